@@ -3,6 +3,7 @@ package api
 import (
 	"backend/internal/cache"
 	"backend/internal/db"
+	"backend/internal/events"
 	"backend/internal/mailer"
 	"backend/internal/middleware"
 	"backend/internal/models"
@@ -108,6 +109,7 @@ func ScrapeUpdateWeekly(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	store.Set(weeklyItemsMap)
+	scrapejob.PublishMenuChanges()
 
 	// Return success code
 	w.WriteHeader(http.StatusOK)
@@ -210,6 +212,7 @@ func ScrapeWeeklyItemsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	store.Set(weeklyItemsMap)
+	scrapejob.PublishMenuChanges()
 
 	// Return success code
 	w.WriteHeader(http.StatusOK)
@@ -270,6 +273,7 @@ func ScrapeLocationOperatingTimesHandler(w http.ResponseWriter, r *http.Request)
 
 	// Populate the memory store after successful database insertion
 	store.Set(locationOperatingTimes)
+	events.PublishHours()
 
 	// Return success code
 	w.WriteHeader(http.StatusOK)
@@ -509,117 +513,41 @@ func DeleteDeviceToken(w http.ResponseWriter, r *http.Request) {
 func GetAllDataHandler(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(string)
 
-	// Try to get data from memory store first, fall back to database if not available
-	var allItems []models.AllDataItem
-	var weeklyItems map[string][]models.DailyItem
-	var locationOperatingTimes []models.LocationOperatingTimes
-	var userPreferences []models.AllDataItem
-	var mailing *bool
-	var nutritionGoals models.NutritionGoals
-	var displayPreferences models.DisplayPreferences
-	var hasSavedDisplayPreferences bool
-	var err error
-
-	// Check memory store first for general data
-	allItems = store.GetAllDataItems()
-	if allItems == nil {
-		fmt.Println("All data items in store were nil, falling back to db")
-		allItems, err = db.GetAllDataItems()
-		if errors.Is(err, db.NoItemsInDB) {
-			allItems = []models.AllDataItem{}
-			err = nil
-		}
-		store.Set(allItems)
-		if err != nil {
-			http.Error(w, "Error fetching all items: "+err.Error(), http.StatusInternalServerError)
+	// Answer revalidations before copying anything out of the store. Only a
+	// cached user has a known generation, so a cache miss always rebuilds.
+	storeVersion, lastModified := store.Version()
+	if cachedUserData, cacheHit := cache.GetUserData(userID); cacheHit {
+		if notModified(w, r, userStoreETag(storeVersion, cachedUserData.Generation)) {
 			return
 		}
 	}
 
-	weeklyItems = store.GetWeeklyItems()
-	if len(weeklyItems) == 0 {
-		fmt.Println("Weekly items in store were nil, falling back to db")
-		// Fetch from database and convert to the format expected by frontend
-		dbWeeklyItems, err := db.GetAllWeeklyItems()
-		if errors.Is(err, db.NoItemsInDB) {
-			dbWeeklyItems = map[string][]models.DailyItem{}
-			err = nil
-		}
-		store.Set(dbWeeklyItems)
-		if err != nil {
-			http.Error(w, "Error fetching weeklyItems items: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		// Convert the database format to the store format for consistency
-		weeklyItems = dbWeeklyItems
+	// Try to get data from memory store first, fall back to database if not available
+	allItems, err := loadAllDataItems()
+	if err != nil {
+		writeError(w, err)
+		return
 	}
-
-	locationOperatingTimes = store.GetLocationOperatingTimes()
-	if locationOperatingTimes == nil {
-		fmt.Println("Location operating times in store were nil, falling back to db")
-		locationOperatingTimes, err = db.GetLocationOperatingTimes()
-		store.Set(locationOperatingTimes)
-		if err != nil {
-			http.Error(w, "Error fetching locationOperatingTimes: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+	weeklyItems, err := loadWeeklyItems()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	locationOperatingTimes, err := loadOperatingTimes()
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
 	// Try to get user-specific data from cache first
-	cachedUserData, cacheHit := cache.GetUserData(userID)
-	if cacheHit {
-		fmt.Printf("Cache hit for user %s\n", userID)
-		userPreferences = cachedUserData.Preferences
-		nutritionGoals = cachedUserData.NutritionGoals
-		mailing = cachedUserData.Mailing
-		displayPreferences = cachedUserData.DisplayPreferences
-		hasSavedDisplayPreferences = cachedUserData.HasSavedDisplayPreferences
-	} else {
-		fmt.Printf("Cache miss for user %s, fetching from database\n", userID)
-
-		// Fetch user preferences from database
-		userPreferences, err = db.GetUserPreferences(userID)
-		if err == db.NoUserPreferencesInDB {
-			userPreferences = []models.AllDataItem{}
-		} else if err != nil {
-			http.Error(w, "Error fetching user preferences: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Fetch mailing preference from database
-		mailing, err = db.GetUserMailing(userID)
-		if err != nil {
-			http.Error(w, "Error fetching user mailing: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Fetch nutrition goals from database
-		nutritionGoals, err = db.GetNutritionGoals(userID)
-		if err != nil && err != db.NoUserGoalsInDB {
-			http.Error(w, "Error fetching nutrition goals: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// If no goals are found, use default values
-		if err == db.NoUserGoalsInDB {
-			nutritionGoals = models.NutritionGoals{
-				Calories: 2000,
-				Protein:  50,
-				Carbs:    275,
-				Fat:      78,
-			}
-		}
-
-		displayPreferences, hasSavedDisplayPreferences, err = db.GetDisplayPreferences(userID)
-		if err != nil {
-			http.Error(w, "Error fetching display preferences: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Cache the user data for future requests
-		cache.SetUserData(userID, userPreferences, nutritionGoals, mailing, displayPreferences, hasSavedDisplayPreferences)
+	user, err := loadUserData(userID)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
+	lastModified = latest(lastModified, user.LastUpdated)
 
+	displayPreferences := user.DisplayPreferences
 	if displayPreferences.VisibleLocations == nil {
 		displayPreferences.VisibleLocations = []string{}
 	}
@@ -628,13 +556,21 @@ func GetAllDataHandler(w http.ResponseWriter, r *http.Request) {
 		"allItems":               allDataItemsToStrings(allItems),
 		"weeklyItems":            weeklyItems,
 		"locationOperatingTimes": locationOperatingTimes,
-		"userPreferences":        allDataItemsToStrings(userPreferences),
-		"mailing":                mailing,
-		"nutritionGoals":         nutritionGoals,
+		"userPreferences":        allDataItemsToStrings(user.Preferences),
+		"mailing":                user.Mailing,
+		"weeklyReport":           user.WeeklyReport,
+		"nutritionGoals":         user.NutritionGoals,
 		"displayPreferences": map[string]interface{}{
 			"visibleLocations":           displayPreferences.VisibleLocations,
-			"hasSavedDisplayPreferences": hasSavedDisplayPreferences,
+			"hasSavedDisplayPreferences": user.HasSavedDisplayPreferences,
 		},
+		"dietaryProfile": user.DietaryProfile,
+	}
+
+	// Without a user cache there is no generation to tell user data apart, so
+	// the response cannot be validated.
+	if user.Generation != 0 {
+		writeValidators(w, userStoreETag(storeVersion, user.Generation), lastModified, "private, no-cache")
 	}
 
 	// Set the response header to indicate JSON content
@@ -661,15 +597,16 @@ func GetAllDataHandler(w http.ResponseWriter, r *http.Request) {
 //   - w: The HTTP response writer.
 //   - r: The HTTP request.
 func GetLocationOperatingTimesHandler(w http.ResponseWriter, r *http.Request) {
+	etag, lastModified := storeETag()
+	if notModified(w, r, etag) {
+		return
+	}
+
 	// Try to get data from memory store first, fall back to database if not available
-	locationOperatingTimes := store.GetLocationOperatingTimes()
-	if locationOperatingTimes == nil {
-		var err error
-		locationOperatingTimes, err = db.GetLocationOperatingTimes()
-		if err != nil {
-			http.Error(w, "Error fetching locationOperatingTimes : "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+	locationOperatingTimes, err := loadOperatingTimes()
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
 	// Combine all data into a single JSON structure
@@ -677,6 +614,8 @@ func GetLocationOperatingTimesHandler(w http.ResponseWriter, r *http.Request) {
 		"locationOperatingTimes": locationOperatingTimes,
 	}
 
+	writeValidators(w, etag, lastModified, "no-cache")
+
 	// Set the response header to indicate JSON content
 	w.Header().Set("Content-Type", "application/json")
 
@@ -701,64 +640,30 @@ func GetLocationOperatingTimesHandler(w http.ResponseWriter, r *http.Request) {
 //   - w: The HTTP response writer.
 //   - r: The HTTP request.
 func GetGeneralDataHandler(w http.ResponseWriter, r *http.Request) {
-	// Try to get data from memory store first, fall back to database if not available
-	var allItems []models.AllDataItem
-	var weeklyItems map[string][]models.DailyItem
-	var locationOperatingTimes []models.LocationOperatingTimes
-	var err error
-
-	// Check memory store first
-	allItems = store.GetAllDataItems()
-	if allItems == nil {
-		fmt.Println("All Data items in store were nil, falling back to db")
-		allItems, err = db.GetAllDataItems()
-		if errors.Is(err, db.NoItemsInDB) {
-			allItems = []models.AllDataItem{}
-			err = nil
-		}
-		store.Set(allItems)
-		if err != nil {
-			http.Error(w, "Error fetching all items: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+	etag, lastModified := storeETag()
+	if notModified(w, r, etag) {
+		return
 	}
 
-	weeklyItems = store.GetWeeklyItems()
-	// Check len bc we initialize weeklyItems to an empty map
-	if len(weeklyItems) == 0 {
-		fmt.Println("Weekly items in store were nil, falling back to db")
-		// Fetch from database and convert to the format expected by frontend
-		dbWeeklyItems, err := db.GetAllWeeklyItems()
-		if errors.Is(err, db.NoItemsInDB) {
-			dbWeeklyItems = map[string][]models.DailyItem{}
-			err = nil
-		}
-		store.Set(dbWeeklyItems)
-		if err != nil {
-			http.Error(w, "Error fetching weeklyItems items: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		weeklyItems = dbWeeklyItems
+	// Try to get data from memory store first, fall back to database if not available
+	allItems, err := loadAllDataItems()
+	if err != nil {
+		writeError(w, err)
+		return
 	}
-
-	locationOperatingTimes = store.GetLocationOperatingTimes()
-	if locationOperatingTimes == nil {
-		fmt.Println("Location operating times in store were nil, falling back to db")
-		locationOperatingTimes, err = db.GetLocationOperatingTimes()
-		store.Set(locationOperatingTimes)
-		if err != nil {
-			http.Error(w, "Error fetching location operations: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+	weeklyItems, err := loadWeeklyItems()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	locationOperatingTimes, err := loadOperatingTimes()
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
 	// Default nutrition goals for non-authenticated users
-	defaultNutritionGoals := models.NutritionGoals{
-		Calories: 2000,
-		Protein:  50,
-		Carbs:    275,
-		Fat:      78,
-	}
+	defaultNutritionGoals := models.DefaultNutritionGoals()
 
 	// Combine all data into a single JSON structure
 	combinedData := map[string]interface{}{
@@ -768,6 +673,8 @@ func GetGeneralDataHandler(w http.ResponseWriter, r *http.Request) {
 		"nutritionGoals":         defaultNutritionGoals,
 	}
 
+	writeValidators(w, etag, lastModified, "no-cache")
+
 	// Set the response header to indicate JSON content
 	w.Header().Set("Content-Type", "application/json")
 
@@ -801,8 +708,14 @@ func HandleUnsubscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update database to set mailing=false for this user
-	err = db.UpdateMailingStatus(userID, false)
+	// Weekly report emails link here with list=weekly; every other link
+	// unsubscribes from the daily favorites email.
+	if r.URL.Query().Get("list") == "weekly" {
+		err = db.UpdateWeeklyReportStatus(userID, false)
+	} else {
+		// Update database to set mailing=false for this user
+		err = db.UpdateMailingStatus(userID, false)
+	}
 	if err != nil {
 		log.Printf("Error unsubscribing user %s: %v", userID, err)
 		http.Error(w, "Failed to unsubscribe", http.StatusInternalServerError)
@@ -812,7 +725,11 @@ func HandleUnsubscribe(w http.ResponseWriter, r *http.Request) {
 	// Keep the in-process cache in sync so the app's mailing toggle reflects the
 	// unsubscribe immediately instead of serving a stale "subscribed" value from
 	// the lazy-loaded store until its TTL expires.
-	cache.SetUserMailing(userID, false)
+	if r.URL.Query().Get("list") == "weekly" {
+		cache.SetUserWeeklyReport(userID, false)
+	} else {
+		cache.SetUserMailing(userID, false)
+	}
 
 	// Show success page
 	w.Header().Set("Content-Type", "text/html")
@@ -843,7 +760,10 @@ func HandleUnsubscribe(w http.ResponseWriter, r *http.Request) {
 //   - A valid Firebase ID token in the Authorization header.
 //
 // Request Body:
-//   - JSON object with calories, protein, carbs, and fat.
+//   - JSON object with calories, protein, carbs, and fat, plus optional
+//     CaloriesRange/ProteinRange/CarbsRange/FatRange ({Min, Max}), MealSplit
+//     ({Breakfast, Lunch, Dinner} percentages adding up to 100), SodiumMax,
+//     SugarMax and FiberMin. Invalid goals are rejected with 400.
 //
 // Parameters:
 //   - w: The HTTP response writer.
@@ -866,6 +786,12 @@ func SaveNutritionGoalsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject impossible goals before they reach the database
+	if err := goals.Validate(); err != nil {
+		http.Error(w, "Invalid nutrition goals: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Save the nutrition goals
 	if err := db.SaveNutritionGoals(userID, goals); err != nil {
 		http.Error(w, "Error saving nutrition goals: "+err.Error(), http.StatusInternalServerError)
@@ -910,12 +836,7 @@ func GetNutritionGoalsHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			if err == db.NoUserGoalsInDB {
 				// Return default values if no goals are found
-				goals = models.NutritionGoals{
-					Calories: 2000,
-					Protein:  50,
-					Carbs:    275,
-					Fat:      78,
-				}
+				goals = models.DefaultNutritionGoals()
 			} else {
 				http.Error(w, "Error retrieving nutrition goals: "+err.Error(), http.StatusInternalServerError)
 				return
@@ -941,8 +862,8 @@ func GetNutritionGoalsHandler(w http.ResponseWriter, r *http.Request) {
 // DeleteUserHandler deletes all server-side data owned by the authenticated
 // user. This backs the account-deletion flow required for App Store review.
 //
-// It removes the user's rows from every user-keyed table (preferences and
-// nutrition goals) and invalidates any cached copy of their data. The iOS
+// It removes the user's rows from every user-keyed table (see
+// db.DeleteUserData) and invalidates any cached copy of their data. The iOS
 // client is responsible for deleting the Firebase Auth user itself afterward,
 // so this handler only clears server-side state.
 //
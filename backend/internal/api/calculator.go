@@ -0,0 +1,144 @@
+package api
+
+import (
+	"backend/internal/cache"
+	"backend/internal/calculator"
+	"backend/internal/db"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// CalculateNutritionGoalsHandler derives nutrition goals from body stats,
+// activity level and objective. By default nothing is stored: save replaces
+// the user's goals with the result, and rememberStats keeps the inputs, which
+// GetBodyStatsHandler reads back and DeleteBodyStatsHandler forgets.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - JSON object with age, sex, heightCm, weightKg, activityLevel
+//     (sedentary, light, moderate, active or veryActive) and objective (cut,
+//     maintain or bulk), plus the optional save and rememberStats flags.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func CalculateNutritionGoalsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.GoalCalculationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := calculateNutritionGoals(userID, request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetBodyStatsHandler returns the goal calculator inputs the user chose to
+// remember, so clients can prefill the calculator. It responds 404 when none
+// are stored.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetBodyStatsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	stats, err := bodyStats(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteBodyStatsHandler forgets the goal calculator inputs the user chose to
+// remember. It succeeds when none are stored.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - No body is expected in this request.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func DeleteBodyStatsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := deleteBodyStats(userID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bodyStats reads the user's remembered calculator inputs for both API
+// versions.
+func bodyStats(userID string) (models.BodyStats, error) {
+	stats, err := db.GetBodyStats(userID)
+	if errors.Is(err, db.NoBodyStatsInDB) {
+		return models.BodyStats{}, notFound("No body stats stored")
+	}
+	if err != nil {
+		return models.BodyStats{}, internalError("Error fetching body stats", err)
+	}
+	return stats, nil
+}
+
+// deleteBodyStats forgets the user's remembered calculator inputs for both API
+// versions.
+func deleteBodyStats(userID string) error {
+	if err := db.DeleteBodyStats(userID); err != nil {
+		return internalError("Error deleting body stats", err)
+	}
+	return nil
+}
+
+// calculateNutritionGoals runs the calculator and stores what the user opted
+// into for both API versions.
+func calculateNutritionGoals(userID string, request models.GoalCalculationRequest) (models.GoalCalculation, error) {
+	result, err := calculator.Calculate(request.BodyStats)
+	if err != nil {
+		return models.GoalCalculation{}, badRequest(err.Error())
+	}
+
+	if request.Save {
+		if err := db.SaveNutritionGoals(userID, result.Goals); err != nil {
+			return models.GoalCalculation{}, internalError("Error saving nutrition goals", err)
+		}
+		cache.SetUserNutritionGoals(userID, result.Goals)
+		result.Saved = true
+	}
+	if request.RememberStats {
+		if err := db.SaveBodyStats(userID, result.Stats); err != nil {
+			return models.GoalCalculation{}, internalError("Error saving body stats", err)
+		}
+		result.StatsSaved = true
+	}
+	return result, nil
+}
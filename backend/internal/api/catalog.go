@@ -0,0 +1,105 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/models"
+	"backend/internal/scrapejob"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// GetCatalogHandler lists the item catalog: each canonical menu item with
+// every spelling the menu has used for it and the upstream IDs seen with it.
+// Admins use it to find duplicates to merge.
+//
+// Expected Authorization:
+//   - Admin level access.
+//
+// Query Parameters:
+//   - q: optional part of an item name; only items with a matching alias are
+//     listed.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetCatalogHandler(w http.ResponseWriter, r *http.Request) {
+	items, err := db.GetCatalogItems(strings.TrimSpace(r.URL.Query().Get("q")))
+	if err != nil {
+		writeError(w, internalError("Error fetching catalog", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// MergeCatalogHandler folds duplicate catalog items into one, so favorites of
+// any of them match every spelling. Menu rows and favorites are relinked and
+// the in-memory menu is reloaded.
+//
+// Expected Authorization:
+//   - Admin level access.
+//
+// Expected Body:
+//   - JSON object with into, the catalog ID to keep, and from, the IDs to fold
+//     into it.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func MergeCatalogHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.CatalogMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	item, err := mergeCatalogItems(request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(item); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// mergeCatalogItems validates and applies a merge, then reloads the menu store
+// so served items carry the surviving catalog ID.
+func mergeCatalogItems(request models.CatalogMergeRequest) (models.CatalogItem, error) {
+	if request.Into == 0 {
+		return models.CatalogItem{}, badRequest("into is required")
+	}
+	seen := make(map[uint]bool, len(request.From))
+	var from []uint
+	for _, id := range request.From {
+		if id == request.Into {
+			return models.CatalogItem{}, badRequest("cannot merge a catalog item into itself")
+		}
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			from = append(from, id)
+		}
+	}
+	if len(from) == 0 {
+		return models.CatalogItem{}, badRequest("from must list at least one catalog ID")
+	}
+
+	item, err := db.MergeCatalogItems(request.Into, from)
+	if errors.Is(err, db.NoCatalogItemInDB) {
+		return models.CatalogItem{}, notFound("No such catalog item")
+	}
+	if err != nil {
+		return models.CatalogItem{}, internalError("Error merging catalog items", err)
+	}
+	scrapejob.RefreshMenuStore()
+	return item, nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/store"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// processEpoch distinguishes store versions across restarts, since the
+// in-memory version counter starts over at zero with every process.
+var processEpoch = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+// storeETag returns a strong ETag for a payload built purely from the memory
+// store, along with the time the store last changed. Handlers take it before
+// reading the store: a change in between then only costs one extra full
+// response, never a stale 304.
+func storeETag() (string, time.Time) {
+	version, updatedAt := store.Version()
+	return fmt.Sprintf(`"%s-%d"`, processEpoch, version), updatedAt
+}
+
+// userStoreETag extends storeETag with a user cache generation, for payloads
+// that combine store data with per-user data.
+func userStoreETag(version uint64, generation uint64) string {
+	return fmt.Sprintf(`"%s-%d-%d"`, processEpoch, version, generation)
+}
+
+// writeValidators sets the ETag and Last-Modified headers, and asks clients to
+// revalidate before reusing a cached copy.
+func writeValidators(w http.ResponseWriter, etag string, lastModified time.Time, cacheControl string) {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+}
+
+// notModified reports whether the request's If-None-Match already names etag,
+// in which case it writes a 304 and the caller should stop.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	if !middleware.ETagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// latest returns the later of two times.
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
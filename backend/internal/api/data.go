@@ -0,0 +1,212 @@
+package api
+
+import (
+	"backend/internal/cache"
+	"backend/internal/db"
+	"backend/internal/models"
+	"backend/internal/store"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// requestError is a failure together with the HTTP status it is reported
+// with. The shared response builders return it so v1 handlers can keep their
+// plain-text errors while v2 handlers wrap the same message in the JSON error
+// envelope.
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func badRequest(message string) error {
+	return &requestError{status: http.StatusBadRequest, message: message}
+}
+
+func notFound(message string) error {
+	return &requestError{status: http.StatusNotFound, message: message}
+}
+
+// internalError reports err as a server failure, prefixed with what was being
+// attempted ("Error fetching menu items").
+func internalError(action string, err error) error {
+	return &requestError{status: http.StatusInternalServerError, message: action + ": " + err.Error()}
+}
+
+// errorStatus returns the status and message to report for err. Errors that
+// are not requestErrors are server failures.
+func errorStatus(err error) (int, string) {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return reqErr.status, reqErr.message
+	}
+	return http.StatusInternalServerError, err.Error()
+}
+
+// writeError reports err as a plain-text v1 error.
+func writeError(w http.ResponseWriter, err error) {
+	status, message := errorStatus(err)
+	http.Error(w, message, status)
+}
+
+// parseID parses the numeric ID of a stored row (a food log entry, saved
+// plate or subscription rule) from a request path.
+func parseID(idText string) (uint, error) {
+	id, err := strconv.ParseUint(idText, 10, 0)
+	if err != nil || id == 0 {
+		return 0, badRequest("id must be a positive integer")
+	}
+	return uint(id), nil
+}
+
+// loadAllDataItems returns the food catalog from the memory store, loading it
+// from the database (and into the store) on a miss.
+func loadAllDataItems() ([]models.AllDataItem, error) {
+	allItems := store.GetAllDataItems()
+	if allItems != nil {
+		return allItems, nil
+	}
+
+	fmt.Println("All data items in store were nil, falling back to db")
+	allItems, err := db.GetAllDataItems()
+	if errors.Is(err, db.NoItemsInDB) {
+		allItems = []models.AllDataItem{}
+		err = nil
+	}
+	if err != nil {
+		return nil, internalError("Error fetching all items", err)
+	}
+	store.Set(allItems)
+	return allItems, nil
+}
+
+// loadWeeklyItems returns the stored menu keyed by date, loading it from the
+// database (and into the store) when the store is empty.
+func loadWeeklyItems() (map[string][]models.DailyItem, error) {
+	// Check len bc we initialize weeklyItems to an empty map
+	weeklyItems := store.GetWeeklyItems()
+	if len(weeklyItems) != 0 {
+		return weeklyItems, nil
+	}
+
+	fmt.Println("Weekly items in store were nil, falling back to db")
+	weeklyItems, err := db.GetAllWeeklyItems()
+	if errors.Is(err, db.NoItemsInDB) {
+		weeklyItems = map[string][]models.DailyItem{}
+		err = nil
+	}
+	if err != nil {
+		return nil, internalError("Error fetching weeklyItems items", err)
+	}
+	// Set derives the store's own copy; the copy served here needs the same
+	// fields.
+	store.Set(weeklyItems)
+	store.DeriveWeekly(weeklyItems)
+	return weeklyItems, nil
+}
+
+// loadOperatingTimes returns every location's operating hours, loading them
+// from the database (and into the store) on a miss.
+func loadOperatingTimes() ([]models.LocationOperatingTimes, error) {
+	locationOperatingTimes := store.GetLocationOperatingTimes()
+	if locationOperatingTimes != nil {
+		return locationOperatingTimes, nil
+	}
+
+	fmt.Println("Location operating times in store were nil, falling back to db")
+	locationOperatingTimes, err := db.GetLocationOperatingTimes()
+	if err != nil {
+		return nil, internalError("Error fetching locationOperatingTimes", err)
+	}
+	store.Set(locationOperatingTimes)
+	return locationOperatingTimes, nil
+}
+
+// userData is one user's saved settings, as served from the user cache or
+// rebuilt from the database.
+type userData struct {
+	Preferences                []models.AllDataItem
+	NutritionGoals             models.NutritionGoals
+	Mailing                    *bool
+	WeeklyReport               *bool
+	DisplayPreferences         models.DisplayPreferences
+	HasSavedDisplayPreferences bool
+	DietaryProfile             models.DietaryProfile
+	// Generation identifies this copy for ETags; 0 when the user cache is off.
+	Generation  uint64
+	LastUpdated time.Time
+}
+
+// loadUserData returns the user's settings from the user cache, rebuilding and
+// caching them from the database on a miss.
+func loadUserData(userID string) (userData, error) {
+	if cached, cacheHit := cache.GetUserData(userID); cacheHit {
+		fmt.Printf("Cache hit for user %s\n", userID)
+		// Read the generation first: a concurrent update then only makes the
+		// ETag older than the data, never newer.
+		generation := cached.Generation
+		return userData{
+			Preferences:                cached.Preferences,
+			NutritionGoals:             cached.NutritionGoals,
+			Mailing:                    cached.Mailing,
+			WeeklyReport:               cached.WeeklyReport,
+			DisplayPreferences:         cached.DisplayPreferences,
+			HasSavedDisplayPreferences: cached.HasSavedDisplayPreferences,
+			DietaryProfile:             cached.DietaryProfile,
+			Generation:                 generation,
+			LastUpdated:                cached.LastUpdated,
+		}, nil
+	}
+
+	fmt.Printf("Cache miss for user %s, fetching from database\n", userID)
+	var data userData
+	var err error
+
+	// Fetch user preferences from database
+	data.Preferences, err = db.GetUserPreferences(userID)
+	if err == db.NoUserPreferencesInDB {
+		data.Preferences = []models.AllDataItem{}
+	} else if err != nil {
+		return data, internalError("Error fetching user preferences", err)
+	}
+
+	// Fetch mailing preference from database
+	data.Mailing, err = db.GetUserMailing(userID)
+	if err != nil {
+		return data, internalError("Error fetching user mailing", err)
+	}
+
+	data.WeeklyReport, err = db.GetUserWeeklyReport(userID)
+	if err != nil {
+		return data, internalError("Error fetching weekly report preference", err)
+	}
+
+	// Fetch nutrition goals from database, using default values if none are saved
+	data.NutritionGoals, err = db.GetNutritionGoals(userID)
+	if err == db.NoUserGoalsInDB {
+		data.NutritionGoals = models.DefaultNutritionGoals()
+	} else if err != nil {
+		return data, internalError("Error fetching nutrition goals", err)
+	}
+
+	data.DisplayPreferences, data.HasSavedDisplayPreferences, err = db.GetDisplayPreferences(userID)
+	if err != nil {
+		return data, internalError("Error fetching display preferences", err)
+	}
+
+	data.DietaryProfile, err = db.GetDietaryProfile(userID)
+	if err != nil {
+		return data, internalError("Error fetching dietary profile", err)
+	}
+
+	// Cache the user data for future requests
+	data.Generation = cache.SetUserData(userID, data.Preferences, data.NutritionGoals, data.Mailing, data.WeeklyReport, data.DisplayPreferences, data.HasSavedDisplayPreferences, data.DietaryProfile)
+	data.LastUpdated = time.Now()
+	return data, nil
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"backend/internal/cache"
+	"backend/internal/db"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Values of the diet query parameter on the menu and search endpoints.
+const (
+	dietAnnotate = "annotate" // mark conflicting items with dietConflicts (the default)
+	dietExclude  = "exclude"  // leave conflicting items out
+)
+
+// SetDietaryProfileHandler replaces the user's dietary profile: the allergen
+// tags to avoid (their "may contain" variants are avoided too), the diet tags
+// every item must carry and the ingredients to avoid. Signed-in menu and
+// search responses, the favorites email and meal-time pushes all honor it.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - JSON object with avoid and require, lists of upstream filter names such
+//     as "Milk", "Sesame" or "Vegan", and avoidIngredients, ingredient names
+//     such as "cilantro" or "mushroom" (at most 30 each).
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func SetDietaryProfileHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.DietaryProfile
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profile, err := saveDietaryProfile(userID, request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(profile); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// saveDietaryProfile normalizes and stores a profile for both API versions.
+func saveDietaryProfile(userID string, request models.DietaryProfile) (models.DietaryProfile, error) {
+	profile, err := request.Normalize()
+	if err != nil {
+		return models.DietaryProfile{}, badRequest("Invalid dietary profile: " + err.Error())
+	}
+	if err := db.SaveDietaryProfile(userID, profile); err != nil {
+		return models.DietaryProfile{}, internalError("Error saving dietary profile", err)
+	}
+	cache.SetUserDietaryProfile(userID, profile)
+	return profile, nil
+}
+
+// requestDietaryProfile returns the dietary profile that applies to a request
+// and whether conflicting items are to be excluded rather than annotated.
+// Anonymous requests get an empty profile.
+func requestDietaryProfile(r *http.Request) (models.DietaryProfile, bool, error) {
+	mode := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("diet")))
+	if mode != "" && mode != dietAnnotate && mode != dietExclude {
+		return models.DietaryProfile{}, false, badRequest("diet must be annotate or exclude")
+	}
+
+	userID, signedIn := r.Context().Value(middleware.UserIDKey).(string)
+	if !signedIn {
+		return models.DietaryProfile{}, false, nil
+	}
+	user, err := loadUserData(userID)
+	if err != nil {
+		return models.DietaryProfile{}, false, err
+	}
+	return user.DietaryProfile, mode == dietExclude, nil
+}
+
+// applyDietaryProfile marks each item that conflicts with profile, or drops it
+// when exclude is set, and returns the items with the number dropped. items
+// must be the caller's own copy.
+func applyDietaryProfile(items []models.DailyItem, profile models.DietaryProfile, exclude bool) ([]models.DailyItem, int) {
+	if profile.Empty() {
+		return items, 0
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		conflicts := profile.Conflicts(item)
+		if len(conflicts) > 0 && exclude {
+			continue
+		}
+		item.DietConflicts = conflicts
+		kept = append(kept, item)
+	}
+	return kept, len(items) - len(kept)
+}
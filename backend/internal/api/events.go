@@ -0,0 +1,88 @@
+package api
+
+import (
+	"backend/internal/events"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// eventsHeartbeat is how often an idle stream sends a comment line, so proxies
+// and load balancers do not close it for inactivity.
+var eventsHeartbeat = 25 * time.Second
+
+// eventsWriteWindow is how long each write to the stream may take. The server's
+// WriteTimeout would otherwise end every stream after a fixed total duration.
+const eventsWriteWindow = time.Minute
+
+// EventsHandler streams menu and operating-hours updates as server-sent events.
+// A "menu" event names the periods (date, location, time of day) replaced or
+// cleared since the previous menu event, plus the change version to pass to
+// /api/menu/changes; an "hours" event means operating hours were replaced.
+// Idle streams receive a heartbeat comment. A client that falls too far behind
+// is disconnected and should reconnect and refetch.
+//
+// Expected Authorization:
+//   - No special authorization required.
+//
+// Expected Body:
+//   - No body is expected in this request.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func EventsHandler(w http.ResponseWriter, r *http.Request) {
+	controller := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stop nginx-style proxies from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	subscription := events.Subscribe(events.DefaultBuffer)
+	defer subscription.Unsubscribe()
+
+	// write sends one chunk and flushes it, extending the write deadline first.
+	// Servers that cannot set deadlines (tests, some wrappers) are fine too.
+	write := func(chunk string) bool {
+		_ = controller.SetWriteDeadline(time.Now().Add(eventsWriteWindow))
+		if _, err := fmt.Fprint(w, chunk); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+
+	if !write("retry: 5000\n: connected\n\n") {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if !write(": heartbeat\n\n") {
+				return
+			}
+		case event, ok := <-subscription.C:
+			if !ok {
+				log.Printf("events client %s fell behind and was disconnected", r.RemoteAddr)
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("failed to encode %s event: %v", event.Type, err)
+				continue
+			}
+			if !write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)) {
+				return
+			}
+		}
+	}
+}
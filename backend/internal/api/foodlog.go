@@ -0,0 +1,285 @@
+package api
+
+import (
+	"backend/internal/cache"
+	"backend/internal/db"
+	"backend/internal/foodlog"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/nutrition"
+	"backend/internal/store"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// AddFoodLogEntryHandler records that the user ate a stored menu item. The item
+// is snapshotted into the log, so the entry keeps its nutrition after the menu
+// rotates.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - JSON object with date, location, timeOfDay and name identifying a menu
+//     item, plus either an optional servings multiplier (default 1, max 20)
+//     or grams, converted to servings through the item's portion weight.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func AddFoodLogEntryHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.FoodLogRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entry, err := addFoodLogEntry(userID, request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteFoodLogEntryHandler removes one of the user's food log entries.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - No body is expected in this request; the entry ID is the {id} path
+//     segment.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func DeleteFoodLogEntryHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := deleteFoodLogEntry(userID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFoodLogHandler lists the user's food log entries for one day, oldest
+// first.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Query Parameters:
+//   - date: the YYYY-MM-DD day to list (defaults to today on the campus clock).
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetFoodLogHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	log, err := foodLogResponse(userID, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(log); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetFoodLogSummaryHandler totals the user's food log for one day and compares
+// the totals with their nutrition goals (or the defaults when none are saved).
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Query Parameters:
+//   - date: the YYYY-MM-DD day to summarize (defaults to today on the campus
+//     clock).
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetFoodLogSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	summary, err := foodLogSummaryResponse(userID, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// addFoodLogEntry resolves the requested menu item and logs it for both API
+// versions.
+func addFoodLogEntry(userID string, request models.FoodLogRequest) (models.FoodLogEntry, error) {
+	date, err := parseLogDate(request.Date)
+	if err != nil {
+		return models.FoodLogEntry{}, err
+	}
+	name := strings.TrimSpace(request.Name)
+	location := strings.TrimSpace(request.Location)
+	timeOfDay := strings.TrimSpace(request.TimeOfDay)
+	if name == "" || location == "" || timeOfDay == "" {
+		return models.FoodLogEntry{}, badRequest("name, location and timeOfDay are required")
+	}
+
+	if request.Servings != 0 && request.Grams != 0 {
+		return models.FoodLogEntry{}, badRequest("give either servings or grams, not both")
+	}
+	if request.Grams < 0 {
+		return models.FoodLogEntry{}, badRequest("grams must not be negative")
+	}
+
+	item, err := findMenuItem(models.MenuFilter{From: date, To: date, Location: location, Meal: timeOfDay}, name)
+	if err != nil {
+		return models.FoodLogEntry{}, err
+	}
+
+	servings := request.Servings
+	if request.Grams > 0 {
+		portion := nutrition.PortionOf(item)
+		if portion.Grams == nil || *portion.Grams <= 0 {
+			return models.FoodLogEntry{}, badRequest(fmt.Sprintf("the portion size of %s (%q) has no known weight; log servings instead", item.Name, item.PortionSize))
+		}
+		servings = math.Round(request.Grams / *portion.Grams * 1000) / 1000
+	} else if servings == 0 {
+		servings = 1
+	}
+	if servings <= 0 || servings > foodlog.MaxServings {
+		return models.FoodLogEntry{}, badRequest(fmt.Sprintf("servings must be between 0 and %d", foodlog.MaxServings))
+	}
+
+	entry, err := db.AddFoodLogEntry(userID, models.FoodLogEntry{Date: date, Servings: servings, Item: item})
+	if err != nil {
+		return models.FoodLogEntry{}, internalError("Error saving food log entry", err)
+	}
+	cache.InvalidateUserFoodLog(userID, date)
+	return entry, nil
+}
+
+// deleteFoodLogEntry removes an entry by its ID for both API versions.
+func deleteFoodLogEntry(userID, idText string) error {
+	id, err := parseID(idText)
+	if err != nil {
+		return err
+	}
+
+	entry, err := db.DeleteFoodLogEntry(userID, id)
+	if errors.Is(err, db.NoFoodLogEntryInDB) {
+		return notFound("No food log entry with id " + idText)
+	}
+	if err != nil {
+		return internalError("Error deleting food log entry", err)
+	}
+	cache.InvalidateUserFoodLog(userID, entry.Date)
+	return nil
+}
+
+// foodLogResponse lists one day of the user's food log for both API versions.
+func foodLogResponse(userID string, r *http.Request) (models.FoodLog, error) {
+	date, err := parseLogDate(r.URL.Query().Get("date"))
+	if err != nil {
+		return models.FoodLog{}, err
+	}
+	entries, err := loadFoodLog(userID, date)
+	if err != nil {
+		return models.FoodLog{}, err
+	}
+	return models.FoodLog{Date: date, Entries: entries}, nil
+}
+
+// foodLogSummaryResponse summarizes one day of the user's food log against
+// their goals for both API versions.
+func foodLogSummaryResponse(userID string, r *http.Request) (models.FoodLogSummary, error) {
+	date, err := parseLogDate(r.URL.Query().Get("date"))
+	if err != nil {
+		return models.FoodLogSummary{}, err
+	}
+	// Load the user first so the food log has a cache entry to land in.
+	user, err := loadUserData(userID)
+	if err != nil {
+		return models.FoodLogSummary{}, err
+	}
+	entries, err := loadFoodLog(userID, date)
+	if err != nil {
+		return models.FoodLogSummary{}, err
+	}
+	return foodlog.Summarize(date, entries, user.NutritionGoals), nil
+}
+
+// loadFoodLog returns one day of the user's food log from the user cache,
+// reading and caching it from the database on a miss.
+func loadFoodLog(userID, date string) ([]models.FoodLogEntry, error) {
+	if entries, cacheHit := cache.GetUserFoodLog(userID, date); cacheHit {
+		return entries, nil
+	}
+
+	entries, err := db.GetFoodLog(userID, date)
+	if err != nil {
+		return nil, internalError("Error fetching food log", err)
+	}
+	cache.SetUserFoodLog(userID, date, entries)
+	return entries, nil
+}
+
+// findMenuItem returns the stored menu item called name within filter, which
+// should name a single date, location and meal.
+func findMenuItem(filter models.MenuFilter, name string) (models.DailyItem, error) {
+	items, ok := store.QueryMenu(filter)
+	if !ok {
+		fmt.Println("Menu store was empty, falling back to db for menu item lookup")
+		var err error
+		items, err = db.QueryMenuItems(filter)
+		if err != nil {
+			return models.DailyItem{}, internalError("Error fetching menu items", err)
+		}
+		store.Derive(items)
+	}
+
+	for _, item := range items {
+		if strings.EqualFold(strings.TrimSpace(item.Name), name) {
+			return item, nil
+		}
+	}
+	return models.DailyItem{}, notFound(fmt.Sprintf("No menu item %q at %s for %s on %s", name, filter.Location, filter.Meal, filter.From))
+}
+
+// parseLogDate validates a YYYY-MM-DD date, defaulting to today on the campus
+// clock.
+func parseLogDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return campusNow().Format(time.DateOnly), nil
+	}
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return "", badRequest("date must be formatted as YYYY-MM-DD")
+	}
+	return value, nil
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/models"
+	"backend/internal/nutrition"
+	"backend/internal/store"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// SuggestResponse is the payload of the typeahead endpoint.
+type SuggestResponse struct {
+	Query       string                  `json:"query"`
+	Suggestions []models.ItemSuggestion `json:"suggestions"`
+}
+
+// SuggestItemsHandler answers typeahead queries over the food catalog, so the
+// favorites picker no longer needs the whole allItems list client-side.
+// Matching is prefix-first and tolerates small typos.
+//
+// Expected Authorization:
+//   - No special authorization required.
+//
+// Query Parameters:
+//   - q: the partial item name typed so far.
+//   - limit: maximum number of suggestions (default 10, max 50).
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func SuggestItemsHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := suggestResponse(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// suggestResponse answers a typeahead request for both API versions.
+func suggestResponse(r *http.Request) (SuggestResponse, error) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		return SuggestResponse{}, badRequest("q parameter is required")
+	}
+
+	limit := defaultSuggestLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return SuggestResponse{}, badRequest("limit must be a positive integer")
+		}
+		limit = min(parsed, maxSuggestLimit)
+	}
+
+	suggestions, ok := store.SuggestItems(query, limit)
+	if !ok {
+		fmt.Println("All data items in store were nil, falling back to db for suggestions")
+		// Loading the catalog into the store builds the suggestion index.
+		if _, err := loadAllDataItems(); err != nil {
+			return SuggestResponse{}, err
+		}
+		suggestions, ok = store.SuggestItems(query, limit)
+		if !ok {
+			suggestions = []models.ItemSuggestion{}
+		}
+	}
+
+	return SuggestResponse{Query: query, Suggestions: suggestions}, nil
+}
+
+// GetItemDetailHandler describes one food item: its latest nutrition,
+// ingredients and filters, and every appearance (date, location, station and
+// meal) still inside the MenuRetentionDays window, including scheduled dates.
+//
+// Expected Authorization:
+//   - No special authorization required.
+//
+// Path Parameters:
+//   - name: the item name, matched case-insensitively.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetItemDetailHandler(w http.ResponseWriter, r *http.Request) {
+	detail, err := itemDetailResponse(mux.Vars(r)["name"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(detail); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// itemDetailResponse looks up one item's detail for both API versions,
+// serving it from the store's cache when possible.
+func itemDetailResponse(name string) (models.ItemDetail, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return models.ItemDetail{}, badRequest("item name is required")
+	}
+
+	since := campusNow().AddDate(0, 0, -db.MenuRetentionDays).Format("2006-01-02")
+
+	if detail, ok := store.GetItemDetail(name, since); ok {
+		return detail, nil
+	}
+
+	history, err := db.GetItemHistory(name, since)
+	if errors.Is(err, db.NoItemsInDB) {
+		return models.ItemDetail{}, notFound("No appearances found for item: " + name)
+	}
+	if err != nil {
+		return models.ItemDetail{}, internalError("Error fetching item history", err)
+	}
+	store.Derive(history)
+	detail := buildItemDetail(history, since)
+	store.CacheItemDetail(detail)
+	return detail, nil
+}
+
+// buildItemDetail folds an item's history, newest first, into a detail. The
+// newest row supplies the nutrition so corrections upstream show up
+// immediately.
+func buildItemDetail(history []models.DailyItem, since string) models.ItemDetail {
+	latest := history[0]
+	detail := models.ItemDetail{
+		Name:           latest.Name,
+		Description:    latest.Description,
+		PortionSize:    latest.PortionSize,
+		Calories:       latest.Calories,
+		Protein:        latest.Protein,
+		Carbs:          latest.Carbs,
+		Fat:            latest.Fat,
+		Ingredients:    latest.Ingredients,
+		IngredientTree: latest.IngredientList(),
+		Filters:        latest.Filters,
+		Nutrition:      latest.Nutrition,
+		Serving:        nutrition.PortionOf(latest),
+		Estimates:      latest.Estimates,
+		LastServed:     latest.Date,
+		Since:          since,
+		Appearances:    make([]models.ItemAppearance, 0, len(history)),
+	}
+
+	for _, item := range history {
+		detail.Appearances = append(detail.Appearances, models.ItemAppearance{
+			Date:        item.Date,
+			Location:    item.Location,
+			StationName: item.StationName,
+			TimeOfDay:   item.TimeOfDay,
+		})
+	}
+	return detail
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"backend/internal/hours"
+	"backend/internal/models"
+	"backend/internal/scraper"
+	"backend/internal/store"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LocationStatus is one location's state at the requested instant.
+type LocationStatus struct {
+	Name        string `json:"name"`
+	Open        bool   `json:"open"`
+	ClosingSoon bool   `json:"closingSoon"`
+	// OpensAt is the next opening while closed; null when open or unknown.
+	OpensAt *time.Time `json:"opensAt"`
+	// ClosesAt is when the location closes while open; null when closed.
+	ClosesAt *time.Time `json:"closesAt"`
+	// Meal is the meal being served while open.
+	Meal string `json:"meal,omitempty"`
+	// HasHours is false when no hours are stored for the day, so closed means
+	// "not known to be open" rather than "closed".
+	HasHours bool `json:"hasHours"`
+}
+
+// LocationStatusResponse is the payload of the location status endpoint.
+type LocationStatusResponse struct {
+	At        time.Time        `json:"at"`
+	Locations []LocationStatus `json:"locations"`
+}
+
+// GetLocationStatusHandler reports, for every location with stored operating
+// hours, whether it is open at the requested instant, when it next opens or
+// closes, whether it is closing soon and which meal it is serving. Hours are
+// read on the campus clock; blocks that run past midnight are honored.
+//
+// Expected Authorization:
+//   - No special authorization required.
+//
+// Query Parameters:
+//   - at: the instant to evaluate, RFC 3339 or a campus-local YYYY-MM-DDTHH:MM
+//     (defaults to now).
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetLocationStatusHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := locationStatusResponse(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// locationStatusResponse computes every location's status for both API
+// versions.
+func locationStatusResponse(r *http.Request) (LocationStatusResponse, error) {
+	at := campusNow()
+	if value := strings.TrimSpace(r.URL.Query().Get("at")); value != "" {
+		parsed, err := parseStatusTime(value)
+		if err != nil {
+			return LocationStatusResponse{}, badRequest("at must be RFC 3339 or YYYY-MM-DDTHH:MM")
+		}
+		at = parsed
+	}
+	// Drop sub-second noise so responses for the same minute look alike.
+	at = at.Truncate(time.Second)
+
+	locationOperatingTimes, err := loadOperatingTimes()
+	if err != nil {
+		return LocationStatusResponse{}, err
+	}
+
+	statuses := make([]LocationStatus, 0, len(locationOperatingTimes))
+	for _, location := range locationOperatingTimes {
+		if strings.TrimSpace(location.Name) == "" {
+			continue
+		}
+		statuses = append(statuses, locationStatus(location, at))
+	}
+	return LocationStatusResponse{At: at, Locations: statuses}, nil
+}
+
+// parseStatusTime reads an RFC 3339 instant or a campus-local minute, and
+// returns it on the campus clock.
+func parseStatusTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.In(campusLocation()), nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04", value, campusLocation())
+}
+
+func locationStatus(location models.LocationOperatingTimes, at time.Time) LocationStatus {
+	status := hours.StatusAt(location.Week, at)
+	result := LocationStatus{
+		Name:        location.Name,
+		Open:        status.Open,
+		ClosingSoon: status.ClosingSoon,
+		OpensAt:     status.OpensAt,
+		ClosesAt:    status.ClosesAt,
+		HasHours:    status.HasHours,
+	}
+
+	if status.Block != nil {
+		blockCount := hours.BlocksOn(hours.Blocks(location.Week, at.Location()), status.Block.Date)
+		result.Meal = hours.MealFor(*status.Block, blockCount, offeredMeals(location.Name, status.Block.Date))
+	}
+	return result
+}
+
+// offeredMeals lists the meal periods on a hall's stored menu for date. The
+// hours feed names venues in full ("Allison Dining Commons") while menus use
+// the scraper's short names, so halls are matched by containment. Venues
+// without a scraped menu offer nothing and fall back to the clock.
+func offeredMeals(locationName, date string) []string {
+	lower := strings.ToLower(locationName)
+	for _, hall := range scraper.DefaultConfig.Locations {
+		if !strings.Contains(lower, strings.ToLower(hall.Name)) {
+			continue
+		}
+		items, _ := store.QueryMenu(models.MenuFilter{From: date, To: date, Location: hall.Name})
+		meals := make([]string, 0, len(items))
+		for _, item := range items {
+			meals = append(meals, item.TimeOfDay)
+		}
+		return meals
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/models"
+	"backend/internal/store"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// Embed the timezone database so LoadLocation(campusZone) works even on
+	// minimal container images that ship no system tzdata.
+	_ "time/tzdata"
+)
+
+// campusZone is the timezone "today" is resolved in for date-relative queries.
+const campusZone = "America/Chicago"
+
+var campusLocation = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation(campusZone)
+	if err != nil {
+		log.Printf("failed to load timezone %q (%v); using server local time", campusZone, err)
+		return time.Local
+	}
+	return loc
+})
+
+// campusNow returns the current time on the campus clock, so "today" is the
+// Chicago day regardless of where the server runs.
+func campusNow() time.Time {
+	return time.Now().In(campusLocation())
+}
+
+// parseMenuFilter reads the date, from/to, location, meal and station query
+// parameters. A single date and a from/to range are mutually exclusive; with
+// neither, the filter covers today's campus date.
+func parseMenuFilter(r *http.Request) (models.MenuFilter, error) {
+	query := r.URL.Query()
+	filter := models.MenuFilter{
+		Location: strings.TrimSpace(query.Get("location")),
+		Meal:     strings.TrimSpace(query.Get("meal")),
+		Station:  strings.TrimSpace(query.Get("station")),
+	}
+
+	date := strings.TrimSpace(query.Get("date"))
+	from := strings.TrimSpace(query.Get("from"))
+	to := strings.TrimSpace(query.Get("to"))
+
+	switch {
+	case date != "" && (from != "" || to != ""):
+		return filter, fmt.Errorf("date cannot be combined with from/to")
+	case date != "":
+		from, to = date, date
+	case from == "" && to == "":
+		today := campusNow().Format("2006-01-02")
+		from, to = today, today
+	}
+
+	for name, value := range map[string]string{"from": from, "to": to} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return filter, fmt.Errorf("invalid %s date %q: expected YYYY-MM-DD", name, value)
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return filter, fmt.Errorf("from %s is after to %s", from, to)
+	}
+
+	filter.From = from
+	filter.To = to
+	return filter, nil
+}
+
+// GetMenuHandler returns the menu items for one date (or a date range) and,
+// optionally, a single hall, meal and station, so clients do not have to pull
+// the whole week to render one view.
+//
+// Expected Authorization:
+//   - Optional. With a Firebase ID token, items that conflict with the user's
+//     dietary profile are annotated or excluded.
+//
+// Query Parameters:
+//   - date: a single YYYY-MM-DD date (defaults to today on the campus clock).
+//   - from, to: an inclusive YYYY-MM-DD range; either bound may be omitted.
+//   - location, meal, station: case-insensitive exact matches.
+//   - diet: annotate (default) or exclude items conflicting with the profile.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetMenuHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := menuResponse(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// menuResponse answers a scoped menu query for both API versions.
+func menuResponse(r *http.Request) (models.MenuResponse, error) {
+	filter, err := parseMenuFilter(r)
+	if err != nil {
+		return models.MenuResponse{}, badRequest(err.Error())
+	}
+	profile, exclude, err := requestDietaryProfile(r)
+	if err != nil {
+		return models.MenuResponse{}, err
+	}
+
+	// Serve from the in-memory index when a menu is loaded; otherwise answer this
+	// one query from the database rather than loading the whole week for it.
+	items, ok := store.QueryMenu(filter)
+	if !ok {
+		fmt.Println("Menu store was empty, falling back to db for scoped menu query")
+		items, err = db.QueryMenuItems(filter)
+		if err != nil {
+			return models.MenuResponse{}, internalError("Error fetching menu items", err)
+		}
+		store.Derive(items)
+	}
+	items, excluded := applyDietaryProfile(items, profile, exclude)
+
+	return models.MenuResponse{
+		From:     filter.From,
+		To:       filter.To,
+		Location: filter.Location,
+		Meal:     filter.Meal,
+		Station:  filter.Station,
+		Count:    len(items),
+		Items:    items,
+		Excluded: excluded,
+	}, nil
+}
+
+// GetMenuChangesHandler serves delta sync for clients that keep a local copy of
+// the menu. It returns only the menu slices (date, location, time of day)
+// replaced or cleared since the client's version, each with its current items,
+// and the version to send next time. When reset is true the client's version
+// is too old (or 0) and it must refetch the full menu.
+//
+// Expected Authorization:
+//   - No special authorization required.
+//
+// Query Parameters:
+//   - since: the version returned by the previous call, or 0 for none.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetMenuChangesHandler(w http.ResponseWriter, r *http.Request) {
+	changes, err := menuChangesResponse(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(changes); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// menuChangesResponse answers a delta-sync query for both API versions.
+func menuChangesResponse(r *http.Request) (models.MenuChanges, error) {
+	var since uint64
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return models.MenuChanges{}, badRequest("since must be a non-negative integer version")
+		}
+		since = parsed
+	}
+
+	// The change log lives only in the database; reading it there also keeps the
+	// reported items consistent with the version even before the store reloads.
+	changes, err := db.GetMenuChangesSince(uint(since))
+	if err != nil {
+		return models.MenuChanges{}, internalError("Error fetching menu changes", err)
+	}
+	return changes, nil
+}
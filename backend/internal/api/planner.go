@@ -0,0 +1,169 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/foodlog"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/planner"
+	"backend/internal/store"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// PlannerRequest asks for plates from one meal period. Without a budget the
+// target is the meal's share of the user's nutrition goals.
+type PlannerRequest struct {
+	Date     string `json:"date,omitempty"` // YYYY-MM-DD; defaults to today on the campus clock
+	Meal     string `json:"meal"`
+	Location string `json:"location,omitempty"` // one hall; empty plans every hall separately
+	// Budget is an explicit target, e.g. what is left of the day's goals.
+	Budget   *models.NutrientTotals `json:"budget,omitempty"`
+	Exclude  []string               `json:"exclude,omitempty"` // filter tags no item may carry, e.g. "Peanuts"
+	Require  []string               `json:"require,omitempty"` // filter tags every item must carry, e.g. "Vegan"
+	MaxItems int                    `json:"maxItems,omitempty"`
+	Limit    int                    `json:"limit,omitempty"`
+	// Servings lists the multipliers items may be taken at, e.g. [0.5, 1, 2].
+	Servings []float64 `json:"servings,omitempty"`
+}
+
+// PlannedPlate is a suggested plate at one hall.
+type PlannedPlate struct {
+	Location string `json:"location"`
+	planner.Plate
+}
+
+// PlannerResponse is the payload of the planner endpoint, best plate first.
+type PlannerResponse struct {
+	Date   string                `json:"date"`
+	Meal   string                `json:"meal"`
+	Target models.NutrientTotals `json:"target"`
+	Plates []PlannedPlate        `json:"plates"`
+}
+
+// SuggestPlatesHandler suggests item combinations from one meal period whose
+// combined nutrition best hits a calorie and macro target. The search is
+// bounded (see package planner), so it answers in milliseconds.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - JSON object with meal (required), date, location, budget (calories,
+//     protein, carbs, fat), exclude and require tag lists, maxItems (default 3,
+//     max 6), limit (default 5, max 20) and servings, the multipliers items
+//     may be taken at (default [1], at most 4 values).
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func SuggestPlatesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request PlannerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := suggestPlates(userID, request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// suggestPlates runs a planner request for both API versions.
+func suggestPlates(userID string, request PlannerRequest) (PlannerResponse, error) {
+	date, err := parseLogDate(request.Date)
+	if err != nil {
+		return PlannerResponse{}, err
+	}
+	meal := strings.TrimSpace(request.Meal)
+	if meal == "" {
+		return PlannerResponse{}, badRequest("meal is required")
+	}
+	if request.MaxItems < 0 || request.Limit < 0 {
+		return PlannerResponse{}, badRequest("maxItems and limit must not be negative")
+	}
+	if len(request.Servings) > planner.MaxServingOptions {
+		return PlannerResponse{}, badRequest(fmt.Sprintf("at most %d servings values may be given", planner.MaxServingOptions))
+	}
+	for _, servings := range request.Servings {
+		if servings <= 0 || servings > foodlog.MaxServings {
+			return PlannerResponse{}, badRequest(fmt.Sprintf("servings must be between 0 and %d", foodlog.MaxServings))
+		}
+	}
+
+	var target models.NutrientTotals
+	if request.Budget != nil {
+		target = *request.Budget
+		if target.Calories < 0 || target.Protein < 0 || target.Carbs < 0 || target.Fat < 0 {
+			return PlannerResponse{}, badRequest("budget values must not be negative")
+		}
+	} else {
+		user, err := loadUserData(userID)
+		if err != nil {
+			return PlannerResponse{}, err
+		}
+		target = planner.MealTarget(user.NutritionGoals, meal)
+	}
+
+	filter := models.MenuFilter{From: date, To: date, Meal: meal, Location: strings.TrimSpace(request.Location)}
+	items, ok := store.QueryMenu(filter)
+	if !ok {
+		fmt.Println("Menu store was empty, falling back to db for planner")
+		items, err = db.QueryMenuItems(filter)
+		if err != nil {
+			return PlannerResponse{}, internalError("Error fetching menu items", err)
+		}
+		store.Derive(items)
+	}
+
+	// A plate comes from one hall, so halls are planned separately and the
+	// results merged.
+	byLocation := make(map[string][]models.DailyItem)
+	var locations []string
+	for _, item := range items {
+		if _, seen := byLocation[item.Location]; !seen {
+			locations = append(locations, item.Location)
+		}
+		byLocation[item.Location] = append(byLocation[item.Location], item)
+	}
+
+	options := planner.Options{
+		Target:   target,
+		MaxItems: request.MaxItems,
+		TopK:     request.Limit,
+		Exclude:  request.Exclude,
+		Require:  request.Require,
+		Servings: request.Servings,
+	}
+	plates := []PlannedPlate{}
+	for _, location := range locations {
+		for _, plate := range planner.Suggest(byLocation[location], options) {
+			plates = append(plates, PlannedPlate{Location: location, Plate: plate})
+		}
+	}
+	sort.SliceStable(plates, func(i, j int) bool { return plates[i].Score < plates[j].Score })
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = planner.DefaultTopK
+	}
+	limit = min(limit, planner.TopKLimit)
+	if len(plates) > limit {
+		plates = plates[:limit]
+	}
+
+	return PlannerResponse{Date: date, Meal: meal, Target: target, Plates: plates}, nil
+}
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/foodlog"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxPlateItems bounds how many items one saved plate may hold.
+	maxPlateItems = 12
+	// maxPlateNameLength bounds a saved plate's name, in bytes.
+	maxPlateNameLength = 100
+)
+
+// GetSavedPlatesHandler lists the user's saved plates, oldest first.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetSavedPlatesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	plates, err := savedPlates(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(plates); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateSavedPlateHandler saves a named combination of menu items for the
+// user, e.g. a plate accepted from the planner.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - JSON object with name and items, a list of up to 12 {name, servings}
+//     components (servings default to 1, max 20).
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func CreateSavedPlateHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.SavedPlateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plate, err := createSavedPlate(userID, request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(plate); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateSavedPlateHandler replaces the name and items of one of the user's
+// saved plates.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - The same JSON object CreateSavedPlateHandler accepts; the plate ID is
+//     the {id} path segment.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func UpdateSavedPlateHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.SavedPlateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plate, err := updateSavedPlate(userID, mux.Vars(r)["id"], request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(plate); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteSavedPlateHandler removes one of the user's saved plates.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - No body is expected in this request; the plate ID is the {id} path
+//     segment.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func DeleteSavedPlateHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := deleteSavedPlate(userID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPlateAvailabilityHandler reports, for each of the user's saved plates,
+// the upcoming dates, meal periods and halls at which every item on the plate
+// is served together in the stored menu.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetPlateAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	availability, err := plateAvailability(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(availability); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// savedPlates lists the user's saved plates for both API versions.
+func savedPlates(userID string) ([]models.SavedPlate, error) {
+	plates, err := db.GetSavedPlates(userID)
+	if err != nil {
+		return nil, internalError("Error fetching saved plates", err)
+	}
+	return plates, nil
+}
+
+// createSavedPlate validates and stores a new plate for both API versions.
+func createSavedPlate(userID string, request models.SavedPlateRequest) (models.SavedPlate, error) {
+	plate, err := normalizeSavedPlate(request)
+	if err != nil {
+		return models.SavedPlate{}, err
+	}
+	plate, err = db.CreateSavedPlate(userID, plate)
+	if err != nil {
+		return models.SavedPlate{}, internalError("Error saving plate", err)
+	}
+	return plate, nil
+}
+
+// updateSavedPlate validates and replaces a plate by its ID for both API
+// versions.
+func updateSavedPlate(userID, idText string, request models.SavedPlateRequest) (models.SavedPlate, error) {
+	id, err := parseID(idText)
+	if err != nil {
+		return models.SavedPlate{}, err
+	}
+	plate, err := normalizeSavedPlate(request)
+	if err != nil {
+		return models.SavedPlate{}, err
+	}
+
+	plate, err = db.UpdateSavedPlate(userID, id, plate)
+	if errors.Is(err, db.NoSavedPlateInDB) {
+		return models.SavedPlate{}, notFound("No saved plate with id " + idText)
+	}
+	if err != nil {
+		return models.SavedPlate{}, internalError("Error saving plate", err)
+	}
+	return plate, nil
+}
+
+// deleteSavedPlate removes a plate by its ID for both API versions.
+func deleteSavedPlate(userID, idText string) error {
+	id, err := parseID(idText)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteSavedPlate(userID, id)
+	if errors.Is(err, db.NoSavedPlateInDB) {
+		return notFound("No saved plate with id " + idText)
+	}
+	if err != nil {
+		return internalError("Error deleting saved plate", err)
+	}
+	return nil
+}
+
+// plateAvailability finds where each of the user's plates is served from
+// today on for both API versions.
+func plateAvailability(userID string) (models.PlateAvailabilityResponse, error) {
+	plates, err := savedPlates(userID)
+	if err != nil {
+		return models.PlateAvailabilityResponse{}, err
+	}
+
+	from := campusNow().Format("2006-01-02")
+	response := models.PlateAvailabilityResponse{From: from, Plates: []models.PlateAvailability{}}
+	for _, plate := range plates {
+		names := make([]string, 0, len(plate.Items))
+		for _, component := range plate.Items {
+			names = append(names, component.Name)
+		}
+		slots, err := db.GetPlateSlots(names, from)
+		if err != nil {
+			return models.PlateAvailabilityResponse{}, internalError("Error matching saved plates to the menu", err)
+		}
+		response.Plates = append(response.Plates, models.PlateAvailability{Plate: plate, Slots: slots})
+	}
+	return response, nil
+}
+
+// normalizeSavedPlate trims and checks a plate request. Items are matched to
+// the menu like favorites, so any spelling of an item matches it and each
+// item may appear on a plate only once.
+func normalizeSavedPlate(request models.SavedPlateRequest) (models.SavedPlate, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return models.SavedPlate{}, badRequest("name is required")
+	}
+	if len(name) > maxPlateNameLength {
+		return models.SavedPlate{}, badRequest(fmt.Sprintf("name must be at most %d characters", maxPlateNameLength))
+	}
+	if len(request.Items) == 0 || len(request.Items) > maxPlateItems {
+		return models.SavedPlate{}, badRequest(fmt.Sprintf("a plate needs between 1 and %d items", maxPlateItems))
+	}
+
+	plate := models.SavedPlate{Name: name, Items: make([]models.PlateComponent, 0, len(request.Items))}
+	seen := make(map[string]bool)
+	for _, component := range request.Items {
+		component.Name = strings.TrimSpace(component.Name)
+		if component.Name == "" {
+			return models.SavedPlate{}, badRequest("every item needs a name")
+		}
+		key := models.CatalogKey(component.Name)
+		if seen[key] {
+			return models.SavedPlate{}, badRequest(fmt.Sprintf("%s is listed more than once; adjust its servings instead", component.Name))
+		}
+		seen[key] = true
+
+		if component.Servings == 0 {
+			component.Servings = 1
+		}
+		if component.Servings < 0 || component.Servings > foodlog.MaxServings {
+			return models.SavedPlate{}, badRequest(fmt.Sprintf("servings must be between 0 and %d", foodlog.MaxServings))
+		}
+		plate.Items = append(plate.Items, component)
+	}
+	return plate, nil
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/models"
+	"backend/internal/ranking"
+	"backend/internal/store"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultRankingLimit = 10
+	maxRankingLimit     = 50
+)
+
+// RankingsResponse is the payload of the rankings endpoint.
+type RankingsResponse struct {
+	Date   string `json:"date"`
+	Meal   string `json:"meal,omitempty"`
+	Metric string `json:"metric"`
+	ranking.Result
+}
+
+// GetRankingsHandler ranks a day's menu items by a nutrient-density metric,
+// overall and per hall, answering questions like "where is the most protein
+// per calorie at lunch today?". Items without the nutrition data the metric
+// needs, or under 50 calories, are skipped and counted. Halls that fill their
+// best-five window rank ahead of halls with fewer items.
+//
+// Expected Authorization:
+//   - None.
+//
+// Query Parameters:
+//   - date: the YYYY-MM-DD day to rank (defaults to today on the campus clock).
+//   - meal: limit the ranking to one meal period (optional).
+//   - metric: protein_per_cal (default), fiber or low_sodium.
+//   - limit: how many items to rank overall (default 10, max 50). Each hall
+//     always lists its best five.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetRankingsHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := rankingsResponse(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// rankingsResponse builds the rankings payload for both API versions.
+func rankingsResponse(r *http.Request) (RankingsResponse, error) {
+	query := r.URL.Query()
+	date, err := parseLogDate(query.Get("date"))
+	if err != nil {
+		return RankingsResponse{}, err
+	}
+
+	metric := strings.ToLower(strings.TrimSpace(query.Get("metric")))
+	if metric == "" {
+		metric = ranking.ProteinPerCal
+	}
+	if !ranking.Valid(metric) {
+		return RankingsResponse{}, badRequest("metric must be one of " + strings.Join(ranking.Metrics, ", "))
+	}
+
+	limit := defaultRankingLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return RankingsResponse{}, badRequest("limit must be a positive integer")
+		}
+		limit = min(parsed, maxRankingLimit)
+	}
+
+	meal := strings.TrimSpace(query.Get("meal"))
+	filter := models.MenuFilter{From: date, To: date, Meal: meal}
+	items, ok := store.QueryMenu(filter)
+	if !ok {
+		fmt.Println("Menu store was empty, falling back to db for rankings")
+		items, err = db.QueryMenuItems(filter)
+		if err != nil {
+			return RankingsResponse{}, internalError("Error fetching menu items", err)
+		}
+		store.Derive(items)
+	}
+
+	return RankingsResponse{
+		Date:   date,
+		Meal:   meal,
+		Metric: metric,
+		Result: ranking.Rank(items, metric, limit),
+	}, nil
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/models"
+	"backend/internal/search"
+	"backend/internal/store"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// SearchResponse is the payload of the search endpoint. Total counts every
+// match; Results holds at most the requested limit, best first.
+type SearchResponse struct {
+	Query   string          `json:"query"`
+	Total   int             `json:"total"`
+	Results []search.Result `json:"results"`
+	// Excluded counts matches left out for conflicting with the signed-in
+	// user's dietary profile.
+	Excluded int `json:"excluded,omitempty"`
+}
+
+// SearchHandler runs a menu search query (see package search for the grammar)
+// against the stored weekly items and returns the matches ranked by text
+// relevance.
+//
+// Expected Authorization:
+//   - Optional. With a Firebase ID token, results that conflict with the
+//     user's dietary profile are annotated or excluded.
+//
+// Query Parameters:
+//   - q: the search query, e.g. `ramen location:allison protein>25 date:tomorrow`
+//     or `ingredient:tofu meal:lunch`.
+//   - limit: maximum number of results (default 50, max 200).
+//   - diet: annotate (default) or exclude results conflicting with the profile.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := searchResponse(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// searchResponse runs a search request for both API versions.
+func searchResponse(r *http.Request) (SearchResponse, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("q"))
+	if raw == "" {
+		return SearchResponse{}, badRequest("q parameter is required")
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return SearchResponse{}, badRequest("limit must be a positive integer")
+		}
+		limit = min(parsed, maxSearchLimit)
+	}
+
+	query, err := search.Parse(raw, campusNow())
+	if err != nil {
+		return SearchResponse{}, badRequest("Invalid search query: " + err.Error())
+	}
+	profile, exclude, err := requestDietaryProfile(r)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+
+	// Narrow the candidates with the menu index where the query allows it; the
+	// search itself re-applies every clause.
+	filter := models.MenuFilter{From: query.Date, To: query.Date, Meal: query.Meal, Ingredients: query.Ingredients}
+	candidates, ok := store.QueryMenu(filter)
+	if !ok {
+		fmt.Println("Menu store was empty, falling back to db for search")
+		candidates, err = db.QueryMenuItems(filter)
+		if err != nil {
+			return SearchResponse{}, internalError("Error fetching menu items", err)
+		}
+		store.Derive(candidates)
+	}
+
+	results := search.Run(candidates, query)
+	var excluded int
+	if !profile.Empty() {
+		kept := results[:0]
+		for _, result := range results {
+			conflicts := profile.Conflicts(result.DailyItem)
+			if len(conflicts) > 0 && exclude {
+				excluded++
+				continue
+			}
+			result.DietConflicts = conflicts
+			kept = append(kept, result)
+		}
+		results = kept
+	}
+
+	return SearchResponse{
+		Query:    raw,
+		Total:    len(results),
+		Results:  results[:min(limit, len(results))],
+		Excluded: excluded,
+	}, nil
+}
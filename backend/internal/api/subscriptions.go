@@ -0,0 +1,240 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/search"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxSubscriptionRules bounds how many rules one user may follow.
+	maxSubscriptionRules = 25
+	// maxRuleNameLength bounds a subscription rule's name, in bytes.
+	maxRuleNameLength = 100
+	// maxRuleQueryLength bounds a subscription rule's query, in bytes.
+	maxRuleQueryLength = 200
+)
+
+// GetSubscriptionRulesHandler lists the user's subscription rules, oldest
+// first.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetSubscriptionRulesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	rules, err := subscriptionRules(userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rules); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateSubscriptionRuleHandler adds a standing search to the user's alerts.
+// Menu items matching the rule are announced in meal notifications and the
+// favorites email just like favorites.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - JSON object with query, in the search query language without a date
+//     clause (e.g. "station:flame protein>30"), and an optional name that
+//     defaults to the query.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func CreateSubscriptionRuleHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.SubscriptionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule, err := createSubscriptionRule(userID, request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateSubscriptionRuleHandler replaces the name and query of one of the
+// user's subscription rules.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - The same JSON object CreateSubscriptionRuleHandler accepts; the rule ID
+//     is the {id} path segment.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func UpdateSubscriptionRuleHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.SubscriptionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule, err := updateSubscriptionRule(userID, mux.Vars(r)["id"], request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteSubscriptionRuleHandler removes one of the user's subscription rules.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - No body is expected in this request; the rule ID is the {id} path
+//     segment.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func DeleteSubscriptionRuleHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := deleteSubscriptionRule(userID, mux.Vars(r)["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// subscriptionRules lists the user's rules for both API versions.
+func subscriptionRules(userID string) ([]models.SubscriptionRule, error) {
+	rules, err := db.GetSubscriptionRules(userID)
+	if err != nil {
+		return nil, internalError("Error fetching subscription rules", err)
+	}
+	return rules, nil
+}
+
+// createSubscriptionRule validates and stores a new rule for both API
+// versions.
+func createSubscriptionRule(userID string, request models.SubscriptionRuleRequest) (models.SubscriptionRule, error) {
+	rule, err := normalizeSubscriptionRule(request)
+	if err != nil {
+		return models.SubscriptionRule{}, err
+	}
+
+	existing, err := subscriptionRules(userID)
+	if err != nil {
+		return models.SubscriptionRule{}, err
+	}
+	if len(existing) >= maxSubscriptionRules {
+		return models.SubscriptionRule{}, badRequest(fmt.Sprintf("a user may follow at most %d rules", maxSubscriptionRules))
+	}
+
+	rule, err = db.CreateSubscriptionRule(userID, rule)
+	if err != nil {
+		return models.SubscriptionRule{}, internalError("Error saving subscription rule", err)
+	}
+	return rule, nil
+}
+
+// updateSubscriptionRule validates and replaces a rule by its ID for both API
+// versions.
+func updateSubscriptionRule(userID, idText string, request models.SubscriptionRuleRequest) (models.SubscriptionRule, error) {
+	id, err := parseID(idText)
+	if err != nil {
+		return models.SubscriptionRule{}, err
+	}
+	rule, err := normalizeSubscriptionRule(request)
+	if err != nil {
+		return models.SubscriptionRule{}, err
+	}
+
+	rule, err = db.UpdateSubscriptionRule(userID, id, rule)
+	if errors.Is(err, db.NoSubscriptionRuleInDB) {
+		return models.SubscriptionRule{}, notFound("No subscription rule with id " + idText)
+	}
+	if err != nil {
+		return models.SubscriptionRule{}, internalError("Error saving subscription rule", err)
+	}
+	return rule, nil
+}
+
+// deleteSubscriptionRule removes a rule by its ID for both API versions.
+func deleteSubscriptionRule(userID, idText string) error {
+	id, err := parseID(idText)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteSubscriptionRule(userID, id)
+	if errors.Is(err, db.NoSubscriptionRuleInDB) {
+		return notFound("No subscription rule with id " + idText)
+	}
+	if err != nil {
+		return internalError("Error deleting subscription rule", err)
+	}
+	return nil
+}
+
+// normalizeSubscriptionRule trims and checks a rule request. The query must
+// parse as a rule so a typo is reported now rather than silently never
+// matching at alert time.
+func normalizeSubscriptionRule(request models.SubscriptionRuleRequest) (models.SubscriptionRule, error) {
+	query := strings.TrimSpace(request.Query)
+	if query == "" {
+		return models.SubscriptionRule{}, badRequest("query is required")
+	}
+	if len(query) > maxRuleQueryLength {
+		return models.SubscriptionRule{}, badRequest(fmt.Sprintf("query must be at most %d characters", maxRuleQueryLength))
+	}
+	if _, err := search.ParseRule(query); err != nil {
+		return models.SubscriptionRule{}, badRequest("Invalid query: " + err.Error())
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		name = query
+	}
+	if len(name) > maxRuleNameLength {
+		return models.SubscriptionRule{}, badRequest(fmt.Sprintf("name must be at most %d characters", maxRuleNameLength))
+	}
+	return models.SubscriptionRule{Name: name, Query: query}, nil
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"backend/internal/db"
+	"backend/internal/models"
+	"backend/internal/scrapejob"
+	"backend/internal/taxonomy"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// GetFilterTaxonomyHandler returns the filter taxonomy: how each upstream
+// filter name maps to a category (allergen, diet or marketing) and a canonical
+// key, plus the names seen on the menu that no mapping covers yet. Menu items
+// carry the resolved tags next to their raw filters.
+//
+// Expected Authorization:
+//   - No special authorization required.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetFilterTaxonomyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(filterTaxonomy()); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// SaveFilterMappingHandler creates or replaces the mapping for one filter
+// name, then retags the stored menu with it.
+//
+// Expected Authorization:
+//   - Admin level access.
+//
+// Expected Body:
+//   - JSON object with name (the base name, without "*"), category (allergen,
+//     diet or marketing) and an optional key, derived from the name when
+//     empty.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func SaveFilterMappingHandler(w http.ResponseWriter, r *http.Request) {
+	var request models.FilterMapping
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mapping, err := saveFilterMapping(request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(mapping); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DeleteFilterMappingHandler removes the mapping for one filter name, which is
+// reported as unmapped again the next time it appears on the menu.
+//
+// Expected Authorization:
+//   - Admin level access.
+//
+// Expected Body:
+//   - No body is expected in this request; the filter name is the {name}
+//     path segment.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func DeleteFilterMappingHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(mux.Vars(r)["name"])
+
+	if err := db.DeleteFilterMapping(name); err != nil {
+		if errors.Is(err, db.NoFilterMappingInDB) {
+			writeError(w, notFound("No filter mapping named "+name))
+			return
+		}
+		writeError(w, internalError("Error deleting filter mapping", err))
+		return
+	}
+	if err := reloadFilterTaxonomy(); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// filterTaxonomy returns the mappings in use and the unmapped names.
+func filterTaxonomy() models.FilterTaxonomy {
+	return models.FilterTaxonomy{Mappings: taxonomy.Mappings(), Unmapped: taxonomy.Unmapped()}
+}
+
+// saveFilterMapping validates and stores a mapping, filling in its key.
+func saveFilterMapping(request models.FilterMapping) (models.FilterMapping, error) {
+	request.Name = strings.TrimSpace(request.Name)
+	request.Key = strings.TrimSpace(request.Key)
+	request.Category = strings.ToLower(strings.TrimSpace(request.Category))
+	if err := request.Validate(); err != nil {
+		return models.FilterMapping{}, badRequest("Invalid filter mapping: " + err.Error())
+	}
+	if request.Key == "" {
+		request.Key = models.FilterKey(request.Name)
+	}
+
+	if err := db.SaveFilterMapping(request); err != nil {
+		return models.FilterMapping{}, internalError("Error saving filter mapping", err)
+	}
+	if err := reloadFilterTaxonomy(); err != nil {
+		return models.FilterMapping{}, err
+	}
+	return request, nil
+}
+
+// reloadFilterTaxonomy puts the database's mappings in use and retags the
+// in-memory menu with them.
+func reloadFilterTaxonomy() error {
+	mappings, err := db.GetFilterMappings()
+	if err != nil {
+		return internalError("Error loading filter mappings", err)
+	}
+	taxonomy.Set(mappings)
+	scrapejob.RefreshMenuStore()
+	return nil
+}
@@ -0,0 +1,893 @@
+package api
+
+import (
+	"backend/internal/cache"
+	"backend/internal/db"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/openapi"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// v2Prefix is where the v2 surface is mounted; documented paths include it.
+const v2Prefix = "/api/v2"
+
+// GeneralDataResponse is the v2 public data payload.
+type GeneralDataResponse struct {
+	AllItems               []string                        `json:"allItems"`
+	WeeklyItems            map[string][]models.DailyItem   `json:"weeklyItems"`
+	LocationOperatingTimes []models.LocationOperatingTimes `json:"locationOperatingTimes"`
+	NutritionGoals         models.NutritionGoals           `json:"nutritionGoals"`
+}
+
+// OperatingTimesResponse lists every location's operating hours.
+type OperatingTimesResponse struct {
+	LocationOperatingTimes []models.LocationOperatingTimes `json:"locationOperatingTimes"`
+}
+
+// UserSettingsResponse is everything the signed-in user has saved.
+type UserSettingsResponse struct {
+	Favorites          []string                   `json:"favorites"`
+	Mailing            *bool                      `json:"mailing"`
+	WeeklyReport       *bool                      `json:"weeklyReport"`
+	NutritionGoals     models.NutritionGoals      `json:"nutritionGoals"`
+	DisplayPreferences DisplayPreferencesResponse `json:"displayPreferences"`
+	DietaryProfile     models.DietaryProfile      `json:"dietaryProfile"`
+}
+
+// DisplayPreferencesResponse is the user's display settings and whether they
+// were ever saved (clients show defaults until then).
+type DisplayPreferencesResponse struct {
+	VisibleLocations           []string `json:"visibleLocations"`
+	HasSavedDisplayPreferences bool     `json:"hasSavedDisplayPreferences"`
+}
+
+// FavoritesBody replaces the user's favorite item names. It is both the
+// request and the response of the favorites endpoint.
+type FavoritesBody struct {
+	Favorites []string `json:"favorites"`
+}
+
+// MailingBody sets whether the user receives the daily favorites email.
+type MailingBody struct {
+	Mailing bool `json:"mailing"`
+}
+
+// v2Route is one v2 operation: its documentation and its handler.
+type v2Route struct {
+	openapi.Route
+	handler http.HandlerFunc
+}
+
+// v2Errors are the statuses every v2 operation may return in the error
+// envelope; routes add their own on top.
+var v2Errors = []int{http.StatusInternalServerError}
+
+// v2Routes is the single table the v2 router and the OpenAPI document are
+// both built from, so the document cannot drift from what is served.
+func v2Routes() []v2Route {
+	dietParam := openapi.Param{Name: "diet", Description: "Signed in only: annotate (default) or exclude items conflicting with the dietary profile."}
+	menuQuery := []openapi.Param{
+		{Name: "date", Description: "A single YYYY-MM-DD date; defaults to today on the campus clock."},
+		{Name: "from", Description: "Inclusive YYYY-MM-DD range start; cannot be combined with date."},
+		{Name: "to", Description: "Inclusive YYYY-MM-DD range end; cannot be combined with date."},
+		{Name: "location", Description: "Dining hall, matched case-insensitively."},
+		{Name: "meal", Description: "Meal period (time of day), matched case-insensitively."},
+		{Name: "station", Description: "Station, matched case-insensitively."},
+		dietParam,
+	}
+
+	return []v2Route{
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/generalData", OperationID: "getGeneralData", Tag: "menu",
+			Summary:  "Catalog, weekly menu and operating hours",
+			Response: GeneralDataResponse{},
+		}, handler: v2GeneralData},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/operatingTimes", OperationID: "getOperatingTimes", Tag: "hours",
+			Summary:  "Operating hours for every location",
+			Response: OperatingTimesResponse{},
+		}, handler: v2OperatingTimes},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/locations/status", OperationID: "getLocationStatus", Tag: "hours",
+			Summary:     "Which locations are open, closing soon or opening next",
+			Description: "Hours are read on the America/Chicago clock, including blocks that run past midnight.",
+			Query:       []openapi.Param{{Name: "at", Description: "RFC 3339 instant or campus-local YYYY-MM-DDTHH:MM; defaults to now."}},
+			Response:    LocationStatusResponse{},
+			Errors:      []int{http.StatusBadRequest},
+		}, handler: v2LocationStatus},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/menu", OperationID: "getMenu", Tag: "menu", OptionalAuth: true,
+			Summary:     "Menu items for a date range, optionally scoped to a hall, meal and station",
+			Description: "Signed in, items conflicting with the user's dietary profile are annotated or excluded.",
+			Query:       menuQuery,
+			Response:    models.MenuResponse{},
+			Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2Menu},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/menu/changes", OperationID: "getMenuChanges", Tag: "menu",
+			Summary:     "Menu slices replaced or cleared since a change version",
+			Description: "When reset is true the version is unknown and the client must refetch the full menu.",
+			Query:       []openapi.Param{{Name: "since", Type: "integer", Description: "Version from the previous call; 0 for none."}},
+			Response:    models.MenuChanges{},
+			Errors:      []int{http.StatusBadRequest},
+		}, handler: v2MenuChanges},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/search", OperationID: "searchMenu", Tag: "menu", OptionalAuth: true,
+			Summary:     "Search the menu with the query language",
+			Description: "Signed in, results conflicting with the user's dietary profile are annotated or excluded.",
+			Query: []openapi.Param{
+				{Name: "q", Required: true, Description: "Search query, e.g. `ramen location:allison protein>25 date:tomorrow` or `ingredient:tofu`."},
+				{Name: "limit", Type: "integer", Description: "Maximum results (default 50, max 200)."},
+				dietParam,
+			},
+			Response: SearchResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2Search},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/filters/taxonomy", OperationID: "getFilterTaxonomy", Tag: "menu",
+			Summary:     "How upstream filter names map to allergen, diet and marketing tags",
+			Description: "Menu items carry these resolved tags next to their raw filters; unmapped lists names no mapping covers yet.",
+			Response:    models.FilterTaxonomy{},
+		}, handler: v2FilterTaxonomy},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/items/suggest", OperationID: "suggestItems", Tag: "items",
+			Summary: "Typo-tolerant item name suggestions",
+			Query: []openapi.Param{
+				{Name: "q", Required: true, Description: "The partial item name typed so far."},
+				{Name: "limit", Type: "integer", Description: "Maximum suggestions (default 10, max 50)."},
+			},
+			Response: SuggestResponse{},
+			Errors:   []int{http.StatusBadRequest},
+		}, handler: v2SuggestItems},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/items/{name}", OperationID: "getItem", Tag: "items",
+			Summary:    "Latest nutrition and retained appearances of one item",
+			PathParams: []openapi.Param{{Name: "name", Description: "Item name, matched case-insensitively."}},
+			Response:   models.ItemDetail{},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound},
+		}, handler: v2ItemDetail},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/rankings", OperationID: "getRankings", Tag: "menu",
+			Summary:     "A day's items ranked by a nutrient-density metric, overall and per hall",
+			Description: "Items missing the nutrition data a metric needs, or under 50 calories, are skipped and counted in skipped. Halls with five ranked items are listed ahead of halls with fewer.",
+			Query: []openapi.Param{
+				{Name: "date", Description: "YYYY-MM-DD; defaults to today on the campus clock."},
+				{Name: "meal", Description: "Limit the ranking to one meal period."},
+				{Name: "metric", Description: "protein_per_cal (default), fiber or low_sodium."},
+				{Name: "limit", Type: "integer", Description: "Items ranked overall (default 10, max 50)."},
+			},
+			Response: RankingsResponse{},
+			Errors:   []int{http.StatusBadRequest},
+		}, handler: v2Rankings},
+		{Route: openapi.Route{
+			Method: http.MethodPost, Path: "/planner/suggest", OperationID: "suggestPlates", Tag: "planner", Auth: true,
+			Summary:     "Item combinations from one meal that best hit a calorie and macro target",
+			Description: "Without a budget the target is the meal's share of the user's nutrition goals.",
+			Request:     PlannerRequest{},
+			Response:    PlannerResponse{},
+			Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2SuggestPlates},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me", OperationID: "getUserSettings", Tag: "user", Auth: true,
+			Summary:  "Everything the signed-in user has saved",
+			Response: UserSettingsResponse{},
+			Errors:   []int{http.StatusUnauthorized},
+		}, handler: v2UserSettings},
+		{Route: openapi.Route{
+			Method: http.MethodPut, Path: "/me/favorites", OperationID: "putFavorites", Tag: "user", Auth: true,
+			Summary:  "Replace the user's favorite items",
+			Request:  FavoritesBody{},
+			Response: FavoritesBody{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2PutFavorites},
+		{Route: openapi.Route{
+			Method: http.MethodPut, Path: "/me/dietaryProfile", OperationID: "putDietaryProfile", Tag: "user", Auth: true,
+			Summary:     "Replace the allergens and ingredients the user avoids and the diets they require",
+			Description: "Avoiding a tag also avoids its \"may contain\" variant, e.g. Sesame and Sesame*. Avoided ingredients match sub-ingredients too, so cilantro excludes a salsa made with it.",
+			Request:     models.DietaryProfile{},
+			Response:    models.DietaryProfile{},
+			Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2PutDietaryProfile},
+		{Route: openapi.Route{
+			Method: http.MethodPut, Path: "/me/mailing", OperationID: "putMailing", Tag: "user", Auth: true,
+			Summary:  "Opt in or out of the daily favorites email",
+			Request:  MailingBody{},
+			Response: MailingBody{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2PutMailing},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me/nutritionGoals", OperationID: "getNutritionGoals", Tag: "user", Auth: true,
+			Summary:  "The user's nutrition goals, or the defaults",
+			Response: models.NutritionGoals{},
+			Errors:   []int{http.StatusUnauthorized},
+		}, handler: v2GetNutritionGoals},
+		{Route: openapi.Route{
+			Method: http.MethodPut, Path: "/me/nutritionGoals", OperationID: "putNutritionGoals", Tag: "user", Auth: true,
+			Summary:  "Save the user's nutrition goals",
+			Request:  models.NutritionGoals{},
+			Response: models.NutritionGoals{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2PutNutritionGoals},
+		{Route: openapi.Route{
+			Method: http.MethodPost, Path: "/me/nutritionGoals/calculate", OperationID: "calculateNutritionGoals", Tag: "user", Auth: true,
+			Summary:     "Nutrition goals derived from body stats, activity level and objective",
+			Description: "Uses Mifflin-St Jeor for resting energy. Nothing is stored unless save or rememberStats is set.",
+			Request:     models.GoalCalculationRequest{},
+			Response:    models.GoalCalculation{},
+			Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2CalculateNutritionGoals},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me/bodyStats", OperationID: "getBodyStats", Tag: "user", Auth: true,
+			Summary:  "The goal calculator inputs the user chose to remember",
+			Response: models.BodyStats{},
+			Errors:   []int{http.StatusUnauthorized, http.StatusNotFound},
+		}, handler: v2GetBodyStats},
+		{Route: openapi.Route{
+			Method: http.MethodDelete, Path: "/me/bodyStats", OperationID: "deleteBodyStats", Tag: "user", Auth: true,
+			Summary: "Forget the remembered goal calculator inputs",
+			Status:  http.StatusNoContent,
+			Errors:  []int{http.StatusUnauthorized},
+		}, handler: v2DeleteBodyStats},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me/foodLog", OperationID: "getFoodLog", Tag: "user", Auth: true,
+			Summary:  "The user's food log for one day",
+			Query:    []openapi.Param{{Name: "date", Description: "YYYY-MM-DD; defaults to today on the campus clock."}},
+			Response: models.FoodLog{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2GetFoodLog},
+		{Route: openapi.Route{
+			Method: http.MethodPost, Path: "/me/foodLog", OperationID: "addFoodLogEntry", Tag: "user", Auth: true,
+			Summary:  "Log servings of a stored menu item",
+			Request:  models.FoodLogRequest{},
+			Response: models.FoodLogEntry{},
+			Status:   http.StatusCreated,
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		}, handler: v2AddFoodLogEntry},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me/foodLog/summary", OperationID: "getFoodLogSummary", Tag: "user", Auth: true,
+			Summary:  "A day's logged totals compared with the user's goals",
+			Query:    []openapi.Param{{Name: "date", Description: "YYYY-MM-DD; defaults to today on the campus clock."}},
+			Response: models.FoodLogSummary{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2GetFoodLogSummary},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me/foodLog/weekly", OperationID: "getWeeklyReport", Tag: "user", Auth: true,
+			Summary:  "Seven days of the user's food log rolled up against their goals",
+			Query:    []openapi.Param{{Name: "date", Description: "YYYY-MM-DD last day of the week; defaults to today on the campus clock."}},
+			Response: models.WeeklyReport{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2GetWeeklyReport},
+		{Route: openapi.Route{
+			Method: http.MethodPut, Path: "/me/weeklyReport", OperationID: "putWeeklyReport", Tag: "user", Auth: true,
+			Summary:  "Opt in or out of the weekly food log report email",
+			Request:  WeeklyReportBody{},
+			Response: WeeklyReportBody{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2PutWeeklyReport},
+		{Route: openapi.Route{
+			Method: http.MethodDelete, Path: "/me/foodLog/{id}", OperationID: "deleteFoodLogEntry", Tag: "user", Auth: true,
+			Summary:    "Remove a food log entry",
+			PathParams: []openapi.Param{{Name: "id", Type: "integer", Description: "The entry's id."}},
+			Status:     http.StatusNoContent,
+			Errors:     []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		}, handler: v2DeleteFoodLogEntry},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me/plates", OperationID: "getSavedPlates", Tag: "planner", Auth: true,
+			Summary:  "The user's saved plates, oldest first",
+			Response: []models.SavedPlate{},
+			Errors:   []int{http.StatusUnauthorized},
+		}, handler: v2GetSavedPlates},
+		{Route: openapi.Route{
+			Method: http.MethodPost, Path: "/me/plates", OperationID: "createSavedPlate", Tag: "planner", Auth: true,
+			Summary:     "Save a named combination of menu items",
+			Description: "Items are matched to the menu like favorites, so any spelling of an item matches. Servings default to 1.",
+			Request:     models.SavedPlateRequest{},
+			Response:    models.SavedPlate{},
+			Status:      http.StatusCreated,
+			Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2CreateSavedPlate},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me/plates/availability", OperationID: "getPlateAvailability", Tag: "planner", Auth: true,
+			Summary:  "Upcoming dates, meals and halls serving every item on each saved plate",
+			Response: models.PlateAvailabilityResponse{},
+			Errors:   []int{http.StatusUnauthorized},
+		}, handler: v2GetPlateAvailability},
+		{Route: openapi.Route{
+			Method: http.MethodPut, Path: "/me/plates/{id}", OperationID: "updateSavedPlate", Tag: "planner", Auth: true,
+			Summary:    "Replace a saved plate's name and items",
+			PathParams: []openapi.Param{{Name: "id", Type: "integer", Description: "The plate's id."}},
+			Request:    models.SavedPlateRequest{},
+			Response:   models.SavedPlate{},
+			Errors:     []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		}, handler: v2UpdateSavedPlate},
+		{Route: openapi.Route{
+			Method: http.MethodDelete, Path: "/me/plates/{id}", OperationID: "deleteSavedPlate", Tag: "planner", Auth: true,
+			Summary:    "Remove a saved plate",
+			PathParams: []openapi.Param{{Name: "id", Type: "integer", Description: "The plate's id."}},
+			Status:     http.StatusNoContent,
+			Errors:     []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		}, handler: v2DeleteSavedPlate},
+		{Route: openapi.Route{
+			Method: http.MethodGet, Path: "/me/subscriptions", OperationID: "getSubscriptionRules", Tag: "user", Auth: true,
+			Summary:  "The user's subscription rules, oldest first",
+			Response: []models.SubscriptionRule{},
+			Errors:   []int{http.StatusUnauthorized},
+		}, handler: v2GetSubscriptionRules},
+		{Route: openapi.Route{
+			Method: http.MethodPost, Path: "/me/subscriptions", OperationID: "createSubscriptionRule", Tag: "user", Auth: true,
+			Summary:     "Follow a standing menu search like a favorite",
+			Description: "The query uses the search language without a date clause, e.g. `tag:vegan location:sargent`. Matching items are included in meal notifications and the favorites email. The name defaults to the query.",
+			Request:     models.SubscriptionRuleRequest{},
+			Response:    models.SubscriptionRule{},
+			Status:      http.StatusCreated,
+			Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: v2CreateSubscriptionRule},
+		{Route: openapi.Route{
+			Method: http.MethodPut, Path: "/me/subscriptions/{id}", OperationID: "updateSubscriptionRule", Tag: "user", Auth: true,
+			Summary:    "Replace a subscription rule's name and query",
+			PathParams: []openapi.Param{{Name: "id", Type: "integer", Description: "The rule's id."}},
+			Request:    models.SubscriptionRuleRequest{},
+			Response:   models.SubscriptionRule{},
+			Errors:     []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		}, handler: v2UpdateSubscriptionRule},
+		{Route: openapi.Route{
+			Method: http.MethodDelete, Path: "/me/subscriptions/{id}", OperationID: "deleteSubscriptionRule", Tag: "user", Auth: true,
+			Summary:    "Stop following a subscription rule",
+			PathParams: []openapi.Param{{Name: "id", Type: "integer", Description: "The rule's id."}},
+			Status:     http.StatusNoContent,
+			Errors:     []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound},
+		}, handler: v2DeleteSubscriptionRule},
+	}
+}
+
+// RegisterV2 mounts the v2 API on router, which must be served under /api/v2,
+// along with its OpenAPI document at /openapi.json.
+func RegisterV2(router *mux.Router) {
+	for _, route := range v2Routes() {
+		handler := route.handler
+		if route.Auth {
+			handler = middleware.AuthMiddleware(handler)
+		} else if route.OptionalAuth {
+			handler = middleware.OptionalAuthMiddleware(handler)
+		}
+		router.HandleFunc(route.Path, handler).Methods(route.Method, http.MethodOptions)
+	}
+	router.HandleFunc("/openapi.json", V2OpenAPIHandler).Methods(http.MethodGet, http.MethodOptions)
+}
+
+// v2Document builds the OpenAPI document once from the route table.
+var v2Document = sync.OnceValue(func() openapi.Document {
+	builder := openapi.NewBuilder(
+		"NUFood API",
+		"2.0.0",
+		"Northwestern dining menus, hours and user settings. Every error is returned as an ErrorResponse.",
+	)
+	builder.BearerAuth("firebase", "Firebase ID token", "A Firebase ID token for the signed-in user.")
+	builder.ErrorBody(middleware.ErrorResponse{})
+	builder.Tag("menu", "Menus, search and delta sync")
+	builder.Tag("items", "The food catalog")
+	builder.Tag("hours", "Location operating hours")
+	builder.Tag("planner", "Meal planning")
+	builder.Tag("user", "The signed-in user's settings")
+
+	for _, route := range v2Routes() {
+		documented := route.Route
+		documented.Path = v2Prefix + route.Path
+		documented.Errors = append(append([]int(nil), route.Errors...), v2Errors...)
+		builder.Add(documented)
+	}
+	return builder.Document()
+})
+
+// V2OpenAPIHandler serves the OpenAPI 3 document describing the v2 API, for
+// generating client models.
+//
+// Expected Authorization:
+//   - No special authorization required.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func V2OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	writeV2JSON(w, http.StatusOK, v2Document())
+}
+
+// writeV2JSON writes a successful v2 response.
+func writeV2JSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		// The status line is already sent; all that is left is to log it.
+		log.Printf("Error encoding v2 JSON response: %v", err)
+	}
+}
+
+// writeV2Error reports err in the v2 error envelope.
+func writeV2Error(w http.ResponseWriter, err error) {
+	status, message := errorStatus(err)
+	middleware.SendJSONError(w, message, status)
+}
+
+// decodeV2Body decodes a JSON request body, rejecting unknown fields so
+// client typos fail loudly instead of being ignored.
+func decodeV2Body(r *http.Request, body any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		return badRequest("Error decoding JSON: " + err.Error())
+	}
+	return nil
+}
+
+func v2GeneralData(w http.ResponseWriter, r *http.Request) {
+	allItems, err := loadAllDataItems()
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	weeklyItems, err := loadWeeklyItems()
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	locationOperatingTimes, err := loadOperatingTimes()
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	writeV2JSON(w, http.StatusOK, GeneralDataResponse{
+		AllItems:               allDataItemsToStrings(allItems),
+		WeeklyItems:            weeklyItems,
+		LocationOperatingTimes: locationOperatingTimes,
+		NutritionGoals:         models.DefaultNutritionGoals(),
+	})
+}
+
+func v2OperatingTimes(w http.ResponseWriter, r *http.Request) {
+	locationOperatingTimes, err := loadOperatingTimes()
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, OperatingTimesResponse{LocationOperatingTimes: locationOperatingTimes})
+}
+
+func v2LocationStatus(w http.ResponseWriter, r *http.Request) {
+	response, err := locationStatusResponse(r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, response)
+}
+
+func v2Menu(w http.ResponseWriter, r *http.Request) {
+	response, err := menuResponse(r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, response)
+}
+
+func v2MenuChanges(w http.ResponseWriter, r *http.Request) {
+	changes, err := menuChangesResponse(r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, changes)
+}
+
+func v2Search(w http.ResponseWriter, r *http.Request) {
+	response, err := searchResponse(r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, response)
+}
+
+func v2FilterTaxonomy(w http.ResponseWriter, r *http.Request) {
+	writeV2JSON(w, http.StatusOK, filterTaxonomy())
+}
+
+func v2Rankings(w http.ResponseWriter, r *http.Request) {
+	response, err := rankingsResponse(r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, response)
+}
+
+func v2SuggestItems(w http.ResponseWriter, r *http.Request) {
+	response, err := suggestResponse(r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, response)
+}
+
+func v2ItemDetail(w http.ResponseWriter, r *http.Request) {
+	detail, err := itemDetailResponse(mux.Vars(r)["name"])
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, detail)
+}
+
+func v2SuggestPlates(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request PlannerRequest
+	if err := decodeV2Body(r, &request); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	response, err := suggestPlates(userID, request)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, response)
+}
+
+func v2UserSettings(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	user, err := loadUserData(userID)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	visibleLocations := user.DisplayPreferences.VisibleLocations
+	if visibleLocations == nil {
+		visibleLocations = []string{}
+	}
+	writeV2JSON(w, http.StatusOK, UserSettingsResponse{
+		Favorites:      allDataItemsToStrings(user.Preferences),
+		Mailing:        user.Mailing,
+		WeeklyReport:   user.WeeklyReport,
+		NutritionGoals: user.NutritionGoals,
+		DisplayPreferences: DisplayPreferencesResponse{
+			VisibleLocations:           visibleLocations,
+			HasSavedDisplayPreferences: user.HasSavedDisplayPreferences,
+		},
+		DietaryProfile: user.DietaryProfile,
+	})
+}
+
+func v2PutFavorites(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var body FavoritesBody
+	if err := decodeV2Body(r, &body); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	favorites := stringsToAllDataItems(body.Favorites)
+	if err := db.SaveUserPreferences(userID, favorites); err != nil {
+		writeV2Error(w, internalError("Error saving user preferences", err))
+		return
+	}
+	cache.SetUserPreferences(userID, favorites)
+
+	writeV2JSON(w, http.StatusOK, FavoritesBody{Favorites: allDataItemsToStrings(favorites)})
+}
+
+func v2PutDietaryProfile(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var body models.DietaryProfile
+	if err := decodeV2Body(r, &body); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	profile, err := saveDietaryProfile(userID, body)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, profile)
+}
+
+func v2PutMailing(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var body MailingBody
+	if err := decodeV2Body(r, &body); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	if err := db.UpdateMailingStatus(userID, body.Mailing); err != nil {
+		writeV2Error(w, internalError("Error updating mail value for user preferences", err))
+		return
+	}
+	cache.SetUserMailing(userID, body.Mailing)
+
+	writeV2JSON(w, http.StatusOK, body)
+}
+
+func v2GetNutritionGoals(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	user, err := loadUserData(userID)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, user.NutritionGoals)
+}
+
+func v2PutNutritionGoals(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var goals models.NutritionGoals
+	if err := decodeV2Body(r, &goals); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	if err := goals.Validate(); err != nil {
+		writeV2Error(w, badRequest("Invalid nutrition goals: "+err.Error()))
+		return
+	}
+
+	if err := db.SaveNutritionGoals(userID, goals); err != nil {
+		writeV2Error(w, internalError("Error saving nutrition goals", err))
+		return
+	}
+	cache.SetUserNutritionGoals(userID, goals)
+
+	writeV2JSON(w, http.StatusOK, goals)
+}
+
+func v2CalculateNutritionGoals(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.GoalCalculationRequest
+	if err := decodeV2Body(r, &request); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	result, err := calculateNutritionGoals(userID, request)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, result)
+}
+
+func v2GetBodyStats(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	stats, err := bodyStats(userID)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, stats)
+}
+
+func v2DeleteBodyStats(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := deleteBodyStats(userID); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func v2GetFoodLog(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	log, err := foodLogResponse(userID, r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, log)
+}
+
+func v2GetWeeklyReport(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	report, err := weeklyReportResponse(userID, r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, report)
+}
+
+func v2PutWeeklyReport(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var body WeeklyReportBody
+	if err := decodeV2Body(r, &body); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	if err := db.UpdateWeeklyReportStatus(userID, body.WeeklyReport); err != nil {
+		writeV2Error(w, internalError("Error updating weekly report preference", err))
+		return
+	}
+	cache.SetUserWeeklyReport(userID, body.WeeklyReport)
+	writeV2JSON(w, http.StatusOK, body)
+}
+
+func v2AddFoodLogEntry(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.FoodLogRequest
+	if err := decodeV2Body(r, &request); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	entry, err := addFoodLogEntry(userID, request)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusCreated, entry)
+}
+
+func v2GetFoodLogSummary(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	summary, err := foodLogSummaryResponse(userID, r)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, summary)
+}
+
+func v2DeleteFoodLogEntry(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := deleteFoodLogEntry(userID, mux.Vars(r)["id"]); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func v2GetSavedPlates(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	plates, err := savedPlates(userID)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, plates)
+}
+
+func v2CreateSavedPlate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.SavedPlateRequest
+	if err := decodeV2Body(r, &request); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	plate, err := createSavedPlate(userID, request)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusCreated, plate)
+}
+
+func v2GetPlateAvailability(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	availability, err := plateAvailability(userID)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, availability)
+}
+
+func v2UpdateSavedPlate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.SavedPlateRequest
+	if err := decodeV2Body(r, &request); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	plate, err := updateSavedPlate(userID, mux.Vars(r)["id"], request)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, plate)
+}
+
+func v2DeleteSavedPlate(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := deleteSavedPlate(userID, mux.Vars(r)["id"]); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func v2GetSubscriptionRules(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	rules, err := subscriptionRules(userID)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, rules)
+}
+
+func v2CreateSubscriptionRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.SubscriptionRuleRequest
+	if err := decodeV2Body(r, &request); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	rule, err := createSubscriptionRule(userID, request)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusCreated, rule)
+}
+
+func v2UpdateSubscriptionRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var request models.SubscriptionRuleRequest
+	if err := decodeV2Body(r, &request); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+
+	rule, err := updateSubscriptionRule(userID, mux.Vars(r)["id"], request)
+	if err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	writeV2JSON(w, http.StatusOK, rule)
+}
+
+func v2DeleteSubscriptionRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	if err := deleteSubscriptionRule(userID, mux.Vars(r)["id"]); err != nil {
+		writeV2Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"backend/internal/cache"
+	"backend/internal/db"
+	"backend/internal/foodlog"
+	"backend/internal/mailer"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// WeeklyReportBody sets whether the user receives the weekly food log report
+// email.
+type WeeklyReportBody struct {
+	WeeklyReport bool `json:"weeklyReport"`
+}
+
+// GetWeeklyReportHandler rolls up the seven days of the user's food log ending
+// on date: per-day totals against their nutrition goals, days over or under
+// target, average macros, and the items and halls they logged most.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Query Parameters:
+//   - date: the YYYY-MM-DD last day of the week (defaults to today on the
+//     campus clock).
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func GetWeeklyReportHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	report, err := weeklyReportResponse(userID, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// SetWeeklyReportMailing opts the user in or out of the weekly food log report
+// email.
+//
+// Expected Authorization:
+//   - A valid Firebase ID token in the Authorization header.
+//
+// Expected Body:
+//   - JSON object with a boolean weeklyReport.
+//
+// Parameters:
+//   - w: The HTTP response writer.
+//   - r: The HTTP request.
+func SetWeeklyReportMailing(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+
+	var body WeeklyReportBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Error decoding JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := db.UpdateWeeklyReportStatus(userID, body.WeeklyReport); err != nil {
+		http.Error(w, "Error updating weekly report preference: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	cache.SetUserWeeklyReport(userID, body.WeeklyReport)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SendOutWeeklyReports emails the weekly report for the week ending today to
+// every opted-in user, outside the Sunday schedule.
+func SendOutWeeklyReports(w http.ResponseWriter, r *http.Request) {
+	if err := mailer.SendWeeklyReports(campusNow().Format(time.DateOnly)); err != nil {
+		http.Error(w, "Error sending out weekly reports: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// weeklyReportResponse builds the weekly report for both API versions.
+func weeklyReportResponse(userID string, r *http.Request) (models.WeeklyReport, error) {
+	end, err := parseLogDate(r.URL.Query().Get("date"))
+	if err != nil {
+		return models.WeeklyReport{}, err
+	}
+	days, err := foodlog.WeekEnding(end)
+	if err != nil {
+		return models.WeeklyReport{}, badRequest(err.Error())
+	}
+
+	user, err := loadUserData(userID)
+	if err != nil {
+		return models.WeeklyReport{}, err
+	}
+	entries, err := db.GetFoodLogRange(userID, days[0], days[len(days)-1])
+	if err != nil {
+		return models.WeeklyReport{}, internalError("Error fetching food log", err)
+	}
+	return foodlog.Weekly(days, entries, user.NutritionGoals), nil
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"backend/internal/models"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,10 +13,27 @@ type UserData struct {
 	Preferences                []models.AllDataItem
 	NutritionGoals             models.NutritionGoals
 	Mailing                    *bool
+	WeeklyReport               *bool
 	DisplayPreferences         models.DisplayPreferences
 	HasSavedDisplayPreferences bool
+	DietaryProfile             models.DietaryProfile
 	LastUpdated                time.Time
 	TTL                        time.Duration
+	// Generation changes whenever this entry is (re)built or modified, so it
+	// can be folded into the ETag of responses that include user data.
+	Generation uint64
+	// FoodLogs holds the food log days read so far, keyed by YYYY-MM-DD. No
+	// response that carries the generation includes the food log, so food
+	// log changes leave it alone.
+	FoodLogs map[string][]models.FoodLogEntry
+}
+
+// generations hands out cache generations. It is shared by every user so a
+// generation is never reused, even after an entry is evicted and rebuilt.
+var generations atomic.Uint64
+
+func nextGeneration() uint64 {
+	return generations.Add(1)
 }
 
 // IsExpired checks if the cached data has expired
@@ -69,15 +87,18 @@ func (uc *UserCache) GetUserData(userID string) (*UserData, bool) {
 	return userData, true
 }
 
-// SetUserData caches user data with default TTL
+// SetUserData caches user data with default TTL and returns the generation
+// assigned to the new entry
 func (uc *UserCache) SetUserData(
 	userID string,
 	preferences []models.AllDataItem,
 	nutritionGoals models.NutritionGoals,
 	mailing *bool,
+	weeklyReport *bool,
 	displayPreferences models.DisplayPreferences,
 	hasSavedDisplayPreferences bool,
-) {
+	dietaryProfile models.DietaryProfile,
+) uint64 {
 	uc.mu.Lock()
 	defer uc.mu.Unlock()
 
@@ -86,16 +107,21 @@ func (uc *UserCache) SetUserData(
 		uc.evictOldestUser()
 	}
 
+	generation := nextGeneration()
 	uc.users[userID] = &UserData{
 		UserID:                     userID,
 		Preferences:                preferences,
 		NutritionGoals:             nutritionGoals,
 		Mailing:                    mailing,
+		WeeklyReport:               weeklyReport,
 		DisplayPreferences:         displayPreferences,
 		HasSavedDisplayPreferences: hasSavedDisplayPreferences,
+		DietaryProfile:             dietaryProfile,
 		LastUpdated:                time.Now(),
 		TTL:                        uc.defaultTTL,
+		Generation:                 generation,
 	}
+	return generation
 }
 
 // SetUserPreferences updates only the preferences for a user
@@ -106,6 +132,7 @@ func (uc *UserCache) SetUserPreferences(userID string, preferences []models.AllD
 	if userData, exists := uc.users[userID]; exists {
 		userData.Preferences = preferences
 		userData.LastUpdated = time.Now()
+		userData.Generation = nextGeneration()
 	}
 }
 
@@ -117,6 +144,7 @@ func (uc *UserCache) SetUserNutritionGoals(userID string, goals models.Nutrition
 	if userData, exists := uc.users[userID]; exists {
 		userData.NutritionGoals = goals
 		userData.LastUpdated = time.Now()
+		userData.Generation = nextGeneration()
 	}
 }
 
@@ -128,6 +156,19 @@ func (uc *UserCache) SetUserMailing(userID string, mailing bool) {
 	if userData, exists := uc.users[userID]; exists {
 		userData.Mailing = &mailing
 		userData.LastUpdated = time.Now()
+		userData.Generation = nextGeneration()
+	}
+}
+
+// SetUserWeeklyReport updates only the weekly report opt-in for a user
+func (uc *UserCache) SetUserWeeklyReport(userID string, weeklyReport bool) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if userData, exists := uc.users[userID]; exists {
+		userData.WeeklyReport = &weeklyReport
+		userData.LastUpdated = time.Now()
+		userData.Generation = nextGeneration()
 	}
 }
 
@@ -139,6 +180,60 @@ func (uc *UserCache) SetUserDisplayPreferences(userID string, displayPreferences
 		userData.DisplayPreferences = displayPreferences
 		userData.HasSavedDisplayPreferences = true
 		userData.LastUpdated = time.Now()
+		userData.Generation = nextGeneration()
+	}
+}
+
+// SetUserDietaryProfile updates only the dietary profile for a user
+func (uc *UserCache) SetUserDietaryProfile(userID string, profile models.DietaryProfile) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if userData, exists := uc.users[userID]; exists {
+		userData.DietaryProfile = profile
+		userData.LastUpdated = time.Now()
+		userData.Generation = nextGeneration()
+	}
+}
+
+// GetUserFoodLog returns a copy of the cached food log for one of the user's
+// days, if that day has been cached
+func (uc *UserCache) GetUserFoodLog(userID, date string) ([]models.FoodLogEntry, bool) {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+
+	userData, exists := uc.users[userID]
+	if !exists || userData.IsExpired() {
+		return nil, false
+	}
+	entries, cached := userData.FoodLogs[date]
+	if !cached {
+		return nil, false
+	}
+	return append([]models.FoodLogEntry{}, entries...), true
+}
+
+// SetUserFoodLog caches one day of the user's food log. Like the other
+// partial setters it only updates users that are already cached
+func (uc *UserCache) SetUserFoodLog(userID, date string, entries []models.FoodLogEntry) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if userData, exists := uc.users[userID]; exists {
+		if userData.FoodLogs == nil {
+			userData.FoodLogs = make(map[string][]models.FoodLogEntry)
+		}
+		userData.FoodLogs[date] = append([]models.FoodLogEntry{}, entries...)
+	}
+}
+
+// InvalidateUserFoodLog drops one cached day of the user's food log
+func (uc *UserCache) InvalidateUserFoodLog(userID, date string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if userData, exists := uc.users[userID]; exists {
+		delete(userData.FoodLogs, date)
 	}
 }
 
@@ -208,18 +303,22 @@ func GetUserData(userID string) (*UserData, bool) {
 	return userCache.GetUserData(userID)
 }
 
-// SetUserData caches user data in the global cache
+// SetUserData caches user data in the global cache and returns the entry's
+// generation, or 0 when the cache is not initialized
 func SetUserData(
 	userID string,
 	preferences []models.AllDataItem,
 	nutritionGoals models.NutritionGoals,
 	mailing *bool,
+	weeklyReport *bool,
 	displayPreferences models.DisplayPreferences,
 	hasSavedDisplayPreferences bool,
-) {
-	if userCache != nil {
-		userCache.SetUserData(userID, preferences, nutritionGoals, mailing, displayPreferences, hasSavedDisplayPreferences)
+	dietaryProfile models.DietaryProfile,
+) uint64 {
+	if userCache == nil {
+		return 0
 	}
+	return userCache.SetUserData(userID, preferences, nutritionGoals, mailing, weeklyReport, displayPreferences, hasSavedDisplayPreferences, dietaryProfile)
 }
 
 // SetUserPreferences updates user preferences in the global cache
@@ -243,12 +342,48 @@ func SetUserMailing(userID string, mailing bool) {
 	}
 }
 
+// SetUserWeeklyReport updates a user's weekly report opt-in in the global cache
+func SetUserWeeklyReport(userID string, weeklyReport bool) {
+	if userCache != nil {
+		userCache.SetUserWeeklyReport(userID, weeklyReport)
+	}
+}
+
 func SetUserDisplayPreferences(userID string, displayPreferences models.DisplayPreferences) {
 	if userCache != nil {
 		userCache.SetUserDisplayPreferences(userID, displayPreferences)
 	}
 }
 
+// SetUserDietaryProfile updates a user's dietary profile in the global cache
+func SetUserDietaryProfile(userID string, profile models.DietaryProfile) {
+	if userCache != nil {
+		userCache.SetUserDietaryProfile(userID, profile)
+	}
+}
+
+// GetUserFoodLog retrieves one cached day of a user's food log from the global cache
+func GetUserFoodLog(userID, date string) ([]models.FoodLogEntry, bool) {
+	if userCache == nil {
+		return nil, false
+	}
+	return userCache.GetUserFoodLog(userID, date)
+}
+
+// SetUserFoodLog caches one day of a user's food log in the global cache
+func SetUserFoodLog(userID, date string, entries []models.FoodLogEntry) {
+	if userCache != nil {
+		userCache.SetUserFoodLog(userID, date, entries)
+	}
+}
+
+// InvalidateUserFoodLog drops one cached day of a user's food log from the global cache
+func InvalidateUserFoodLog(userID, date string) {
+	if userCache != nil {
+		userCache.InvalidateUserFoodLog(userID, date)
+	}
+}
+
 // InvalidateUser removes a user from the global cache
 func InvalidateUser(userID string) {
 	if userCache != nil {
@@ -0,0 +1,172 @@
+// Package calculator derives nutrition goals from body stats: the
+// Mifflin-St Jeor equation for resting energy, an activity multiplier for
+// daily energy, an objective adjustment, and a macro split.
+package calculator
+
+import (
+	"backend/internal/models"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Accepted input bounds. The equation is fitted on adults, and values far
+// outside these are almost always unit mistakes (inches, pounds).
+const (
+	MinAge      = 13
+	MaxAge      = 100
+	MinHeightCm = 120
+	MaxHeightCm = 230
+	MinWeightKg = 35
+	MaxWeightKg = 250
+)
+
+// activityFactors scale resting energy to daily energy, keyed by normalized
+// activity level.
+var activityFactors = map[string]float64{
+	"sedentary":  1.2,
+	"light":      1.375,
+	"moderate":   1.55,
+	"active":     1.725,
+	"veryactive": 1.9,
+}
+
+// activityNames are the canonical spellings returned in normalized stats.
+var activityNames = map[string]string{
+	"sedentary":  "sedentary",
+	"light":      "light",
+	"moderate":   "moderate",
+	"active":     "active",
+	"veryactive": "veryActive",
+}
+
+// objective adjusts daily energy and sets protein per kilogram of body
+// weight.
+type objective struct {
+	energy       float64
+	proteinPerKg float64
+}
+
+var objectives = map[string]objective{
+	"cut":      {energy: 0.80, proteinPerKg: 2.0},
+	"maintain": {energy: 1.00, proteinPerKg: 1.6},
+	"bulk":     {energy: 1.10, proteinPerKg: 1.8},
+}
+
+// Macro and limit rules applied to the calorie goal.
+const (
+	fatShare        = 0.25 // of calories
+	sugarShare      = 0.10 // of calories, as a cap
+	fiberPer1000    = 14.0 // grams per 1000 kcal
+	sodiumMaxMg     = 2300
+	calorieBand     = 0.05 // the calorie range is the goal ± 5%
+	caloriesPerGram = 4.0  // protein and carbs
+	fatCalories     = 9.0
+)
+
+// Calculate validates stats and returns the energy needs and goals they imply.
+func Calculate(stats models.BodyStats) (models.GoalCalculation, error) {
+	stats, err := Normalize(stats)
+	if err != nil {
+		return models.GoalCalculation{}, err
+	}
+
+	bmr := 10*stats.WeightKg + 6.25*stats.HeightCm - 5*float64(stats.Age) + sexOffset(stats.Sex)
+	tdee := bmr * activityFactors[key(stats.ActivityLevel)]
+	goal := objectives[stats.Objective]
+
+	calories := roundTo(tdee*goal.energy, 10)
+	protein := math.Round(stats.WeightKg * goal.proteinPerKg)
+	fat := math.Round(calories * fatShare / fatCalories)
+	carbs := math.Max(0, math.Round((calories-protein*caloriesPerGram-fat*fatCalories)/caloriesPerGram))
+
+	sodium := float64(sodiumMaxMg)
+	sugar := math.Round(calories * sugarShare / caloriesPerGram)
+	fiber := math.Round(calories / 1000 * fiberPer1000)
+
+	return models.GoalCalculation{
+		Stats: stats,
+		BMR:   math.Round(bmr),
+		TDEE:  math.Round(tdee),
+		Goals: models.NutritionGoals{
+			Calories: calories,
+			Protein:  protein,
+			Carbs:    carbs,
+			Fat:      fat,
+			CaloriesRange: &models.GoalRange{
+				Min: roundTo(calories*(1-calorieBand), 10),
+				Max: roundTo(calories*(1+calorieBand), 10),
+			},
+			SodiumMax: &sodium,
+			SugarMax:  &sugar,
+			FiberMin:  &fiber,
+		},
+	}, nil
+}
+
+// Normalize checks stats against the accepted bounds and returns them with
+// sex, activity level and objective in their canonical spelling.
+func Normalize(stats models.BodyStats) (models.BodyStats, error) {
+	if stats.Age < MinAge || stats.Age > MaxAge {
+		return stats, fmt.Errorf("age must be between %d and %d", MinAge, MaxAge)
+	}
+	if math.IsNaN(stats.HeightCm) || stats.HeightCm < MinHeightCm || stats.HeightCm > MaxHeightCm {
+		return stats, fmt.Errorf("heightCm must be between %d and %d", MinHeightCm, MaxHeightCm)
+	}
+	if math.IsNaN(stats.WeightKg) || stats.WeightKg < MinWeightKg || stats.WeightKg > MaxWeightKg {
+		return stats, fmt.Errorf("weightKg must be between %d and %d", MinWeightKg, MaxWeightKg)
+	}
+
+	activity, ok := activityNames[key(stats.ActivityLevel)]
+	if !ok {
+		return stats, fmt.Errorf("activityLevel must be one of sedentary, light, moderate, active or veryActive")
+	}
+	stats.ActivityLevel = activity
+
+	stats.Objective = key(stats.Objective)
+	if stats.Objective == "" {
+		stats.Objective = "maintain"
+	}
+	if _, ok := objectives[stats.Objective]; !ok {
+		return stats, fmt.Errorf("objective must be one of cut, maintain or bulk")
+	}
+
+	switch key(stats.Sex) {
+	case "male", "m":
+		stats.Sex = "male"
+	case "female", "f":
+		stats.Sex = "female"
+	default:
+		stats.Sex = ""
+	}
+	return stats, nil
+}
+
+// sexOffset is the Mifflin-St Jeor constant: +5 for men, -161 for women, and
+// their midpoint when sex is not given.
+func sexOffset(sex string) float64 {
+	switch sex {
+	case "male":
+		return 5
+	case "female":
+		return -161
+	}
+	return -78
+}
+
+// key lowercases value and drops everything but letters, so "Very Active",
+// "very_active" and "veryActive" match.
+func key(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if unicode.IsLetter(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+func roundTo(value, step float64) float64 {
+	return math.Round(value/step) * step
+}
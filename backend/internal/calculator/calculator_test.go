@@ -0,0 +1,72 @@
+package calculator
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateUsesMifflinStJeor(t *testing.T) {
+	result, err := Calculate(models.BodyStats{Age: 20, Sex: "Male", HeightCm: 180, WeightKg: 75, ActivityLevel: "moderate", Objective: "maintain"})
+	require.NoError(t, err)
+
+	// 10*75 + 6.25*180 - 5*20 + 5 = 1780; * 1.55 = 2759.
+	assert.Equal(t, 1780.0, result.BMR)
+	assert.Equal(t, 2759.0, result.TDEE)
+	assert.Equal(t, 2760.0, result.Goals.Calories)
+	assert.Equal(t, 120.0, result.Goals.Protein)
+	assert.Equal(t, 77.0, result.Goals.Fat)
+	assert.Equal(t, 397.0, result.Goals.Carbs)
+	assert.Equal(t, &models.GoalRange{Min: 2620, Max: 2900}, result.Goals.CaloriesRange)
+	assert.Equal(t, "male", result.Stats.Sex)
+	assert.NoError(t, result.Goals.Validate())
+}
+
+func TestCalculateAdjustsForSexAndObjective(t *testing.T) {
+	stats := models.BodyStats{Age: 30, Sex: "female", HeightCm: 165, WeightKg: 60, ActivityLevel: "Very Active"}
+
+	maintain, err := Calculate(stats)
+	require.NoError(t, err)
+	assert.Equal(t, "maintain", maintain.Stats.Objective, "the objective defaults to maintain")
+	assert.Equal(t, "veryActive", maintain.Stats.ActivityLevel)
+	assert.Equal(t, 1320.0, maintain.BMR)
+
+	stats.Objective = "cut"
+	cut, err := Calculate(stats)
+	require.NoError(t, err)
+	stats.Objective = "BULK"
+	bulk, err := Calculate(stats)
+	require.NoError(t, err)
+
+	assert.Less(t, cut.Goals.Calories, maintain.Goals.Calories)
+	assert.Greater(t, bulk.Goals.Calories, maintain.Goals.Calories)
+	assert.Greater(t, cut.Goals.Protein, maintain.Goals.Protein, "a cut keeps protein high")
+
+	stats.Sex = ""
+	unspecified, err := Calculate(stats)
+	require.NoError(t, err)
+	assert.Equal(t, 1403.0, unspecified.BMR, "an unspecified sex uses the midpoint constant")
+}
+
+func TestCalculateRejectsOutOfRangeStats(t *testing.T) {
+	valid := models.BodyStats{Age: 25, HeightCm: 170, WeightKg: 70, ActivityLevel: "light"}
+	cases := map[string]func(*models.BodyStats){
+		"too young":        func(s *models.BodyStats) { s.Age = 8 },
+		"height in inches": func(s *models.BodyStats) { s.HeightCm = 68 },
+		"weight in pounds": func(s *models.BodyStats) { s.WeightKg = 400 },
+		"unknown activity": func(s *models.BodyStats) { s.ActivityLevel = "extreme" },
+		"missing activity": func(s *models.BodyStats) { s.ActivityLevel = "" },
+		"unknown goal":     func(s *models.BodyStats) { s.Objective = "recomp" },
+	}
+	for name, change := range cases {
+		stats := valid
+		change(&stats)
+		_, err := Calculate(stats)
+		assert.Error(t, err, name)
+	}
+
+	_, err := Calculate(valid)
+	assert.NoError(t, err)
+}
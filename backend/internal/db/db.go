@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -35,9 +36,11 @@ type GormAllDataItem struct {
 type GormUserPreferences struct {
 	gorm.Model
 	UserID             string `gorm:"unique"` // Unique identifier for the user.
-	Favorites          string // JSON-encoded array of item names marked as favorites.
+	Favorites          string // JSON-encoded array of favorite item names and their catalog IDs.
 	Mailing            bool   // Bool value to know if the user wants their available favorites in a daily email.
+	WeeklyReport       bool   // Bool value to know if the user wants a weekly food log report by email.
 	DisplayPreferences string // JSON-encoded display settings (locations currently).
+	DietaryProfile     string // JSON-encoded allergens to avoid and diets to require.
 }
 
 // GormLocationOperatingTimes represents the operating times for a location.
@@ -58,7 +61,9 @@ type GormDeviceToken struct {
 	Platform string // Client platform (e.g. "ios", "web").
 }
 
-// GormNutritionGoals represents user-defined nutrition goals
+// GormNutritionGoals represents user-defined nutrition goals. The optional
+// columns are nullable so rows saved before they existed read back as "not
+// set".
 type GormNutritionGoals struct {
 	gorm.Model
 	UserID   string `gorm:"unique"` // Unique identifier for the user
@@ -66,17 +71,124 @@ type GormNutritionGoals struct {
 	Protein  float64
 	Carbs    float64
 	Fat      float64
+	// Optional ranges; a range is stored only with both ends.
+	CaloriesMin *float64
+	CaloriesMax *float64
+	ProteinMin  *float64
+	ProteinMax  *float64
+	CarbsMin    *float64
+	CarbsMax    *float64
+	FatMin      *float64
+	FatMax      *float64
+	// Optional per-meal split, in percent; stored only with all three.
+	BreakfastPercent *float64
+	LunchPercent     *float64
+	DinnerPercent    *float64
+	// Optional daily limits.
+	SodiumMax *float64 // mg
+	SugarMax  *float64 // g
+	FiberMin  *float64 // g
+}
+
+// GormMenuChange records that one menu slice (date, location, time of day)
+// was replaced, cleared or pruned. The auto-incrementing ID doubles as the
+// menu change version that delta-syncing clients pass back as ?since=; every
+// writer holds lockMenuChangeLog, so IDs become visible in order.
+type GormMenuChange struct {
+	gorm.Model
+	Date      string `gorm:"index"`
+	Location  string
+	TimeOfDay string
+	Cleared   bool // true when the slice was left with no items
+}
+
+// GormFoodLogEntry is one serving in a user's food log. The logged menu item
+// is snapshotted as JSON because menu rows are replaced on every scrape and
+// pruned after MenuRetentionDays, while the log is kept until the user
+// deletes it.
+type GormFoodLogEntry struct {
+	gorm.Model
+	UserID   string           `gorm:"index:idx_food_log_user_date"`
+	Date     string           `gorm:"index:idx_food_log_user_date"` // YYYY-MM-DD the food was eaten
+	Servings float64          // multiplier applied to the item's nutrition
+	Item     models.DailyItem `gorm:"serializer:json"`
+}
+
+// GormBodyStats holds the goal calculator inputs a user chose to remember.
+// Rows exist only for users who opted in.
+type GormBodyStats struct {
+	gorm.Model
+	UserID        string `gorm:"unique"`
+	Age           int
+	Sex           string
+	HeightCm      float64
+	WeightKg      float64
+	ActivityLevel string
+	Objective     string
+}
+
+// GormSavedPlate is a named combination of menu items a user saved, matched
+// against the menu by item name through the catalog.
+type GormSavedPlate struct {
+	gorm.Model
+	UserID string `gorm:"index"`
+	Name   string
+	Items  []models.PlateComponent `gorm:"serializer:json"`
+}
+
+// GormSubscriptionRule is a standing menu search a user follows like a
+// favorite. Query is in the search package's query language.
+type GormSubscriptionRule struct {
+	gorm.Model
+	UserID string `gorm:"index"`
+	Name   string
+	Query  string
+}
+
+// GormCatalogItem is one canonical menu item. Its spellings live in
+// GormCatalogAlias; menu rows and favorites refer to it by ID.
+type GormCatalogItem struct {
+	gorm.Model
+	Name        string   // the first spelling seen
+	UpstreamIDs []string `gorm:"serializer:json"` // upstream Item.IDs seen with it, newest last
+}
+
+// GormCatalogAlias maps one normalized item name (models.CatalogKey) to the
+// catalog item it names.
+type GormCatalogAlias struct {
+	gorm.Model
+	Key       string `gorm:"uniqueIndex"`
+	Name      string // the spelling first seen with this key
+	CatalogID uint   `gorm:"index"`
+}
+
+// GormFilterMapping is one admin-editable row of the filter taxonomy.
+type GormFilterMapping struct {
+	gorm.Model
+	Name     string `gorm:"uniqueIndex"`
+	Key      string
+	Category string
 }
 
 // Package-level errors for database operations.
 var (
-	NoItemsInDB           = errors.New("no menu items found")
-	NoUserPreferencesInDB = errors.New("no user preferences found")
-	NoUserGoalsInDB       = errors.New("no user nutrition goals found")
+	NoItemsInDB            = errors.New("no menu items found")
+	NoUserPreferencesInDB  = errors.New("no user preferences found")
+	NoUserGoalsInDB        = errors.New("no user nutrition goals found")
+	NoFoodLogEntryInDB     = errors.New("no food log entry found")
+	NoBodyStatsInDB        = errors.New("no body stats found")
+	NoSavedPlateInDB       = errors.New("no saved plate found")
+	NoSubscriptionRuleInDB = errors.New("no subscription rule found")
+	NoFilterMappingInDB    = errors.New("no filter mapping found")
+	NoCatalogItemInDB      = errors.New("no catalog item found")
 )
 
 const MenuRetentionDays = 30
 
+// MenuChangeRetentionDays is how long the menu change log is kept. Clients
+// whose version has been pruned are told to reset and download the full menu.
+const MenuChangeRetentionDays = 7
+
 // AllDataItemToGorm converts an AllDataItem model to a GormAllDataItem.
 func AllDataItemToGorm(item models.AllDataItem) GormAllDataItem {
 	return GormAllDataItem{AllDataItem: item}
@@ -120,6 +232,14 @@ func Migrate(database *gorm.DB) error {
 		&GormWeeklyItem{},
 		&GormNutritionGoals{},
 		&GormDeviceToken{},
+		&GormMenuChange{},
+		&GormFoodLogEntry{},
+		&GormBodyStats{},
+		&GormSavedPlate{},
+		&GormSubscriptionRule{},
+		&GormFilterMapping{},
+		&GormCatalogItem{},
+		&GormCatalogAlias{},
 	); err != nil {
 		return err
 	}
@@ -139,15 +259,42 @@ func Migrate(database *gorm.DB) error {
 		`).Error; err != nil {
 			return fmt.Errorf("create all-data name index: %w", err)
 		}
+		if err := seedFilterMappings(tx); err != nil {
+			return fmt.Errorf("seed filter mappings: %w", err)
+		}
+		if err := backfillCatalog(tx); err != nil {
+			return fmt.Errorf("backfill item catalog: %w", err)
+		}
 		return nil
 	})
 }
 
+// seedFilterMappings fills an empty filter taxonomy table with the defaults.
+func seedFilterMappings(tx *gorm.DB) error {
+	var count int64
+	if err := tx.Model(&GormFilterMapping{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var rows []GormFilterMapping
+	for _, mapping := range models.DefaultFilterMappings() {
+		rows = append(rows, GormFilterMapping{Name: mapping.Name, Key: mapping.Key, Category: mapping.Category})
+	}
+	return tx.Create(&rows).Error
+}
+
 func insertWeeklyItems(tx *gorm.DB, items []models.WeeklyItem) error {
 	if len(items) == 0 {
 		log.Println("No weekly items, skipping insert")
 		return nil
 	}
+	if err := linkCatalog(tx, items); err != nil {
+		return fmt.Errorf("link items to catalog: %w", err)
+	}
+
 	var gormItems []GormWeeklyItem
 
 	for _, item := range items {
@@ -191,16 +338,48 @@ func PersistScrapedMenu(items []models.WeeklyItem, allDataItems []models.AllData
 	}
 
 	cutoff := now.AddDate(0, 0, -MenuRetentionDays).Format("2006-01-02")
+	changeCutoff := now.AddDate(0, 0, -MenuChangeRetentionDays)
 	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockMenuChangeLog(tx); err != nil {
+			return err
+		}
+
+		// Every slice stored on a scraped date is replaced, whether or not the
+		// new scrape still serves it, so collect them before deleting.
+		var previous []MenuPeriod
+		if err := tx.Model(&GormWeeklyItem{}).
+			Distinct("date", "location", "time_of_day").
+			Where("date IN ?", dates).
+			Scan(&previous).Error; err != nil {
+			return fmt.Errorf("list replaced menu periods: %w", err)
+		}
 		if err := tx.Unscoped().Where("date IN ?", dates).Delete(&GormWeeklyItem{}).Error; err != nil {
 			return fmt.Errorf("replace scraped menu dates: %w", err)
 		}
 		if err := insertWeeklyItems(tx, cleanItems); err != nil {
 			return fmt.Errorf("insert scraped menu items: %w", err)
 		}
+		if err := recordMenuChanges(tx, previous, cleanItems); err != nil {
+			return err
+		}
+		// Pruned slices are logged as cleared so delta-syncing clients drop
+		// them too.
+		var pruned []MenuPeriod
+		if err := tx.Model(&GormWeeklyItem{}).
+			Distinct("date", "location", "time_of_day").
+			Where("date < ?", cutoff).
+			Scan(&pruned).Error; err != nil {
+			return fmt.Errorf("list pruned menu periods: %w", err)
+		}
 		if err := tx.Unscoped().Where("date < ?", cutoff).Delete(&GormWeeklyItem{}).Error; err != nil {
 			return fmt.Errorf("prune menu history before %s: %w", cutoff, err)
 		}
+		if err := recordMenuChanges(tx, pruned, nil); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("created_at < ?", changeCutoff).Delete(&GormMenuChange{}).Error; err != nil {
+			return fmt.Errorf("prune menu change log: %w", err)
+		}
 
 		uniqueAllData := uniqueAllDataItems(allDataItems)
 		if len(uniqueAllData) == 0 {
@@ -285,6 +464,10 @@ func ReplaceMenuPeriods(periods []MenuPeriod, items []models.WeeklyItem, allData
 	}
 
 	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockMenuChangeLog(tx); err != nil {
+			return err
+		}
+
 		for _, period := range cleanPeriods {
 			err := tx.Unscoped().
 				Where("date = ? AND location = ? AND time_of_day = ?", period.Date, period.Location, period.TimeOfDay).
@@ -297,6 +480,9 @@ func ReplaceMenuPeriods(periods []MenuPeriod, items []models.WeeklyItem, allData
 		if err := insertWeeklyItems(tx, cleanItems); err != nil {
 			return fmt.Errorf("insert refreshed menu items: %w", err)
 		}
+		if err := recordMenuChanges(tx, cleanPeriods, cleanItems); err != nil {
+			return err
+		}
 
 		uniqueAllData := uniqueAllDataItems(allDataItems)
 		if len(uniqueAllData) == 0 {
@@ -313,6 +499,75 @@ func ReplaceMenuPeriods(periods []MenuPeriod, items []models.WeeklyItem, allData
 	})
 }
 
+// lockMenuChangeLog serializes menu writers for the rest of tx. A change's
+// version is its autoincrement ID, and Postgres hands out IDs at insert time
+// rather than commit time, so without the lock a reader could see a later
+// version commit first and skip the earlier one for good. The lock still
+// admits readers. SQLite already allows only one writer at a time.
+func lockMenuChangeLog(tx *gorm.DB) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+	if err := tx.Exec("LOCK TABLE gorm_menu_changes IN EXCLUSIVE MODE").Error; err != nil {
+		return fmt.Errorf("lock menu change log: %w", err)
+	}
+	return nil
+}
+
+// recordMenuChanges appends one change log row per replaced slice: every
+// period in replaced plus every slice the new items fill. A slice with no new
+// items is recorded as cleared. It runs inside the caller's transaction so the
+// log can never disagree with the menu rows.
+func recordMenuChanges(tx *gorm.DB, replaced []MenuPeriod, items []models.WeeklyItem) error {
+	filled := make(map[string]struct{}, len(items))
+	periods := append([]MenuPeriod(nil), replaced...)
+	for _, item := range items {
+		period := MenuPeriod{
+			Date:      item.DailyItem.Date,
+			Location:  item.DailyItem.Location,
+			TimeOfDay: item.DailyItem.TimeOfDay,
+		}
+		if _, exists := filled[period.key()]; !exists {
+			filled[period.key()] = struct{}{}
+			periods = append(periods, period)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(periods))
+	changes := make([]GormMenuChange, 0, len(periods))
+	for _, period := range periods {
+		if _, exists := seen[period.key()]; exists {
+			continue
+		}
+		seen[period.key()] = struct{}{}
+		_, hasItems := filled[period.key()]
+		changes = append(changes, GormMenuChange{
+			Date:      period.Date,
+			Location:  period.Location,
+			TimeOfDay: period.TimeOfDay,
+			Cleared:   !hasItems,
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		return a.TimeOfDay < b.TimeOfDay
+	})
+	if err := tx.CreateInBatches(&changes, 500).Error; err != nil {
+		return fmt.Errorf("record menu changes: %w", err)
+	}
+	return nil
+}
+
 func normalizeMenuPeriods(periods []MenuPeriod) ([]MenuPeriod, error) {
 	seen := make(map[string]struct{}, len(periods))
 	result := make([]MenuPeriod, 0, len(periods))
@@ -509,6 +764,12 @@ func ReplaceLocationOperatingTimes(locations []models.LocationOperatingTimes) er
 // Returns:
 // - error: An error if the save operation fails.
 func SaveUserPreferences(userID string, favorites []models.AllDataItem) error {
+	// Link each favorite to its catalog item so later renames keep matching
+	favorites, err := withCatalogIDs(DB, favorites)
+	if err != nil {
+		return fmt.Errorf("error resolving favorites: %v", err)
+	}
+
 	// Convert the maps to JSON
 	favoritesJSON, err := json.Marshal(favorites)
 	if err != nil {
@@ -632,6 +893,188 @@ func GetAllWeeklyItems() (map[string][]models.DailyItem, error) {
 	return weeklyItemsMap, nil
 }
 
+// QueryMenuItems returns the stored menu items matching filter, in the same
+// order GetAllWeeklyItems uses. It backs the scoped menu endpoint when the
+// in-memory store has not been filled yet. No matches is not an error.
+func QueryMenuItems(filter models.MenuFilter) ([]models.DailyItem, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	query := DB.Model(&GormWeeklyItem{})
+	if filter.From != "" {
+		query = query.Where("date >= ?", filter.From)
+	}
+	if filter.To != "" {
+		query = query.Where("date <= ?", filter.To)
+	}
+	if filter.Location != "" {
+		query = query.Where("LOWER(location) = LOWER(?)", filter.Location)
+	}
+	if filter.Meal != "" {
+		query = query.Where("LOWER(time_of_day) = LOWER(?)", filter.Meal)
+	}
+	if filter.Station != "" {
+		query = query.Where("LOWER(station_name) = LOWER(?)", filter.Station)
+	}
+
+	var rows []GormWeeklyItem
+	if err := query.Order("date ASC, location ASC, time_of_day ASC, station_name ASC, name ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]models.DailyItem, 0, len(rows))
+	for _, row := range rows {
+		item := row.DailyItem
+		if len(filter.Ingredients) > 0 && !filter.Matches(item) {
+			continue
+		}
+		if item.Filters == nil {
+			item.Filters = []string{}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetItemHistory returns every stored menu row for the named item dated on or
+// after since, newest first. Names match case-insensitively after trimming, and
+// NoItemsInDB is returned when the item has no retained appearances.
+func GetItemHistory(name, since string) ([]models.DailyItem, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	var rows []GormWeeklyItem
+	result := DB.Where("LOWER(name) = LOWER(?) AND date >= ?", strings.TrimSpace(name), since).
+		Order("date DESC, id DESC").
+		Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(rows) == 0 {
+		return nil, NoItemsInDB
+	}
+
+	items := make([]models.DailyItem, 0, len(rows))
+	for _, row := range rows {
+		item := row.DailyItem
+		if item.Filters == nil {
+			item.Filters = []string{}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetMenuChangesSince returns the menu slices replaced or cleared after the
+// given change version, each with its current items, plus the latest version.
+// A slice changed several times is reported once. Reset is set, with no
+// periods, when since is no longer in the retained change log (or is 0): the
+// client must then download the full menu again.
+func GetMenuChangesSince(since uint) (models.MenuChanges, error) {
+	if DB == nil {
+		return models.MenuChanges{}, errors.New("database is not initialized")
+	}
+
+	var changes models.MenuChanges
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		changes, err = readMenuChangeLog(tx, since)
+		if err != nil {
+			return err
+		}
+
+		// Report each slice as it stands now rather than replaying the log, so
+		// a slice replaced and then cleared comes back empty.
+		for index, change := range changes.Periods {
+			var items []GormWeeklyItem
+			if err := tx.Where("date = ? AND location = ? AND time_of_day = ?", change.Date, change.Location, change.TimeOfDay).
+				Order("station_name ASC, name ASC").
+				Find(&items).Error; err != nil {
+				return err
+			}
+			for _, item := range items {
+				dailyItem := item.DailyItem
+				if dailyItem.Filters == nil {
+					dailyItem.Filters = []string{}
+				}
+				changes.Periods[index].Items = append(changes.Periods[index].Items, dailyItem)
+			}
+			changes.Periods[index].Cleared = len(changes.Periods[index].Items) == 0
+		}
+		return nil
+	})
+	if err != nil {
+		return models.MenuChanges{}, err
+	}
+	return changes, nil
+}
+
+// GetMenuChangeLog is GetMenuChangesSince without the items: each period's
+// Cleared flag comes from its latest log entry and Items is always empty. It
+// suits notifications that only need to name what changed.
+func GetMenuChangeLog(since uint) (models.MenuChanges, error) {
+	if DB == nil {
+		return models.MenuChanges{}, errors.New("database is not initialized")
+	}
+	return readMenuChangeLog(DB, since)
+}
+
+// readMenuChangeLog collapses the change log after since into one entry per
+// slice, in the order slices were first touched.
+func readMenuChangeLog(tx *gorm.DB, since uint) (models.MenuChanges, error) {
+	changes := models.MenuChanges{Since: since, Periods: []models.MenuPeriodChange{}}
+
+	var latest GormMenuChange
+	if err := tx.Unscoped().Order("id DESC").Limit(1).Find(&latest).Error; err != nil {
+		return changes, err
+	}
+	changes.Version = latest.ID
+	if since == 0 {
+		changes.Reset = true
+		return changes, nil
+	}
+	if since == latest.ID {
+		return changes, nil
+	}
+
+	// Pruning removes the oldest rows first, so if since itself survives,
+	// every later change does too.
+	var known int64
+	if err := tx.Unscoped().Model(&GormMenuChange{}).Where("id = ?", since).Count(&known).Error; err != nil {
+		return changes, err
+	}
+	if known == 0 {
+		changes.Reset = true
+		return changes, nil
+	}
+
+	var rows []GormMenuChange
+	if err := tx.Unscoped().Where("id > ? AND id <= ?", since, latest.ID).Order("id ASC").Find(&rows).Error; err != nil {
+		return changes, err
+	}
+
+	position := make(map[MenuPeriod]int, len(rows))
+	for _, row := range rows {
+		period := MenuPeriod{Date: row.Date, Location: row.Location, TimeOfDay: row.TimeOfDay}
+		change := models.MenuPeriodChange{
+			Date:      row.Date,
+			Location:  row.Location,
+			TimeOfDay: row.TimeOfDay,
+			Cleared:   row.Cleared,
+			Items:     []models.DailyItem{},
+		}
+		if index, exists := position[period]; exists {
+			changes.Periods[index] = change
+			continue
+		}
+		position[period] = len(changes.Periods)
+		changes.Periods = append(changes.Periods, change)
+	}
+	return changes, nil
+}
+
 // GetAllDataItems retrieves all records from the all data table.
 //
 // Returns:
@@ -730,6 +1173,83 @@ func GetUserPreferences(userID string) ([]models.AllDataItem, error) {
 	return favorites, nil
 }
 
+// UpdateWeeklyReportStatus opts a user in or out of the weekly food log report
+// email, creating their preferences row if they have none yet. Like
+// UpdateMailingStatus it is idempotent.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - weeklyReport: Whether the user wants the weekly report.
+//
+// Returns:
+// - error: An error if the operation fails.
+func UpdateWeeklyReportStatus(userID string, weeklyReport bool) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+
+	var userPreferences GormUserPreferences
+	err := DB.Where("user_id = ?", userID).First(&userPreferences).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		userPreferences = GormUserPreferences{
+			UserID:       userID,
+			Favorites:    "[]",
+			WeeklyReport: weeklyReport,
+		}
+		return DB.Create(&userPreferences).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	return DB.Model(&GormUserPreferences{}).Where("user_id = ?", userID).Update("weekly_report", weeklyReport).Error
+}
+
+// GetWeeklyReportList returns the IDs of users opted in to the weekly food log
+// report email.
+//
+// Returns:
+// - []string: The opted-in user IDs.
+// - error: An error if the operation fails.
+func GetWeeklyReportList() ([]string, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	var userIDs []string
+	if err := DB.Model(&GormUserPreferences{}).Where("weekly_report = ?", true).Order("user_id").Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// GetUserWeeklyReport returns whether the user is opted in to the weekly food
+// log report email.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+//
+// Returns:
+// - *bool: The user's weekly report opt-in.
+// - error: NoUserPreferencesInDB if the user has no preferences row, or an
+// error if the operation fails.
+func GetUserWeeklyReport(userID string) (*bool, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	var userPreferences GormUserPreferences
+	err := DB.Where("user_id = ?", userID).First(&userPreferences).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, NoUserPreferencesInDB
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &userPreferences.WeeklyReport, nil
+}
+
 func GetUserMailing(userID string) (*bool, error) {
 	var userPreferences GormUserPreferences
 
@@ -769,66 +1289,217 @@ func GetDisplayPreferences(userID string) (models.DisplayPreferences, bool, erro
 	return displayPreferences, true, nil
 }
 
-func GetAvailableFavoritesBatch(userID string) ([]models.DailyItem, error) {
-	userPreferences, err := GetUserPreferences(userID)
+// SaveDietaryProfile stores the user's dietary profile, creating their
+// preferences row if they have none yet.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - profile: The normalized profile to store.
+//
+// Returns:
+// - error: An error if the operation fails.
+func SaveDietaryProfile(userID string, profile models.DietaryProfile) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
 
+	profileJSON, err := json.Marshal(profile)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("error serializing dietary profile: %v", err)
 	}
-	var search []string
 
-	for _, pref := range userPreferences {
-		search = append(search, pref.Name)
+	var userPreferences GormUserPreferences
+	err = DB.Where("user_id = ?", userID).First(&userPreferences).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		userPreferences = GormUserPreferences{
+			UserID:         userID,
+			Favorites:      "[]",
+			DietaryProfile: string(profileJSON),
+		}
+		return DB.Create(&userPreferences).Error
 	}
-	if len(search) == 0 {
-		return []models.DailyItem{}, nil
+	if err != nil {
+		return err
 	}
 
-	var matchingItems []models.DailyItem
-	result := DB.Table("gorm_weekly_items").
-		Where("name IN ? AND date = ?", search, time.Now().Format("2006-01-02")).
-		Find(&matchingItems)
+	return DB.Model(&GormUserPreferences{}).Where("user_id = ?", userID).Update("dietary_profile", string(profileJSON)).Error
+}
 
-	if result.Error != nil {
-		fmt.Println("Error finding favorite items batch search:", result.Error)
-		return []models.DailyItem{}, result.Error
+// GetDietaryProfile retrieves the user's dietary profile. A user who never
+// saved one gets an empty profile, which restricts nothing.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+//
+// Returns:
+// - models.DietaryProfile: The stored profile.
+// - error: An error if the operation fails.
+func GetDietaryProfile(userID string) (models.DietaryProfile, error) {
+	empty := models.DietaryProfile{Avoid: []string{}, Require: []string{}, AvoidIngredients: []string{}}
+	if DB == nil {
+		return empty, errors.New("database is not initialized")
 	}
 
-	return matchingItems, nil
-}
-
-// GetAvailableFavoritesForMeal returns the user's favorite items that appear on
-// the given date for a specific meal period (e.g. "Breakfast"). It mirrors
-// GetAvailableFavoritesBatch but scopes results to a single date and TimeOfDay,
-// which the notification cron needs to describe the upcoming meal only.
-func GetAvailableFavoritesForMeal(userID, date, timeOfDay string) ([]models.DailyItem, error) {
-	userPreferences, err := GetUserPreferences(userID)
+	var userPreferences GormUserPreferences
+	err := DB.Where("user_id = ?", userID).First(&userPreferences).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return empty, nil
+	}
 	if err != nil {
-		return nil, err
+		return empty, err
+	}
+	if strings.TrimSpace(userPreferences.DietaryProfile) == "" {
+		return empty, nil
 	}
 
-	var search []string
-	for _, pref := range userPreferences {
-		search = append(search, pref.Name)
+	var profile models.DietaryProfile
+	if err := json.Unmarshal([]byte(userPreferences.DietaryProfile), &profile); err != nil {
+		return empty, fmt.Errorf("error deserializing dietary profile: %v", err)
+	}
+	if profile.Avoid == nil {
+		profile.Avoid = []string{}
 	}
-	if len(search) == 0 {
-		return []models.DailyItem{}, nil
+	if profile.Require == nil {
+		profile.Require = []string{}
 	}
+	if profile.AvoidIngredients == nil {
+		profile.AvoidIngredients = []string{}
+	}
+	return profile, nil
+}
 
-	var matchingItems []models.DailyItem
-	result := DB.Table("gorm_weekly_items").
-		Where("name IN ? AND date = ? AND time_of_day = ?", search, date, timeOfDay).
-		Find(&matchingItems)
+// RuleMatcher matches a user's subscription rules against a menu a send pass
+// loaded once; subscription.Menu implements it. Keeping the rule language
+// behind this interface leaves db free of the search package.
+type RuleMatcher interface {
+	Matches(userID string) ([]models.DailyItem, error)
+}
 
-	if result.Error != nil {
-		fmt.Println("Error finding favorite items for meal:", result.Error)
-		return []models.DailyItem{}, result.Error
+// GetAvailableFavoritesBatch returns today's menu items that are one of the
+// user's favorites or match one of their subscription rules, leaving out any
+// that conflict with their dietary profile. rules must hold today's menu; a
+// nil rules reports favorites only. The favorites email lists these.
+func GetAvailableFavoritesBatch(userID string, rules RuleMatcher) ([]models.DailyItem, error) {
+	matchingItems, err := availableForUser(userID, rules, "date = ?", time.Now().Format("2006-01-02"))
+	if err != nil {
+		fmt.Println("Error finding favorite items batch search:", err)
+		return []models.DailyItem{}, err
 	}
 
-	return matchingItems, nil
+	return safeForUser(userID, matchingItems)
 }
 
-// SaveDeviceToken upserts an FCM registration token for the user. Because a
+// GetAvailableFavoritesForMeal returns the user's favorite items, and the
+// items matching their subscription rules, that appear on the given date for
+// a specific meal period (e.g. "Breakfast"). rules must hold that meal's menu;
+// a nil rules reports favorites only. It mirrors GetAvailableFavoritesBatch
+// but scopes results to a single date and TimeOfDay, which the notification
+// cron needs to describe the upcoming meal only. Unlike the batch it leaves
+// the dietary profile to the caller: the cron loads it once per user and hands
+// it to push.BuildNotificationForProfile, which drops conflicting items.
+func GetAvailableFavoritesForMeal(userID, date, timeOfDay string, rules RuleMatcher) ([]models.DailyItem, error) {
+	matchingItems, err := availableForUser(userID, rules, "date = ? AND time_of_day = ?", date, timeOfDay)
+	if err != nil {
+		fmt.Println("Error finding favorite items for meal:", err)
+		return []models.DailyItem{}, err
+	}
+
+	return matchingItems, nil
+}
+
+// availableForUser returns the stored menu rows satisfying condition that are
+// one of the user's favorites, followed by the items rules matches for them.
+// A row matched both ways is listed once. Users without a preferences row may
+// still follow rules, so a missing row counts as no favorites.
+func availableForUser(userID string, rules RuleMatcher, condition string, args ...any) ([]models.DailyItem, error) {
+	favorites, err := GetUserPreferences(userID)
+	if err != nil && !errors.Is(err, NoUserPreferencesInDB) {
+		return nil, err
+	}
+
+	matchingItems := []models.DailyItem{}
+	if len(favorites) > 0 {
+		matchingItems, err = favoriteMenuItems(favorites, condition, args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rules == nil {
+		return matchingItems, nil
+	}
+
+	ruleItems, err := rules.Matches(userID)
+	if err != nil {
+		return nil, err
+	}
+	return appendNewMenuItems(matchingItems, ruleItems), nil
+}
+
+// appendNewMenuItems appends the items of more that are not already in items,
+// comparing the date, location, meal period, station and name of each row.
+func appendNewMenuItems(items, more []models.DailyItem) []models.DailyItem {
+	key := func(item models.DailyItem) string {
+		return strings.Join([]string{item.Date, item.Location, item.TimeOfDay, item.StationName, item.Name}, "\x00")
+	}
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		seen[key(item)] = true
+	}
+	for _, item := range more {
+		if seen[key(item)] {
+			continue
+		}
+		seen[key(item)] = true
+		items = append(items, item)
+	}
+	return items
+}
+
+// safeForUser drops the items that conflict with the user's dietary profile,
+// so a favorite is never announced once the user has learned to avoid it.
+func safeForUser(userID string, items []models.DailyItem) ([]models.DailyItem, error) {
+	profile, err := GetDietaryProfile(userID)
+	if err != nil {
+		return []models.DailyItem{}, err
+	}
+	if profile.Empty() {
+		return items, nil
+	}
+
+	safe := []models.DailyItem{}
+	for _, item := range items {
+		if profile.Allows(item) {
+			safe = append(safe, item)
+		}
+	}
+	return safe, nil
+}
+
+// favoriteMenuItems returns the stored menu rows that satisfy condition and
+// are one of favorites: by catalog identity, so any spelling of the item
+// matches, or by exact name for favorites not linked to the catalog yet. The
+// saved plate lookups match components the same way.
+func favoriteMenuItems(favorites []models.AllDataItem, condition string, args ...any) ([]models.DailyItem, error) {
+	var catalogIDs []uint
+	var names []string
+	for _, favorite := range favorites {
+		if favorite.CatalogID != 0 {
+			catalogIDs = append(catalogIDs, favorite.CatalogID)
+		} else {
+			names = append(names, favorite.Name)
+		}
+	}
+
+	var matchingItems []models.DailyItem
+	result := DB.Table("gorm_weekly_items").
+		Where("catalog_id IN ? OR name IN ?", catalogIDs, names).
+		Where(condition, args...).
+		Find(&matchingItems)
+	return matchingItems, result.Error
+}
+
+// SaveDeviceToken upserts an FCM registration token for the user. Because a
 // token is unique per device, re-registering an existing token reassigns it to
 // the current user and refreshes UpdatedAt rather than creating a duplicate.
 func SaveDeviceToken(userID, token, platform string) error {
@@ -898,7 +1569,11 @@ func GetAllDeviceTokens() (map[string][]string, error) {
 	return tokensByUser, nil
 }
 
-func GetMailingList() ([]models.PreferenceReturn, error) {
+// GetMailingList returns every user who opted into the favorites email with
+// today's menu items for them: their available favorites and the items their
+// subscription rules match, as GetAvailableFavoritesBatch reports. rules must
+// hold today's menu, loaded once for the whole list.
+func GetMailingList(rules RuleMatcher) ([]models.PreferenceReturn, error) {
 	rows, err := DB.Raw("SELECT user_id FROM gorm_user_preferences WHERE mailing = true").Rows()
 	if err != nil {
 		fmt.Println("Error executing query:", err)
@@ -916,7 +1591,7 @@ func GetMailingList() ([]models.PreferenceReturn, error) {
 
 		userID := strings.TrimSpace(item.UserID)
 
-		availFavorites, err := GetAvailableFavoritesBatch(userID)
+		availFavorites, err := GetAvailableFavoritesBatch(userID, rules)
 
 		if err != nil {
 			fmt.Printf("Error getting favorites for user %s with err %v:\n", item.UserID, err)
@@ -951,10 +1626,7 @@ func SaveNutritionGoals(userID string, goals models.NutritionGoals) error {
 
 	// If goals already exist, update them
 	if result.RowsAffected > 0 {
-		existingGoals.Calories = goals.Calories
-		existingGoals.Protein = goals.Protein
-		existingGoals.Carbs = goals.Carbs
-		existingGoals.Fat = goals.Fat
+		existingGoals.setGoals(goals)
 
 		result = DB.Save(&existingGoals)
 		if result.Error != nil {
@@ -965,13 +1637,8 @@ func SaveNutritionGoals(userID string, goals models.NutritionGoals) error {
 	}
 
 	// Otherwise, create new goals
-	newGoals := GormNutritionGoals{
-		UserID:   userID,
-		Calories: goals.Calories,
-		Protein:  goals.Protein,
-		Carbs:    goals.Carbs,
-		Fat:      goals.Fat,
-	}
+	newGoals := GormNutritionGoals{UserID: userID}
+	newGoals.setGoals(goals)
 
 	result = DB.Create(&newGoals)
 	if result.Error != nil {
@@ -981,10 +1648,76 @@ func SaveNutritionGoals(userID string, goals models.NutritionGoals) error {
 	return nil
 }
 
+// setGoals copies goals into the row, clearing optional columns the goals
+// leave unset.
+func (row *GormNutritionGoals) setGoals(goals models.NutritionGoals) {
+	row.Calories = goals.Calories
+	row.Protein = goals.Protein
+	row.Carbs = goals.Carbs
+	row.Fat = goals.Fat
+
+	row.CaloriesMin, row.CaloriesMax = rangeColumns(goals.CaloriesRange)
+	row.ProteinMin, row.ProteinMax = rangeColumns(goals.ProteinRange)
+	row.CarbsMin, row.CarbsMax = rangeColumns(goals.CarbsRange)
+	row.FatMin, row.FatMax = rangeColumns(goals.FatRange)
+
+	row.BreakfastPercent, row.LunchPercent, row.DinnerPercent = nil, nil, nil
+	if split := goals.MealSplit; split != nil {
+		row.BreakfastPercent = &split.Breakfast
+		row.LunchPercent = &split.Lunch
+		row.DinnerPercent = &split.Dinner
+	}
+
+	row.SodiumMax = goals.SodiumMax
+	row.SugarMax = goals.SugarMax
+	row.FiberMin = goals.FiberMin
+}
+
+// goals reads the row back into the model.
+func (row GormNutritionGoals) goals() models.NutritionGoals {
+	goals := models.NutritionGoals{
+		Calories:      row.Calories,
+		Protein:       row.Protein,
+		Carbs:         row.Carbs,
+		Fat:           row.Fat,
+		CaloriesRange: goalRange(row.CaloriesMin, row.CaloriesMax),
+		ProteinRange:  goalRange(row.ProteinMin, row.ProteinMax),
+		CarbsRange:    goalRange(row.CarbsMin, row.CarbsMax),
+		FatRange:      goalRange(row.FatMin, row.FatMax),
+		SodiumMax:     row.SodiumMax,
+		SugarMax:      row.SugarMax,
+		FiberMin:      row.FiberMin,
+	}
+	if row.BreakfastPercent != nil && row.LunchPercent != nil && row.DinnerPercent != nil {
+		goals.MealSplit = &models.MealSplit{
+			Breakfast: *row.BreakfastPercent,
+			Lunch:     *row.LunchPercent,
+			Dinner:    *row.DinnerPercent,
+		}
+	}
+	return goals
+}
+
+func rangeColumns(band *models.GoalRange) (*float64, *float64) {
+	if band == nil {
+		return nil, nil
+	}
+	low, high := band.Min, band.Max
+	return &low, &high
+}
+
+func goalRange(low, high *float64) *models.GoalRange {
+	if low == nil || high == nil {
+		return nil
+	}
+	return &models.GoalRange{Min: *low, Max: *high}
+}
+
 // DeleteUserData removes all rows owned by a user across the user-keyed tables
-// (GormUserPreferences and GormNutritionGoals). It runs inside a transaction so
-// the deletion is all-or-nothing. Deleting zero rows is not an error, since a
-// user may have no stored data.
+// (GormUserPreferences, GormNutritionGoals, GormDeviceToken, GormFoodLogEntry,
+// GormBodyStats, GormSavedPlate and GormSubscriptionRule). It runs inside a
+// transaction so the deletion is all-or-nothing. Deleting zero rows is not an
+// error, since a user may have no stored data.
 //
 // Parameters:
 // - userID: The unique identifier for the user whose data should be deleted.
@@ -1006,6 +1739,18 @@ func DeleteUserData(userID string) error {
 		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&GormDeviceToken{}).Error; err != nil {
 			return fmt.Errorf("delete user device tokens: %w", err)
 		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&GormFoodLogEntry{}).Error; err != nil {
+			return fmt.Errorf("delete user food log: %w", err)
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&GormBodyStats{}).Error; err != nil {
+			return fmt.Errorf("delete user body stats: %w", err)
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&GormSavedPlate{}).Error; err != nil {
+			return fmt.Errorf("delete user saved plates: %w", err)
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&GormSubscriptionRule{}).Error; err != nil {
+			return fmt.Errorf("delete user subscription rules: %w", err)
+		}
 		return nil
 	})
 }
@@ -1030,12 +1775,1038 @@ func GetNutritionGoals(userID string) (models.NutritionGoals, error) {
 		return models.NutritionGoals{}, result.Error
 	}
 
-	goals := models.NutritionGoals{
-		Calories: gormGoals.Calories,
-		Protein:  gormGoals.Protein,
-		Carbs:    gormGoals.Carbs,
-		Fat:      gormGoals.Fat,
+	return gormGoals.goals(), nil
+}
+
+// AddFoodLogEntry records a serving in the user's food log and returns the
+// stored entry with its ID and timestamp. The item snapshot keeps its
+// nutrient estimates, which the log's totals fall back on, but not the other
+// fields the menu store derives.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - entry: The entry to store; its ID and LoggedAt are ignored.
+//
+// Returns:
+// - models.FoodLogEntry: The stored entry.
+// - error: An error if the operation fails.
+func AddFoodLogEntry(userID string, entry models.FoodLogEntry) (models.FoodLogEntry, error) {
+	if DB == nil {
+		return models.FoodLogEntry{}, errors.New("database is not initialized")
+	}
+
+	item := entry.Item
+	item.Tags = nil
+	item.DietConflicts = nil
+	row := GormFoodLogEntry{
+		UserID:   userID,
+		Date:     entry.Date,
+		Servings: entry.Servings,
+		Item:     item,
+	}
+	if err := DB.Create(&row).Error; err != nil {
+		return models.FoodLogEntry{}, err
+	}
+	return row.toModel(), nil
+}
+
+// DeleteFoodLogEntry removes one of the user's food log entries and returns
+// it, so callers know which date changed. Entries owned by other users are
+// reported as NoFoodLogEntryInDB.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - id: The entry's ID.
+//
+// Returns:
+// - models.FoodLogEntry: The deleted entry.
+// - error: NoFoodLogEntryInDB if the user has no such entry, or another error if the operation fails.
+func DeleteFoodLogEntry(userID string, id uint) (models.FoodLogEntry, error) {
+	if DB == nil {
+		return models.FoodLogEntry{}, errors.New("database is not initialized")
+	}
+
+	var row GormFoodLogEntry
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&row).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NoFoodLogEntryInDB
+			}
+			return err
+		}
+		return tx.Unscoped().Delete(&row).Error
+	})
+	if err != nil {
+		return models.FoodLogEntry{}, err
+	}
+	return row.toModel(), nil
+}
+
+// GetFoodLog returns the user's food log entries for a date, oldest first.
+// A day with nothing logged yields an empty slice, not an error.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - date: The YYYY-MM-DD date to read.
+//
+// Returns:
+// - []models.FoodLogEntry: The day's entries.
+// - error: An error if the operation fails.
+func GetFoodLog(userID, date string) ([]models.FoodLogEntry, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	var rows []GormFoodLogEntry
+	if err := DB.Where("user_id = ? AND date = ?", userID, date).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.FoodLogEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, row.toModel())
+	}
+	return entries, nil
+}
+
+// GetFoodLogRange retrieves a user's food log entries dated from through to,
+// inclusive, oldest first.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - from: The first YYYY-MM-DD date to include.
+// - to: The last YYYY-MM-DD date to include.
+//
+// Returns:
+// - []models.FoodLogEntry: The entries, empty when none were logged.
+// - error: An error if the operation fails.
+func GetFoodLogRange(userID, from, to string) ([]models.FoodLogEntry, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	var rows []GormFoodLogEntry
+	if err := DB.Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).Order("date, created_at, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.FoodLogEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, row.toModel())
+	}
+	return entries, nil
+}
+
+func (row GormFoodLogEntry) toModel() models.FoodLogEntry {
+	item := row.Item
+	if item.Filters == nil {
+		item.Filters = []string{}
+	}
+	return models.FoodLogEntry{
+		ID:       row.ID,
+		Date:     row.Date,
+		Servings: row.Servings,
+		Item:     item,
+		LoggedAt: row.CreatedAt,
+	}
+}
+
+// SaveBodyStats stores the goal calculator inputs for a user, replacing any
+// saved earlier. Callers must only store stats the user opted to keep.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - stats: The normalized body stats to store.
+//
+// Returns:
+// - error: An error if the operation fails.
+func SaveBodyStats(userID string, stats models.BodyStats) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+
+	row := GormBodyStats{
+		UserID:        userID,
+		Age:           stats.Age,
+		Sex:           stats.Sex,
+		HeightCm:      stats.HeightCm,
+		WeightKg:      stats.WeightKg,
+		ActivityLevel: stats.ActivityLevel,
+		Objective:     stats.Objective,
+	}
+	return DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "age", "sex", "height_cm", "weight_kg", "activity_level", "objective"}),
+	}).Create(&row).Error
+}
+
+// GetBodyStats retrieves the goal calculator inputs a user chose to remember.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+//
+// Returns:
+// - models.BodyStats: The stored body stats.
+// - error: NoBodyStatsInDB if the user has none stored, or another error if
+// the operation fails.
+func GetBodyStats(userID string) (models.BodyStats, error) {
+	if DB == nil {
+		return models.BodyStats{}, errors.New("database is not initialized")
+	}
+
+	var row GormBodyStats
+	if err := DB.Where("user_id = ?", userID).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.BodyStats{}, NoBodyStatsInDB
+		}
+		return models.BodyStats{}, err
+	}
+
+	return models.BodyStats{
+		Age:           row.Age,
+		Sex:           row.Sex,
+		HeightCm:      row.HeightCm,
+		WeightKg:      row.WeightKg,
+		ActivityLevel: row.ActivityLevel,
+		Objective:     row.Objective,
+	}, nil
+}
+
+// DeleteBodyStats forgets the goal calculator inputs a user stored. Deleting
+// stats that were never stored is not an error.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+//
+// Returns:
+// - error: An error if the operation fails.
+func DeleteBodyStats(userID string) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+
+	return DB.Unscoped().Where("user_id = ?", userID).Delete(&GormBodyStats{}).Error
+}
+
+// CreateSavedPlate stores a new saved plate for the user and returns it with
+// its ID and timestamps.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - plate: The plate to store; its ID and timestamps are ignored.
+//
+// Returns:
+// - models.SavedPlate: The stored plate.
+// - error: An error if the operation fails.
+func CreateSavedPlate(userID string, plate models.SavedPlate) (models.SavedPlate, error) {
+	if DB == nil {
+		return models.SavedPlate{}, errors.New("database is not initialized")
+	}
+
+	row := GormSavedPlate{UserID: userID, Name: plate.Name, Items: plate.Items}
+	if err := DB.Create(&row).Error; err != nil {
+		return models.SavedPlate{}, err
+	}
+	return row.toModel(), nil
+}
+
+// UpdateSavedPlate replaces the name and items of one of the user's saved
+// plates. Plates owned by other users are reported as NoSavedPlateInDB.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - id: The plate's ID.
+// - plate: The new name and items.
+//
+// Returns:
+// - models.SavedPlate: The updated plate.
+// - error: NoSavedPlateInDB if the user has no such plate, or another error if the operation fails.
+func UpdateSavedPlate(userID string, id uint, plate models.SavedPlate) (models.SavedPlate, error) {
+	if DB == nil {
+		return models.SavedPlate{}, errors.New("database is not initialized")
+	}
+
+	var row GormSavedPlate
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&row).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NoSavedPlateInDB
+			}
+			return err
+		}
+		row.Name = plate.Name
+		row.Items = plate.Items
+		return tx.Save(&row).Error
+	})
+	if err != nil {
+		return models.SavedPlate{}, err
+	}
+	return row.toModel(), nil
+}
+
+// DeleteSavedPlate removes one of the user's saved plates. Plates owned by
+// other users are reported as NoSavedPlateInDB.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - id: The plate's ID.
+//
+// Returns:
+// - error: NoSavedPlateInDB if the user has no such plate, or another error if the operation fails.
+func DeleteSavedPlate(userID string, id uint) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+
+	result := DB.Unscoped().Where("id = ? AND user_id = ?", id, userID).Delete(&GormSavedPlate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return NoSavedPlateInDB
+	}
+	return nil
+}
+
+// GetSavedPlates returns the user's saved plates, oldest first. A user with
+// none yields an empty slice, not an error.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+//
+// Returns:
+// - []models.SavedPlate: The user's plates.
+// - error: An error if the operation fails.
+func GetSavedPlates(userID string) ([]models.SavedPlate, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	var rows []GormSavedPlate
+	if err := DB.Where("user_id = ?", userID).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	plates := make([]models.SavedPlate, 0, len(rows))
+	for _, row := range rows {
+		plates = append(plates, row.toModel())
+	}
+	return plates, nil
+}
+
+func (row GormSavedPlate) toModel() models.SavedPlate {
+	items := row.Items
+	if items == nil {
+		items = []models.PlateComponent{}
+	}
+	return models.SavedPlate{
+		ID:        row.ID,
+		Name:      row.Name,
+		Items:     items,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// GetPlateSlots returns the stored menu slots (date, meal period and hall)
+// dated on or after from that serve every one of names, using the same
+// catalog matching as the favorites lookups, so a respelled or renamed dish
+// still counts. Slots are ordered by date, location and meal period.
+//
+// Parameters:
+// - names: The item names that must all appear in a slot.
+// - from: The first YYYY-MM-DD date to consider.
+//
+// Returns:
+// - []models.PlateSlot: The matching slots, empty when there are none.
+// - error: An error if the operation fails.
+func GetPlateSlots(names []string, from string) ([]models.PlateSlot, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	components := make([]models.AllDataItem, 0, len(names))
+	for _, name := range names {
+		components = append(components, models.AllDataItem{Name: name})
+	}
+	components, err := withCatalogIDs(DB, components)
+	if err != nil {
+		return nil, err
+	}
+
+	// A component is identified by its catalog item, or by its exact name
+	// while the catalog does not know it.
+	byCatalog := make(map[uint]string)
+	byName := make(map[string]string)
+	wanted := make(map[string]bool)
+	for _, component := range components {
+		identity := "name:" + component.Name
+		if component.CatalogID != 0 {
+			identity = fmt.Sprintf("catalog:%d", component.CatalogID)
+			byCatalog[component.CatalogID] = identity
+		} else {
+			byName[component.Name] = identity
+		}
+		wanted[identity] = true
+	}
+	if len(wanted) == 0 {
+		return []models.PlateSlot{}, nil
 	}
 
-	return goals, nil
+	matchingItems, err := favoriteMenuItems(components, "date >= ?", from)
+	if err != nil {
+		return nil, err
+	}
+
+	served := make(map[models.PlateSlot]map[string]bool)
+	for _, item := range matchingItems {
+		identity, ok := byCatalog[item.CatalogID]
+		if !ok {
+			identity, ok = byName[item.Name]
+		}
+		if !ok {
+			continue
+		}
+		slot := models.PlateSlot{Date: item.Date, TimeOfDay: item.TimeOfDay, Location: item.Location}
+		if served[slot] == nil {
+			served[slot] = make(map[string]bool)
+		}
+		served[slot][identity] = true
+	}
+
+	slots := []models.PlateSlot{}
+	for slot, found := range served {
+		if len(found) == len(wanted) {
+			slots = append(slots, slot)
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Date != slots[j].Date {
+			return slots[i].Date < slots[j].Date
+		}
+		if slots[i].Location != slots[j].Location {
+			return slots[i].Location < slots[j].Location
+		}
+		return slots[i].TimeOfDay < slots[j].TimeOfDay
+	})
+	return slots, nil
+}
+
+// CreateSubscriptionRule stores a new subscription rule for the user and
+// returns it with its ID and timestamps.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - rule: The rule to store; its ID and timestamps are ignored.
+//
+// Returns:
+// - models.SubscriptionRule: The stored rule.
+// - error: An error if the operation fails.
+func CreateSubscriptionRule(userID string, rule models.SubscriptionRule) (models.SubscriptionRule, error) {
+	if DB == nil {
+		return models.SubscriptionRule{}, errors.New("database is not initialized")
+	}
+
+	row := GormSubscriptionRule{UserID: userID, Name: rule.Name, Query: rule.Query}
+	if err := DB.Create(&row).Error; err != nil {
+		return models.SubscriptionRule{}, err
+	}
+	return row.toModel(), nil
+}
+
+// UpdateSubscriptionRule replaces the name and query of one of the user's
+// subscription rules. Rules owned by other users are reported as
+// NoSubscriptionRuleInDB.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - id: The rule's ID.
+// - rule: The new name and query.
+//
+// Returns:
+// - models.SubscriptionRule: The updated rule.
+// - error: NoSubscriptionRuleInDB if the user has no such rule, or another error if the operation fails.
+func UpdateSubscriptionRule(userID string, id uint, rule models.SubscriptionRule) (models.SubscriptionRule, error) {
+	if DB == nil {
+		return models.SubscriptionRule{}, errors.New("database is not initialized")
+	}
+
+	var row GormSubscriptionRule
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&row).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NoSubscriptionRuleInDB
+			}
+			return err
+		}
+		row.Name = rule.Name
+		row.Query = rule.Query
+		return tx.Save(&row).Error
+	})
+	if err != nil {
+		return models.SubscriptionRule{}, err
+	}
+	return row.toModel(), nil
+}
+
+// DeleteSubscriptionRule removes one of the user's subscription rules. Rules
+// owned by other users are reported as NoSubscriptionRuleInDB.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+// - id: The rule's ID.
+//
+// Returns:
+// - error: NoSubscriptionRuleInDB if the user has no such rule, or another error if the operation fails.
+func DeleteSubscriptionRule(userID string, id uint) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+
+	result := DB.Unscoped().Where("id = ? AND user_id = ?", id, userID).Delete(&GormSubscriptionRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return NoSubscriptionRuleInDB
+	}
+	return nil
+}
+
+// GetSubscriptionRules returns the user's subscription rules, oldest first. A
+// user with none yields an empty slice, not an error.
+//
+// Parameters:
+// - userID: The unique identifier for the user.
+//
+// Returns:
+// - []models.SubscriptionRule: The user's rules.
+// - error: An error if the operation fails.
+func GetSubscriptionRules(userID string) ([]models.SubscriptionRule, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	var rows []GormSubscriptionRule
+	if err := DB.Where("user_id = ?", userID).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	rules := make([]models.SubscriptionRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, row.toModel())
+	}
+	return rules, nil
+}
+
+func (row GormSubscriptionRule) toModel() models.SubscriptionRule {
+	return models.SubscriptionRule{
+		ID:        row.ID,
+		Name:      row.Name,
+		Query:     row.Query,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// GetFilterMappings returns the filter taxonomy, ordered by category and name.
+//
+// Returns:
+// - []models.FilterMapping: The mappings.
+// - error: An error if the operation fails.
+func GetFilterMappings() ([]models.FilterMapping, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	var rows []GormFilterMapping
+	if err := DB.Order("category, name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	mappings := make([]models.FilterMapping, 0, len(rows))
+	for _, row := range rows {
+		mappings = append(mappings, models.FilterMapping{Name: row.Name, Key: row.Key, Category: row.Category})
+	}
+	return mappings, nil
+}
+
+// SaveFilterMapping creates the mapping for a filter name or replaces the
+// existing one. Names match case-insensitively, so an edit can also fix the
+// stored casing.
+//
+// Parameters:
+// - mapping: The validated mapping, with its key filled in.
+//
+// Returns:
+// - error: An error if the operation fails.
+func SaveFilterMapping(mapping models.FilterMapping) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var row GormFilterMapping
+		if err := tx.Where("LOWER(name) = LOWER(?)", mapping.Name).Limit(1).Find(&row).Error; err != nil {
+			return err
+		}
+		row.Name = mapping.Name
+		row.Key = mapping.Key
+		row.Category = mapping.Category
+		return tx.Save(&row).Error
+	})
+}
+
+// DeleteFilterMapping removes the mapping for a filter name, matched
+// case-insensitively. The name is reported as unmapped again the next time it
+// appears on the menu.
+//
+// Parameters:
+// - name: The base filter name, without "*".
+//
+// Returns:
+// - error: NoFilterMappingInDB if no mapping has that name, or another error if the operation fails.
+func DeleteFilterMapping(name string) error {
+	if DB == nil {
+		return errors.New("database is not initialized")
+	}
+
+	result := DB.Unscoped().Where("LOWER(name) = LOWER(?)", name).Delete(&GormFilterMapping{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return NoFilterMappingInDB
+	}
+	return nil
+}
+
+// maxCatalogUpstreamIDs bounds the upstream IDs kept per catalog item; the
+// oldest are dropped first.
+const maxCatalogUpstreamIDs = 20
+
+// catalogResolver resolves item names to catalog IDs inside one transaction,
+// creating catalog items and aliases for names never seen before. It loads
+// the whole catalog up front, which stays small: one row per distinct dish.
+type catalogResolver struct {
+	tx         *gorm.DB
+	byKey      map[string]uint
+	byUpstream map[string]uint
+	items      map[uint]*GormCatalogItem
+	changed    map[uint]bool
+}
+
+func newCatalogResolver(tx *gorm.DB) (*catalogResolver, error) {
+	var items []GormCatalogItem
+	if err := tx.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	var aliases []GormCatalogAlias
+	if err := tx.Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+
+	r := &catalogResolver{
+		tx:         tx,
+		byKey:      make(map[string]uint, len(aliases)),
+		byUpstream: make(map[string]uint),
+		items:      make(map[uint]*GormCatalogItem, len(items)),
+		changed:    make(map[uint]bool),
+	}
+	for i := range items {
+		r.items[items[i].ID] = &items[i]
+		for _, upstreamID := range items[i].UpstreamIDs {
+			r.byUpstream[upstreamID] = items[i].ID
+		}
+	}
+	for _, alias := range aliases {
+		r.byKey[alias.Key] = alias.CatalogID
+	}
+	return r, nil
+}
+
+// resolve returns the catalog ID for an item name. The normalized name is
+// matched first, then the upstream ID: a new spelling carrying a known
+// upstream ID is a rename and becomes an alias of that item. A name matching
+// neither creates a catalog item. Blank names resolve to 0.
+func (r *catalogResolver) resolve(name, upstreamID string) (uint, error) {
+	name = strings.TrimSpace(name)
+	key := models.CatalogKey(name)
+	if key == "" {
+		return 0, nil
+	}
+
+	id, known := r.byKey[key]
+	if !known && upstreamID != "" {
+		if id, known = r.byUpstream[upstreamID]; known {
+			if err := r.addAlias(key, name, id); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if !known {
+		item := GormCatalogItem{Name: name}
+		if err := r.tx.Create(&item).Error; err != nil {
+			return 0, err
+		}
+		r.items[item.ID] = &item
+		id = item.ID
+		if err := r.addAlias(key, name, id); err != nil {
+			return 0, err
+		}
+	}
+
+	r.recordUpstream(id, upstreamID)
+	return id, nil
+}
+
+func (r *catalogResolver) addAlias(key, name string, id uint) error {
+	if err := r.tx.Create(&GormCatalogAlias{Key: key, Name: name, CatalogID: id}).Error; err != nil {
+		return err
+	}
+	r.byKey[key] = id
+	return nil
+}
+
+func (r *catalogResolver) recordUpstream(id uint, upstreamID string) {
+	item := r.items[id]
+	if upstreamID == "" || item == nil {
+		return
+	}
+	for _, seen := range item.UpstreamIDs {
+		if seen == upstreamID {
+			return
+		}
+	}
+	item.UpstreamIDs = appendUpstreamIDs(item.UpstreamIDs, upstreamID)
+	r.byUpstream[upstreamID] = id
+	r.changed[id] = true
+}
+
+// save writes back the catalog items whose upstream IDs changed.
+func (r *catalogResolver) save() error {
+	for id := range r.changed {
+		if err := r.tx.Save(r.items[id]).Error; err != nil {
+			return err
+		}
+	}
+	r.changed = make(map[uint]bool)
+	return nil
+}
+
+// appendUpstreamIDs adds the IDs not already listed and keeps the newest
+// maxCatalogUpstreamIDs.
+func appendUpstreamIDs(list []string, ids ...string) []string {
+	for _, id := range ids {
+		duplicate := false
+		for _, seen := range list {
+			if seen == id {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			list = append(list, id)
+		}
+	}
+	if len(list) > maxCatalogUpstreamIDs {
+		list = list[len(list)-maxCatalogUpstreamIDs:]
+	}
+	return list
+}
+
+// linkCatalog sets CatalogID on every item about to be written, recording the
+// upstream IDs seen and creating catalog entries for new names.
+func linkCatalog(tx *gorm.DB, items []models.WeeklyItem) error {
+	resolver, err := newCatalogResolver(tx)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		id, err := resolver.resolve(items[i].DailyItem.Name, items[i].DailyItem.UpstreamID)
+		if err != nil {
+			return err
+		}
+		items[i].DailyItem.CatalogID = id
+	}
+	return resolver.save()
+}
+
+// backfillCatalog brings data written before the catalog existed onto it:
+// every all-data name gets a catalog entry, menu rows without a catalog ID
+// are linked, and name-only favorites gain catalog IDs. Each step touches only
+// what is still missing, so running it on every start is cheap once done.
+func backfillCatalog(tx *gorm.DB) error {
+	resolver, err := newCatalogResolver(tx)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if err := tx.Model(&GormAllDataItem{}).Pluck("name", &names).Error; err != nil {
+		return fmt.Errorf("list all-data names: %w", err)
+	}
+	for _, name := range names {
+		if _, err := resolver.resolve(name, ""); err != nil {
+			return err
+		}
+	}
+
+	var unlinked []string
+	if err := tx.Model(&GormWeeklyItem{}).
+		Where("catalog_id IS NULL OR catalog_id = 0").
+		Distinct("name").
+		Pluck("name", &unlinked).Error; err != nil {
+		return fmt.Errorf("list unlinked menu rows: %w", err)
+	}
+	for _, name := range unlinked {
+		id, err := resolver.resolve(name, "")
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			continue
+		}
+		if err := tx.Model(&GormWeeklyItem{}).
+			Where("name = ? AND (catalog_id IS NULL OR catalog_id = 0)", name).
+			Update("catalog_id", id).Error; err != nil {
+			return fmt.Errorf("link menu rows named %q: %w", name, err)
+		}
+	}
+
+	err = rewriteFavorites(tx, func(favorites []models.AllDataItem) ([]models.AllDataItem, bool, error) {
+		changed := false
+		for i := range favorites {
+			if favorites[i].CatalogID != 0 {
+				continue
+			}
+			id, err := resolver.resolve(favorites[i].Name, "")
+			if err != nil {
+				return nil, false, err
+			}
+			if id != 0 {
+				favorites[i].CatalogID = id
+				changed = true
+			}
+		}
+		return favorites, changed, nil
+	})
+	if err != nil {
+		return err
+	}
+	return resolver.save()
+}
+
+// rewriteFavorites passes every user's favorites through edit and stores the
+// lists it reports as changed. Rows whose favorites cannot be decoded are left
+// for GetUserPreferences to report.
+func rewriteFavorites(tx *gorm.DB, edit func([]models.AllDataItem) ([]models.AllDataItem, bool, error)) error {
+	var rows []GormUserPreferences
+	if err := tx.Find(&rows).Error; err != nil {
+		return fmt.Errorf("list user preferences: %w", err)
+	}
+
+	for _, row := range rows {
+		var favorites []models.AllDataItem
+		if err := json.Unmarshal([]byte(row.Favorites), &favorites); err != nil {
+			continue
+		}
+		favorites, changed, err := edit(favorites)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+		favoritesJSON, err := json.Marshal(favorites)
+		if err != nil {
+			return fmt.Errorf("error serializing favorites: %v", err)
+		}
+		if err := tx.Model(&GormUserPreferences{}).Where("id = ?", row.ID).Update("favorites", string(favoritesJSON)).Error; err != nil {
+			return fmt.Errorf("update favorites of %s: %w", row.UserID, err)
+		}
+	}
+	return nil
+}
+
+// withCatalogIDs fills in the catalog ID of each favorite from the alias
+// table. Names the catalog does not know yet keep 0 and are matched by name.
+func withCatalogIDs(tx *gorm.DB, favorites []models.AllDataItem) ([]models.AllDataItem, error) {
+	keys := make([]string, 0, len(favorites))
+	for _, favorite := range favorites {
+		if key := models.CatalogKey(favorite.Name); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return favorites, nil
+	}
+
+	var aliases []GormCatalogAlias
+	if err := tx.Where("key IN ?", keys).Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]uint, len(aliases))
+	for _, alias := range aliases {
+		byKey[alias.Key] = alias.CatalogID
+	}
+
+	resolved := make([]models.AllDataItem, len(favorites))
+	for i, favorite := range favorites {
+		favorite.CatalogID = byKey[models.CatalogKey(favorite.Name)]
+		resolved[i] = favorite
+	}
+	return resolved, nil
+}
+
+// GetCatalogItems returns the catalog items with an alias containing query,
+// normalized like item names, ordered by name. An empty query returns the
+// whole catalog.
+//
+// Parameters:
+// - query: Part of an item name, or "" for every item.
+//
+// Returns:
+// - []models.CatalogItem: The matching items with their aliases.
+// - error: An error if the operation fails.
+func GetCatalogItems(query string) ([]models.CatalogItem, error) {
+	if DB == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	items := DB.Model(&GormCatalogItem{})
+	if key := models.CatalogKey(query); key != "" {
+		items = items.Where("id IN (?)", DB.Model(&GormCatalogAlias{}).Select("catalog_id").Where("key LIKE ?", "%"+key+"%"))
+	}
+	var rows []GormCatalogItem
+	if err := items.Order("name, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return catalogItemModels(DB, rows)
+}
+
+// MergeCatalogItems folds duplicate catalog items into one: their aliases and
+// upstream IDs move to into, menu rows are relinked and favorites rewritten,
+// then the duplicates are deleted.
+//
+// Parameters:
+// - into: The catalog item to keep.
+// - from: The catalog items to fold into it.
+//
+// Returns:
+// - models.CatalogItem: The merged item.
+// - error: NoCatalogItemInDB if any of the items does not exist, or another error if the operation fails.
+func MergeCatalogItems(into uint, from []uint) (models.CatalogItem, error) {
+	if DB == nil {
+		return models.CatalogItem{}, errors.New("database is not initialized")
+	}
+
+	for _, id := range from {
+		if id == into {
+			return models.CatalogItem{}, errors.New("cannot merge a catalog item into itself")
+		}
+	}
+
+	var merged []models.CatalogItem
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var target GormCatalogItem
+		if err := tx.First(&target, into).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return NoCatalogItemInDB
+			}
+			return err
+		}
+		var sources []GormCatalogItem
+		if err := tx.Where("id IN ?", from).Order("id").Find(&sources).Error; err != nil {
+			return err
+		}
+		if len(sources) != len(from) {
+			return NoCatalogItemInDB
+		}
+
+		for _, source := range sources {
+			target.UpstreamIDs = appendUpstreamIDs(target.UpstreamIDs, source.UpstreamIDs...)
+		}
+		if err := tx.Save(&target).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&GormCatalogAlias{}).Where("catalog_id IN ?", from).Update("catalog_id", into).Error; err != nil {
+			return fmt.Errorf("move catalog aliases: %w", err)
+		}
+		if err := tx.Model(&GormWeeklyItem{}).Where("catalog_id IN ?", from).Update("catalog_id", into).Error; err != nil {
+			return fmt.Errorf("relink menu rows: %w", err)
+		}
+
+		merging := make(map[uint]bool, len(from))
+		for _, id := range from {
+			merging[id] = true
+		}
+		err := rewriteFavorites(tx, func(favorites []models.AllDataItem) ([]models.AllDataItem, bool, error) {
+			changed := false
+			kept := favorites[:0]
+			hasTarget := false
+			for _, favorite := range favorites {
+				if merging[favorite.CatalogID] {
+					favorite.CatalogID = into
+					changed = true
+				}
+				if favorite.CatalogID == into {
+					// Two favorites naming the merged item collapse into one.
+					if hasTarget {
+						changed = true
+						continue
+					}
+					hasTarget = true
+				}
+				kept = append(kept, favorite)
+			}
+			return kept, changed, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("id IN ?", from).Delete(&GormCatalogItem{}).Error; err != nil {
+			return fmt.Errorf("delete merged catalog items: %w", err)
+		}
+
+		merged, err = catalogItemModels(tx, []GormCatalogItem{target})
+		return err
+	})
+	if err != nil {
+		return models.CatalogItem{}, err
+	}
+	return merged[0], nil
+}
+
+// catalogItemModels converts catalog rows, attaching each item's aliases.
+func catalogItemModels(tx *gorm.DB, rows []GormCatalogItem) ([]models.CatalogItem, error) {
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var aliases []GormCatalogAlias
+	if len(ids) > 0 {
+		if err := tx.Where("catalog_id IN ?", ids).Order("id").Find(&aliases).Error; err != nil {
+			return nil, err
+		}
+	}
+	aliasNames := make(map[uint][]string, len(rows))
+	for _, alias := range aliases {
+		aliasNames[alias.CatalogID] = append(aliasNames[alias.CatalogID], alias.Name)
+	}
+
+	items := make([]models.CatalogItem, 0, len(rows))
+	for _, row := range rows {
+		upstreamIDs := row.UpstreamIDs
+		if upstreamIDs == nil {
+			upstreamIDs = []string{}
+		}
+		names := aliasNames[row.ID]
+		if names == nil {
+			names = []string{}
+		}
+		items = append(items, models.CatalogItem{ID: row.ID, Name: row.Name, Aliases: names, UpstreamIDs: upstreamIDs})
+	}
+	return items, nil
 }
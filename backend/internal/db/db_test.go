@@ -2,8 +2,8 @@ package db_test
 
 import (
 	"backend/internal/db"
+	"backend/internal/db/dbtest"
 	"backend/internal/models"
-	"fmt"
 	"testing"
 	"time"
 
@@ -13,22 +13,6 @@ import (
 	"gorm.io/gorm"
 )
 
-func setupTestDB(t *testing.T) *gorm.DB {
-	t.Helper()
-
-	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
-	testDB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
-	require.NoError(t, err)
-	require.NoError(t, db.Migrate(testDB))
-	db.DB = testDB
-	t.Cleanup(func() {
-		sqlDB, err := testDB.DB()
-		require.NoError(t, err)
-		require.NoError(t, sqlDB.Close())
-	})
-	return testDB
-}
-
 func menuItem(date, name, location string) models.WeeklyItem {
 	return models.WeeklyItem{DailyItem: models.DailyItem{
 		Name:        name,
@@ -40,7 +24,7 @@ func menuItem(date, name, location string) models.WeeklyItem {
 }
 
 func TestPersistScrapedMenuReplacesDatesAndPrunesOldHistory(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 	now := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC)
 	tooOld := now.AddDate(0, 0, -db.MenuRetentionDays-1).Format("2006-01-02")
 	oldestRetained := now.AddDate(0, 0, -db.MenuRetentionDays).Format("2006-01-02")
@@ -84,7 +68,7 @@ func TestPersistScrapedMenuReplacesDatesAndPrunesOldHistory(t *testing.T) {
 }
 
 func TestPersistScrapedMenuCanReplaceClosedDate(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 	now := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC)
 	date := now.Format("2006-01-02")
 
@@ -102,7 +86,7 @@ func TestPersistScrapedMenuCanReplaceClosedDate(t *testing.T) {
 }
 
 func TestPersistScrapedMenuRollsBackOnInsertFailure(t *testing.T) {
-	testDB := setupTestDB(t)
+	testDB := dbtest.Setup(t)
 	now := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC)
 	date := now.Format("2006-01-02")
 
@@ -129,7 +113,7 @@ func TestPersistScrapedMenuRollsBackOnInsertFailure(t *testing.T) {
 }
 
 func TestAvailableFavoritesUsesActualDate(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 	today := time.Now().Format("2006-01-02")
 	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
 
@@ -145,19 +129,50 @@ func TestAvailableFavoritesUsesActualDate(t *testing.T) {
 	))
 	require.NoError(t, db.SaveUserPreferences("test-user", []models.AllDataItem{{Name: "Bacon"}, {Name: "Eggs"}}))
 
-	favorites, err := db.GetAvailableFavoritesBatch("test-user")
+	favorites, err := db.GetAvailableFavoritesBatch("test-user", nil)
 	require.NoError(t, err)
 	require.Len(t, favorites, 2)
 	assert.Equal(t, "Bacon", favorites[0].Name)
 	assert.Equal(t, "Bacon", favorites[1].Name)
 }
 
+func TestDietaryProfileFiltersAvailableFavorites(t *testing.T) {
+	dbtest.Setup(t)
+	today := time.Now().Format("2006-01-02")
+
+	profile, err := db.GetDietaryProfile("test-user")
+	require.NoError(t, err)
+	assert.True(t, profile.Empty())
+
+	bacon := dbtest.MealItem(today, "Bacon", "Allison", "Breakfast")
+	eggs := dbtest.MealItem(today, "Eggs", "Allison", "Breakfast")
+	eggs.DailyItem.Filters = []string{"Eggs", "Milk*"}
+	require.NoError(t, db.PersistScrapedMenu([]models.WeeklyItem{bacon, eggs}, nil, []string{today}, time.Now()))
+	require.NoError(t, db.SaveUserPreferences("test-user", []models.AllDataItem{{Name: "Bacon"}, {Name: "Eggs"}}))
+
+	require.NoError(t, db.SaveDietaryProfile("test-user", models.DietaryProfile{Avoid: []string{"Milk"}, Require: []string{}}))
+	profile, err = db.GetDietaryProfile("test-user")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Milk"}, profile.Avoid)
+
+	favorites, err := db.GetAvailableFavoritesBatch("test-user", nil)
+	require.NoError(t, err)
+	require.Len(t, favorites, 1)
+	assert.Equal(t, "Bacon", favorites[0].Name)
+
+	// The notification cron applies the profile itself, once, when it builds
+	// the push copy.
+	favorites, err = db.GetAvailableFavoritesForMeal("test-user", today, "Breakfast", nil)
+	require.NoError(t, err)
+	assert.Len(t, favorites, 2)
+}
+
 // TestIngredientsAndFiltersSurviveRoundTrip guards the columns the clients read
 // for allergen/diet badges. Filters is stored through the gorm json serializer,
 // so it must survive both the GormWeeklyItem path and the raw-table scan into
 // []models.DailyItem used by the favorites/notification queries.
 func TestIngredientsAndFiltersSurviveRoundTrip(t *testing.T) {
-	testDB := setupTestDB(t)
+	testDB := dbtest.Setup(t)
 	date := "2026-07-27"
 
 	tagged := models.WeeklyItem{DailyItem: models.DailyItem{
@@ -200,7 +215,7 @@ func TestIngredientsAndFiltersSurviveRoundTrip(t *testing.T) {
 	assert.Empty(t, byName["Plain Rice"].Filters)
 
 	require.NoError(t, db.SaveUserPreferences("test-user", []models.AllDataItem{{Name: "Barbeque Chicken"}}))
-	favorites, err := db.GetAvailableFavoritesForMeal("test-user", date, "Lunch")
+	favorites, err := db.GetAvailableFavoritesForMeal("test-user", date, "Lunch", nil)
 	require.NoError(t, err)
 	require.Len(t, favorites, 1)
 	assert.Equal(t, tagged.DailyItem.Filters, favorites[0].Filters)
@@ -216,25 +231,15 @@ func TestIngredientsAndFiltersSurviveRoundTrip(t *testing.T) {
 	}
 }
 
-func mealItem(date, name, location, timeOfDay string) models.WeeklyItem {
-	return models.WeeklyItem{DailyItem: models.DailyItem{
-		Name:        name,
-		Date:        date,
-		Location:    location,
-		StationName: "Comfort",
-		TimeOfDay:   timeOfDay,
-	}}
-}
-
 func TestGetAvailableFavoritesForMealFiltersByMeal(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 	date := "2026-07-10"
 
 	require.NoError(t, db.PersistScrapedMenu(
 		[]models.WeeklyItem{
-			mealItem(date, "Bacon", "Allison", "Breakfast"),
-			mealItem(date, "Bacon", "Sargent", "Dinner"),
-			mealItem(date, "Eggs", "Allison", "Breakfast"),
+			dbtest.MealItem(date, "Bacon", "Allison", "Breakfast"),
+			dbtest.MealItem(date, "Bacon", "Sargent", "Dinner"),
+			dbtest.MealItem(date, "Eggs", "Allison", "Breakfast"),
 		},
 		nil,
 		[]string{date},
@@ -242,23 +247,89 @@ func TestGetAvailableFavoritesForMealFiltersByMeal(t *testing.T) {
 	))
 	require.NoError(t, db.SaveUserPreferences("test-user", []models.AllDataItem{{Name: "Bacon"}, {Name: "Eggs"}}))
 
-	breakfast, err := db.GetAvailableFavoritesForMeal("test-user", date, "Breakfast")
+	breakfast, err := db.GetAvailableFavoritesForMeal("test-user", date, "Breakfast", nil)
 	require.NoError(t, err)
 	require.Len(t, breakfast, 2)
 
-	dinner, err := db.GetAvailableFavoritesForMeal("test-user", date, "Dinner")
+	dinner, err := db.GetAvailableFavoritesForMeal("test-user", date, "Dinner", nil)
 	require.NoError(t, err)
 	require.Len(t, dinner, 1)
 	assert.Equal(t, "Bacon", dinner[0].Name)
 	assert.Equal(t, "Sargent", dinner[0].Location)
 
-	lunch, err := db.GetAvailableFavoritesForMeal("test-user", date, "Lunch")
+	lunch, err := db.GetAvailableFavoritesForMeal("test-user", date, "Lunch", nil)
 	require.NoError(t, err)
 	assert.Empty(t, lunch)
 }
 
+func TestQueryMenuItemsAppliesFilter(t *testing.T) {
+	dbtest.Setup(t)
+	date := "2026-07-10"
+	now := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, db.PersistScrapedMenu(
+		[]models.WeeklyItem{
+			dbtest.MealItem(date, "Bacon", "Allison", "Breakfast"),
+			dbtest.MealItem(date, "Pasta", "Allison", "Lunch"),
+			dbtest.MealItem(date, "Apple", "Allison", "Lunch"),
+			dbtest.MealItem(date, "Soup", "Sargent", "Lunch"),
+			dbtest.MealItem("2026-07-11", "Tacos", "Allison", "Lunch"),
+		},
+		nil,
+		[]string{date, "2026-07-11"},
+		now,
+	))
+
+	items, err := db.QueryMenuItems(models.MenuFilter{From: date, To: date, Location: "allison", Meal: "lunch"})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Apple", items[0].Name)
+	assert.Equal(t, "Pasta", items[1].Name)
+	assert.NotNil(t, items[0].Filters)
+
+	items, err = db.QueryMenuItems(models.MenuFilter{From: "2026-07-11"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Tacos", items[0].Name)
+
+	items, err = db.QueryMenuItems(models.MenuFilter{Station: "Grill"})
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestGetItemHistoryReturnsRetainedAppearances(t *testing.T) {
+	dbtest.Setup(t)
+	now := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, db.PersistScrapedMenu(
+		[]models.WeeklyItem{
+			dbtest.MealItem("2026-07-08", "Chicken Tikka Masala", "Allison", "Lunch"),
+			dbtest.MealItem("2026-07-09", "Pasta", "Allison", "Lunch"),
+			dbtest.MealItem("2026-07-10", "chicken tikka masala", "Sargent", "Dinner"),
+		},
+		nil,
+		[]string{"2026-07-08", "2026-07-09", "2026-07-10"},
+		now,
+	))
+
+	items, err := db.GetItemHistory(" Chicken Tikka Masala ", "2026-07-01")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "2026-07-10", items[0].Date)
+	assert.Equal(t, "Sargent", items[0].Location)
+	assert.Equal(t, "2026-07-08", items[1].Date)
+	assert.NotNil(t, items[0].Filters)
+
+	items, err = db.GetItemHistory("Chicken Tikka Masala", "2026-07-09")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	_, err = db.GetItemHistory("Ramen", "2026-07-01")
+	assert.ErrorIs(t, err, db.NoItemsInDB)
+}
+
 func TestDeviceTokenLifecycle(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 
 	require.NoError(t, db.SaveDeviceToken("user-a", "token-1", "ios"))
 	require.NoError(t, db.SaveDeviceToken("user-a", "token-2", "ios"))
@@ -290,7 +361,7 @@ func TestDeviceTokenLifecycle(t *testing.T) {
 }
 
 func TestDeleteUserDataRemovesDeviceTokens(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 
 	require.NoError(t, db.SaveDeviceToken("delete-me", "token-x", "ios"))
 	require.NoError(t, db.SaveDeviceToken("keep-me", "token-y", "ios"))
@@ -303,8 +374,102 @@ func TestDeleteUserDataRemovesDeviceTokens(t *testing.T) {
 	assert.ElementsMatch(t, []string{"token-y"}, tokens["keep-me"])
 }
 
+func TestFoodLogEntriesArePerUserAndDate(t *testing.T) {
+	dbtest.Setup(t)
+	item := dbtest.MealItem("2026-07-10", "Oatmeal", "Allison", "Breakfast").DailyItem
+	item.Calories = "150"
+	item.Estimates = map[string]models.NutrientEstimate{"protein": {Value: 5}}
+	item.Tags = []models.FilterTag{{Name: "Vegan"}}
+	item.IngredientTree = []models.Ingredient{{Name: "Oats"}}
+
+	first, err := db.AddFoodLogEntry("eater", models.FoodLogEntry{Date: "2026-07-10", Servings: 1.5, Item: item})
+	require.NoError(t, err)
+	assert.NotZero(t, first.ID)
+	assert.False(t, first.LoggedAt.IsZero())
+	_, err = db.AddFoodLogEntry("eater", models.FoodLogEntry{Date: "2026-07-11", Servings: 1, Item: item})
+	require.NoError(t, err)
+	other, err := db.AddFoodLogEntry("someone-else", models.FoodLogEntry{Date: "2026-07-10", Servings: 1, Item: item})
+	require.NoError(t, err)
+
+	entries, err := db.GetFoodLog("eater", "2026-07-10")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 1.5, entries[0].Servings)
+	assert.Equal(t, "Oatmeal", entries[0].Item.Name)
+	assert.Equal(t, "150", entries[0].Item.Calories)
+	assert.Equal(t, 5.0, entries[0].Item.Estimates["protein"].Value, "estimates back the log's totals")
+	assert.Nil(t, entries[0].Item.Tags)
+	assert.Nil(t, entries[0].Item.IngredientTree)
+
+	_, err = db.DeleteFoodLogEntry("eater", other.ID)
+	assert.ErrorIs(t, err, db.NoFoodLogEntryInDB, "users cannot delete each other's entries")
+
+	deleted, err := db.DeleteFoodLogEntry("eater", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-07-10", deleted.Date)
+	entries, err = db.GetFoodLog("eater", "2026-07-10")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, db.DeleteUserData("eater"))
+	entries, err = db.GetFoodLog("eater", "2026-07-11")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	entries, err = db.GetFoodLog("someone-else", "2026-07-10")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestGetFoodLogRangeIsInclusiveAndOrdered(t *testing.T) {
+	dbtest.Setup(t)
+	for _, date := range []string{"2026-07-12", "2026-07-05", "2026-07-06", "2026-07-13"} {
+		_, err := db.AddFoodLogEntry("eater", models.FoodLogEntry{Date: date, Servings: 1, Item: models.DailyItem{Name: "Toast"}})
+		require.NoError(t, err)
+	}
+	_, err := db.AddFoodLogEntry("someone-else", models.FoodLogEntry{Date: "2026-07-08", Servings: 1, Item: models.DailyItem{Name: "Toast"}})
+	require.NoError(t, err)
+
+	entries, err := db.GetFoodLogRange("eater", "2026-07-06", "2026-07-12")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "2026-07-06", entries[0].Date)
+	assert.Equal(t, "2026-07-12", entries[1].Date)
+}
+
+func TestWeeklyReportOptIn(t *testing.T) {
+	dbtest.Setup(t)
+
+	require.NoError(t, db.UpdateWeeklyReportStatus("new-user", true))
+	require.NoError(t, db.SaveUserPreferences("existing-user", []models.AllDataItem{{Name: "Eggs"}}))
+	require.NoError(t, db.UpdateWeeklyReportStatus("existing-user", true))
+	require.NoError(t, db.UpdateMailingStatus("daily-only", true))
+
+	users, err := db.GetWeeklyReportList()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"existing-user", "new-user"}, users)
+
+	require.NoError(t, db.UpdateWeeklyReportStatus("new-user", false))
+	require.NoError(t, db.UpdateWeeklyReportStatus("new-user", false), "opting out twice is not an error")
+	users, err = db.GetWeeklyReportList()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"existing-user"}, users)
+
+	optedIn, err := db.GetUserWeeklyReport("existing-user")
+	require.NoError(t, err)
+	assert.True(t, *optedIn)
+	optedIn, err = db.GetUserWeeklyReport("new-user")
+	require.NoError(t, err)
+	assert.False(t, *optedIn)
+	_, err = db.GetUserWeeklyReport("nobody")
+	assert.ErrorIs(t, err, db.NoUserPreferencesInDB)
+
+	favorites, err := db.GetUserPreferences("existing-user")
+	require.NoError(t, err)
+	assert.Equal(t, []models.AllDataItem{{Name: "Eggs"}}, favorites, "opting in keeps favorites")
+}
+
 func TestReplaceLocationOperatingTimes(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 	first := []models.LocationOperatingTimes{{Name: "Allison", Week: []models.DailyOperatingTimes{{Date: "2026-07-10"}}}}
 	second := []models.LocationOperatingTimes{{Name: "Sargent", Week: []models.DailyOperatingTimes{{Date: "2026-07-11"}}}}
 
@@ -318,7 +483,7 @@ func TestReplaceLocationOperatingTimes(t *testing.T) {
 }
 
 func TestUserAndDisplayPreferences(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 	userID := "test-user"
 	favorites := []models.AllDataItem{{Name: "Bacon"}, {Name: "Eggs"}}
 
@@ -343,7 +508,7 @@ func TestUserAndDisplayPreferences(t *testing.T) {
 }
 
 func TestDeleteUserData(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 	userID := "delete-me"
 	otherUser := "keep-me"
 
@@ -370,14 +535,150 @@ func TestDeleteUserData(t *testing.T) {
 }
 
 func TestDeleteUserDataNoRowsIsNotAnError(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 
 	// Deleting a user with no stored data should succeed.
 	require.NoError(t, db.DeleteUserData("user-with-no-data"))
 }
 
+func TestNutritionGoalsRangesSplitsAndLimitsRoundTrip(t *testing.T) {
+	dbtest.Setup(t)
+	userID := "goal-setter"
+
+	// Goals saved in the original shape read back without optional parts.
+	legacy := models.NutritionGoals{Calories: 2000, Protein: 100, Carbs: 200, Fat: 70}
+	require.NoError(t, db.SaveNutritionGoals(userID, legacy))
+	saved, err := db.GetNutritionGoals(userID)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, saved)
+
+	sodium, sugar, fiber := 2300.0, 50.0, 30.0
+	richer := models.NutritionGoals{
+		Calories:      2200,
+		Protein:       120,
+		Carbs:         250,
+		Fat:           70,
+		CaloriesRange: &models.GoalRange{Min: 2000, Max: 2400},
+		FatRange:      &models.GoalRange{Min: 60, Max: 80},
+		MealSplit:     &models.MealSplit{Breakfast: 20, Lunch: 35, Dinner: 45},
+		SodiumMax:     &sodium,
+		SugarMax:      &sugar,
+		FiberMin:      &fiber,
+	}
+	require.NoError(t, db.SaveNutritionGoals(userID, richer))
+	saved, err = db.GetNutritionGoals(userID)
+	require.NoError(t, err)
+	assert.Equal(t, richer, saved)
+
+	// Saving the original shape again clears the optional goals.
+	require.NoError(t, db.SaveNutritionGoals(userID, legacy))
+	saved, err = db.GetNutritionGoals(userID)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, saved)
+}
+
+func TestBodyStatsAreStoredPerUserAndDeletedWithTheUser(t *testing.T) {
+	dbtest.Setup(t)
+	userID := "stats-keeper"
+
+	_, err := db.GetBodyStats(userID)
+	assert.ErrorIs(t, err, db.NoBodyStatsInDB)
+
+	first := models.BodyStats{Age: 20, Sex: "female", HeightCm: 165, WeightKg: 60, ActivityLevel: "light", Objective: "maintain"}
+	require.NoError(t, db.SaveBodyStats(userID, first))
+	updated := first
+	updated.WeightKg = 58
+	updated.Objective = "cut"
+	require.NoError(t, db.SaveBodyStats(userID, updated))
+
+	saved, err := db.GetBodyStats(userID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, saved, "saving again replaces the stored stats")
+
+	require.NoError(t, db.DeleteBodyStats(userID))
+	_, err = db.GetBodyStats(userID)
+	assert.ErrorIs(t, err, db.NoBodyStatsInDB)
+	require.NoError(t, db.DeleteBodyStats(userID), "forgetting stats twice is not an error")
+
+	require.NoError(t, db.SaveBodyStats(userID, first))
+	require.NoError(t, db.DeleteUserData(userID))
+	_, err = db.GetBodyStats(userID)
+	assert.ErrorIs(t, err, db.NoBodyStatsInDB)
+}
+
+func TestSavedPlatesArePerUser(t *testing.T) {
+	dbtest.Setup(t)
+
+	plate, err := db.CreateSavedPlate("alice", models.SavedPlate{
+		Name:  "Usual",
+		Items: []models.PlateComponent{{Name: "Bacon", Servings: 2}, {Name: "Eggs", Servings: 1}},
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, plate.ID)
+
+	_, err = db.UpdateSavedPlate("bob", plate.ID, models.SavedPlate{Name: "Mine"})
+	assert.ErrorIs(t, err, db.NoSavedPlateInDB)
+	assert.ErrorIs(t, db.DeleteSavedPlate("bob", plate.ID), db.NoSavedPlateInDB)
+
+	updated, err := db.UpdateSavedPlate("alice", plate.ID, models.SavedPlate{
+		Name:  "Light",
+		Items: []models.PlateComponent{{Name: "Eggs", Servings: 0.5}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Light", updated.Name)
+
+	plates, err := db.GetSavedPlates("alice")
+	require.NoError(t, err)
+	require.Len(t, plates, 1)
+	assert.Equal(t, []models.PlateComponent{{Name: "Eggs", Servings: 0.5}}, plates[0].Items)
+
+	plates, err = db.GetSavedPlates("bob")
+	require.NoError(t, err)
+	assert.Empty(t, plates)
+
+	require.NoError(t, db.DeleteUserData("alice"))
+	plates, err = db.GetSavedPlates("alice")
+	require.NoError(t, err)
+	assert.Empty(t, plates)
+}
+
+func TestGetPlateSlotsNeedsEveryComponent(t *testing.T) {
+	dbtest.Setup(t)
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	today := time.Now().Format("2006-01-02")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	require.NoError(t, db.PersistScrapedMenu(
+		[]models.WeeklyItem{
+			dbtest.MealItem(yesterday, "Bacon", "Allison", "Breakfast"),
+			dbtest.MealItem(yesterday, "Eggs", "Allison", "Breakfast"),
+			dbtest.MealItem(today, "Bacon", "Allison", "Breakfast"),
+			dbtest.MealItem(today, "Eggs", "Sargent", "Breakfast"),
+			dbtest.MealItem(tomorrow, "Bacon", "Sargent", "Breakfast"),
+			dbtest.MealItem(tomorrow, "Eggs", "Sargent", "Breakfast"),
+			dbtest.MealItem(tomorrow, "Bacon", "Allison", "Lunch"),
+			dbtest.MealItem(tomorrow, "Eggs", "Allison", "Lunch"),
+			dbtest.MealItem(tomorrow, "Eggs", "Allison", "Dinner"),
+		},
+		nil,
+		[]string{yesterday, today, tomorrow},
+		time.Now(),
+	))
+
+	slots, err := db.GetPlateSlots([]string{"Bacon", "Eggs"}, today)
+	require.NoError(t, err)
+	assert.Equal(t, []models.PlateSlot{
+		{Date: tomorrow, TimeOfDay: "Lunch", Location: "Allison"},
+		{Date: tomorrow, TimeOfDay: "Breakfast", Location: "Sargent"},
+	}, slots)
+
+	slots, err = db.GetPlateSlots(nil, today)
+	require.NoError(t, err)
+	assert.Empty(t, slots)
+}
+
 func TestDisplayPreferencesNotFound(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 
 	preferences, hasSaved, err := db.GetDisplayPreferences("unknown-user")
 	require.NoError(t, err)
@@ -386,7 +687,7 @@ func TestDisplayPreferencesNotFound(t *testing.T) {
 }
 
 func TestInsertAllDataItemsIgnoresDuplicates(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 
 	require.NoError(t, db.InsertAllDataItems([]models.AllDataItem{
 		{Name: "Eggs"},
@@ -439,6 +740,27 @@ func storedNames(t *testing.T, testDB *gorm.DB, date, location, timeOfDay string
 	return names
 }
 
+func TestNutritionLabelSurvivesRoundTrip(t *testing.T) {
+	dbtest.Setup(t)
+	now := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC)
+
+	item := dbtest.MealItem("2026-07-10", "Tomato Soup", "Allison", "Lunch")
+	item.DailyItem.Calories = "120"
+	item.DailyItem.Nutrition = models.Nutrition{
+		Calories: &models.NutrientAmount{Value: 120, Unit: "kcal"},
+		Sodium:   &models.NutrientAmount{Value: 640, Unit: "mg"},
+		TransFat: &models.NutrientAmount{Value: 1, Unit: "g", LessThan: true},
+		Other:    map[string]models.NutrientAmount{"Vitamin C": {Value: 9, Unit: "mg"}},
+	}
+	require.NoError(t, db.PersistScrapedMenu([]models.WeeklyItem{item}, nil, []string{"2026-07-10"}, now))
+
+	items, err := db.GetItemHistory("Tomato Soup", "2026-07-01")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "120", items[0].Calories)
+	assert.Equal(t, item.DailyItem.Nutrition, items[0].Nutrition)
+}
+
 func seedTwoDaysOfMenu(t *testing.T, testDB *gorm.DB) (today, tomorrow string) {
 	t.Helper()
 	now := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC)
@@ -459,7 +781,7 @@ func seedTwoDaysOfMenu(t *testing.T, testDB *gorm.DB) (today, tomorrow string) {
 // A refresh replaces only the slices it names: other periods at the same hall,
 // other halls, and other dates keep their rows.
 func TestReplaceMenuPeriodsTouchesOnlyNamedSlices(t *testing.T) {
-	testDB := setupTestDB(t)
+	testDB := dbtest.Setup(t)
 	today, tomorrow := seedTwoDaysOfMenu(t, testDB)
 
 	err := db.ReplaceMenuPeriods(
@@ -482,7 +804,7 @@ func TestReplaceMenuPeriodsTouchesOnlyNamedSlices(t *testing.T) {
 
 // A fetch failure is expressed by omitting the slice, and must never delete.
 func TestReplaceMenuPeriodsWithNoSlicesLeavesEverythingAlone(t *testing.T) {
-	testDB := setupTestDB(t)
+	testDB := dbtest.Setup(t)
 	today, _ := seedTwoDaysOfMenu(t, testDB)
 
 	require.NoError(t, db.ReplaceMenuPeriods(nil, nil, nil))
@@ -494,7 +816,7 @@ func TestReplaceMenuPeriodsWithNoSlicesLeavesEverythingAlone(t *testing.T) {
 // A successful fetch that found no menu (hall closed for that meal) clears the
 // slice — "closed" is real information, unlike a failure.
 func TestReplaceMenuPeriodsClearsSliceOnSuccessfulEmpty(t *testing.T) {
-	testDB := setupTestDB(t)
+	testDB := dbtest.Setup(t)
 	today, _ := seedTwoDaysOfMenu(t, testDB)
 
 	err := db.ReplaceMenuPeriods(
@@ -512,7 +834,7 @@ func TestReplaceMenuPeriodsClearsSliceOnSuccessfulEmpty(t *testing.T) {
 // A hall serving Brunch instead of Lunch clears both names, so the meal slot
 // cannot end up holding two generations of rows.
 func TestReplaceMenuPeriodsHandlesBrunchAlias(t *testing.T) {
-	testDB := setupTestDB(t)
+	testDB := dbtest.Setup(t)
 	today, _ := seedTwoDaysOfMenu(t, testDB)
 
 	err := db.ReplaceMenuPeriods(
@@ -531,7 +853,7 @@ func TestReplaceMenuPeriodsHandlesBrunchAlias(t *testing.T) {
 
 // Writing an item whose slice was not cleared would duplicate stored rows.
 func TestReplaceMenuPeriodsRejectsItemsOutsideNamedSlices(t *testing.T) {
-	testDB := setupTestDB(t)
+	testDB := dbtest.Setup(t)
 	today, _ := seedTwoDaysOfMenu(t, testDB)
 
 	err := db.ReplaceMenuPeriods(
@@ -547,7 +869,7 @@ func TestReplaceMenuPeriodsRejectsItemsOutsideNamedSlices(t *testing.T) {
 }
 
 func TestReplaceMenuPeriodsValidatesSlices(t *testing.T) {
-	setupTestDB(t)
+	dbtest.Setup(t)
 
 	assert.Error(t, db.ReplaceMenuPeriods(
 		[]db.MenuPeriod{{Date: "not-a-date", Location: "Allison", TimeOfDay: "Lunch"}}, nil, nil))
@@ -556,3 +878,325 @@ func TestReplaceMenuPeriodsValidatesSlices(t *testing.T) {
 	assert.Error(t, db.ReplaceMenuPeriods(
 		[]db.MenuPeriod{{Date: "2026-07-10", Location: "Allison", TimeOfDay: ""}}, nil, nil))
 }
+
+// The change log lets clients fetch only the slices touched since their
+// version, each with its current items.
+func TestGetMenuChangesSinceReportsTouchedSlices(t *testing.T) {
+	testDB := dbtest.Setup(t)
+	today, tomorrow := seedTwoDaysOfMenu(t, testDB)
+
+	changes, err := db.GetMenuChangesSince(0)
+	require.NoError(t, err)
+	assert.True(t, changes.Reset, "a client with no version must download the full menu")
+	assert.NotZero(t, changes.Version)
+	assert.Empty(t, changes.Periods)
+	version := changes.Version
+
+	require.NoError(t, db.ReplaceMenuPeriods(
+		[]db.MenuPeriod{
+			{Date: today, Location: "Allison", TimeOfDay: "Lunch"},
+			{Date: today, Location: "Sargent", TimeOfDay: "Lunch"},
+		},
+		[]models.WeeklyItem{periodItem(today, "New lunch entree", "Allison", "Lunch")},
+		nil,
+	))
+
+	changes, err = db.GetMenuChangesSince(version)
+	require.NoError(t, err)
+	assert.False(t, changes.Reset)
+	assert.Greater(t, changes.Version, version)
+	require.Len(t, changes.Periods, 2)
+	assert.Equal(t, "Allison", changes.Periods[0].Location)
+	assert.False(t, changes.Periods[0].Cleared)
+	require.Len(t, changes.Periods[0].Items, 1)
+	assert.Equal(t, "New lunch entree", changes.Periods[0].Items[0].Name)
+	assert.Equal(t, "Sargent", changes.Periods[1].Location)
+	assert.True(t, changes.Periods[1].Cleared)
+	assert.Empty(t, changes.Periods[1].Items)
+
+	// Re-scraping a date replaces every slice stored on it, including ones the
+	// new scrape no longer serves.
+	version = changes.Version
+	now := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, db.PersistScrapedMenu(
+		[]models.WeeklyItem{periodItem(tomorrow, "Tomorrow dinner", "Allison", "Dinner")},
+		nil,
+		[]string{tomorrow},
+		now,
+	))
+	changes, err = db.GetMenuChangesSince(version)
+	require.NoError(t, err)
+	require.Len(t, changes.Periods, 2)
+	assert.Equal(t, "Dinner", changes.Periods[0].TimeOfDay)
+	assert.False(t, changes.Periods[0].Cleared)
+	assert.Equal(t, "Lunch", changes.Periods[1].TimeOfDay)
+	assert.True(t, changes.Periods[1].Cleared)
+
+	changes, err = db.GetMenuChangesSince(changes.Version)
+	require.NoError(t, err)
+	assert.False(t, changes.Reset)
+	assert.Empty(t, changes.Periods)
+
+	changes, err = db.GetMenuChangesSince(changes.Version + 100)
+	require.NoError(t, err)
+	assert.True(t, changes.Reset, "an unknown version must force a full download")
+}
+
+func TestPersistScrapedMenuLogsPrunedSlicesAsCleared(t *testing.T) {
+	testDB := dbtest.Setup(t)
+	today, _ := seedTwoDaysOfMenu(t, testDB)
+
+	changes, err := db.GetMenuChangesSince(0)
+	require.NoError(t, err)
+	version := changes.Version
+
+	// A scrape a retention window later prunes every slice stored on today.
+	later := time.Date(2026, time.July, 10, 12, 0, 0, 0, time.UTC).AddDate(0, 0, db.MenuRetentionDays+1)
+	laterDate := later.Format("2006-01-02")
+	require.NoError(t, db.PersistScrapedMenu(
+		[]models.WeeklyItem{periodItem(laterDate, "Later lunch", "Allison", "Lunch")},
+		nil,
+		[]string{laterDate},
+		later,
+	))
+
+	changes, err = db.GetMenuChangesSince(version)
+	require.NoError(t, err)
+	assert.False(t, changes.Reset)
+	cleared := map[string]bool{}
+	for _, period := range changes.Periods {
+		if period.Date == today {
+			assert.True(t, period.Cleared, "pruned slice %s/%s", period.Location, period.TimeOfDay)
+			cleared[period.Location+"/"+period.TimeOfDay] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{
+		"Allison/Lunch":     true,
+		"Allison/Breakfast": true,
+		"Allison/Everyday":  true,
+		"Sargent/Lunch":     true,
+	}, cleared)
+}
+
+func TestFilterMappingsAreSeededAndEditable(t *testing.T) {
+	testDB := dbtest.Setup(t)
+
+	mappings, err := db.GetFilterMappings()
+	require.NoError(t, err)
+	assert.Len(t, mappings, len(models.DefaultFilterMappings()))
+	assert.Contains(t, mappings, models.FilterMapping{Name: "Sesame", Key: "sesame", Category: models.FilterAllergen})
+
+	require.NoError(t, db.SaveFilterMapping(models.FilterMapping{Name: "Mustard", Key: "mustard", Category: models.FilterAllergen}))
+	require.NoError(t, db.SaveFilterMapping(models.FilterMapping{Name: "sesame", Key: "sesame_seed", Category: models.FilterAllergen}))
+	mappings, err = db.GetFilterMappings()
+	require.NoError(t, err)
+	assert.Len(t, mappings, len(models.DefaultFilterMappings())+1)
+	assert.Contains(t, mappings, models.FilterMapping{Name: "sesame", Key: "sesame_seed", Category: models.FilterAllergen})
+
+	require.NoError(t, db.DeleteFilterMapping("MUSTARD"))
+	assert.ErrorIs(t, db.DeleteFilterMapping("Mustard"), db.NoFilterMappingInDB)
+
+	require.NoError(t, db.Migrate(testDB))
+	mappings, err = db.GetFilterMappings()
+	require.NoError(t, err)
+	assert.Len(t, mappings, len(models.DefaultFilterMappings()), "a populated table is not reseeded")
+}
+
+func TestFavoritesMatchOnCatalogIdentity(t *testing.T) {
+	dbtest.Setup(t)
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	today := now.Format("2006-01-02")
+
+	tikka := dbtest.MealItem(yesterday, "Chicken Tikka Masala", "Allison", "Dinner")
+	tikka.DailyItem.UpstreamID = "item-42"
+	require.NoError(t, db.PersistScrapedMenu([]models.WeeklyItem{tikka}, []models.AllDataItem{{Name: "Chicken Tikka Masala"}}, []string{yesterday}, now))
+	require.NoError(t, db.SaveUserPreferences("test-user", []models.AllDataItem{{Name: "Chicken Tikka Masala"}}))
+
+	favorites, err := db.GetUserPreferences("test-user")
+	require.NoError(t, err)
+	require.Len(t, favorites, 1)
+	require.NotZero(t, favorites[0].CatalogID)
+
+	// Today the hall respells the dish and renames it outright under the same
+	// upstream ID; both still count as the favorite.
+	respelled := dbtest.MealItem(today, "chicken tikka  masala", "Allison", "Dinner")
+	renamed := dbtest.MealItem(today, "Tikka Masala (Halal)", "Sargent", "Dinner")
+	renamed.DailyItem.UpstreamID = "item-42"
+	other := dbtest.MealItem(today, "Chicken Tikka Wrap", "Elder", "Dinner")
+	require.NoError(t, db.PersistScrapedMenu([]models.WeeklyItem{respelled, renamed, other}, nil, []string{today}, now))
+
+	matches, err := db.GetAvailableFavoritesForMeal("test-user", today, "Dinner", nil)
+	require.NoError(t, err)
+	var names []string
+	for _, item := range matches {
+		assert.Equal(t, favorites[0].CatalogID, item.CatalogID)
+		names = append(names, item.Name)
+	}
+	assert.ElementsMatch(t, []string{"chicken tikka  masala", "Tikka Masala (Halal)"}, names)
+
+	catalog, err := db.GetCatalogItems("tikka masala")
+	require.NoError(t, err)
+	require.Len(t, catalog, 1)
+	assert.Equal(t, []string{"Chicken Tikka Masala", "Tikka Masala (Halal)"}, catalog[0].Aliases)
+	assert.Equal(t, []string{"item-42"}, catalog[0].UpstreamIDs)
+}
+
+func TestGetPlateSlotsMatchesOnCatalogIdentity(t *testing.T) {
+	dbtest.Setup(t)
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	today := now.Format("2006-01-02")
+
+	tikka := dbtest.MealItem(yesterday, "Chicken Tikka Masala", "Allison", "Dinner")
+	tikka.DailyItem.UpstreamID = "item-42"
+	require.NoError(t, db.PersistScrapedMenu([]models.WeeklyItem{tikka}, nil, []string{yesterday}, now))
+
+	// Today Allison respells the dish and Sargent renames it under the same
+	// upstream ID; both still serve the plate alongside rice.
+	respelled := dbtest.MealItem(today, "chicken tikka  masala", "Allison", "Dinner")
+	renamed := dbtest.MealItem(today, "Tikka Masala (Halal)", "Sargent", "Dinner")
+	renamed.DailyItem.UpstreamID = "item-42"
+	require.NoError(t, db.PersistScrapedMenu(
+		[]models.WeeklyItem{
+			respelled,
+			dbtest.MealItem(today, "Basmati Rice", "Allison", "Dinner"),
+			renamed,
+			dbtest.MealItem(today, "Basmati Rice", "Sargent", "Dinner"),
+			dbtest.MealItem(today, "Chicken Tikka Wrap", "Elder", "Dinner"),
+			dbtest.MealItem(today, "Basmati Rice", "Elder", "Dinner"),
+		},
+		nil,
+		[]string{today},
+		now,
+	))
+
+	slots, err := db.GetPlateSlots([]string{"Chicken Tikka Masala ", "Basmati Rice"}, today)
+	require.NoError(t, err)
+	assert.Equal(t, []models.PlateSlot{
+		{Date: today, TimeOfDay: "Dinner", Location: "Allison"},
+		{Date: today, TimeOfDay: "Dinner", Location: "Sargent"},
+	}, slots)
+}
+
+func TestMergeCatalogItemsMovesRowsAndFavorites(t *testing.T) {
+	dbtest.Setup(t)
+	now := time.Now()
+	today := now.Format("2006-01-02")
+
+	items := []models.WeeklyItem{
+		dbtest.MealItem(today, "Mac & Cheese", "Allison", "Lunch"),
+		dbtest.MealItem(today, "Mac and Cheese", "Sargent", "Lunch"),
+	}
+	require.NoError(t, db.PersistScrapedMenu(items, nil, []string{today}, now))
+	require.NoError(t, db.SaveUserPreferences("test-user", []models.AllDataItem{{Name: "Mac & Cheese"}, {Name: "Mac and Cheese"}}))
+
+	catalog, err := db.GetCatalogItems("mac")
+	require.NoError(t, err)
+	require.Len(t, catalog, 2, "different spellings are separate until merged")
+	into, from := catalog[0].ID, catalog[1].ID
+
+	merged, err := db.MergeCatalogItems(into, []uint{from})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Mac & Cheese", "Mac and Cheese"}, merged.Aliases)
+
+	favorites, err := db.GetUserPreferences("test-user")
+	require.NoError(t, err)
+	require.Len(t, favorites, 1, "favorites naming the same item collapse")
+	assert.Equal(t, into, favorites[0].CatalogID)
+
+	matches, err := db.GetAvailableFavoritesForMeal("test-user", today, "Lunch", nil)
+	require.NoError(t, err)
+	assert.Len(t, matches, 2)
+
+	_, err = db.MergeCatalogItems(into, []uint{from})
+	assert.ErrorIs(t, err, db.NoCatalogItemInDB)
+	_, err = db.MergeCatalogItems(into, []uint{into})
+	assert.Error(t, err)
+}
+
+func TestMigrateBackfillsCatalogForExistingData(t *testing.T) {
+	testDB := dbtest.Setup(t)
+	today := time.Now().Format("2006-01-02")
+
+	// Rows and favorites written before the catalog existed.
+	require.NoError(t, testDB.Create(&db.GormWeeklyItem{DailyItem: dbtest.MealItem(today, "Pad Thai", "Elder", "Dinner").DailyItem}).Error)
+	require.NoError(t, testDB.Create(&db.GormUserPreferences{UserID: "test-user", Favorites: `[{"Name":"Pad Thai"}]`}).Error)
+
+	require.NoError(t, db.Migrate(testDB))
+
+	favorites, err := db.GetUserPreferences("test-user")
+	require.NoError(t, err)
+	require.Len(t, favorites, 1)
+	require.NotZero(t, favorites[0].CatalogID)
+
+	weekly, err := db.GetAllWeeklyItems()
+	require.NoError(t, err)
+	assert.Equal(t, favorites[0].CatalogID, weekly[today][0].CatalogID)
+}
+
+// ruleMatches stands in for subscription.Menu: it reports the same rule
+// matches for every user.
+type ruleMatches []models.DailyItem
+
+func (m ruleMatches) Matches(userID string) ([]models.DailyItem, error) {
+	return m, nil
+}
+
+func TestAvailableFavoritesIncludeRuleMatches(t *testing.T) {
+	dbtest.Setup(t)
+	today := time.Now().Format("2006-01-02")
+	ramen := dbtest.MealItem(today, "Spicy Miso Ramen", "Allison", "Dinner")
+	tofu := dbtest.MealItem(today, "Tofu Scramble", "Sargent", "Dinner")
+	tofu.DailyItem.Filters = []string{"Vegan"}
+	require.NoError(t, db.PersistScrapedMenu([]models.WeeklyItem{ramen, tofu}, nil, []string{today}, time.Now()))
+	rules := ruleMatches{ramen.DailyItem, tofu.DailyItem}
+
+	// A user with rules and no preferences row is still alerted.
+	matches, err := db.GetAvailableFavoritesForMeal("test-user", today, "Dinner", rules)
+	require.NoError(t, err)
+	var names []string
+	for _, item := range matches {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"Spicy Miso Ramen", "Tofu Scramble"}, names)
+
+	// An item that is both a favorite and a rule match is listed once, and the
+	// dietary profile applies to rule matches too.
+	require.NoError(t, db.SaveUserPreferences("test-user", []models.AllDataItem{{Name: "Spicy Miso Ramen"}}))
+	require.NoError(t, db.SaveDietaryProfile("test-user", models.DietaryProfile{Require: []string{}, AvoidIngredients: []string{}, Avoid: []string{"Vegan"}}))
+	require.NoError(t, db.UpdateMailingStatus("test-user", true))
+	list, err := db.GetMailingList(rules)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	names = nil
+	for _, item := range list[0].Preferences {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"Spicy Miso Ramen"}, names)
+}
+
+func TestSubscriptionRulesArePerUser(t *testing.T) {
+	dbtest.Setup(t)
+
+	rule, err := db.CreateSubscriptionRule("alice", models.SubscriptionRule{Name: "Ramen", Query: "ramen"})
+	require.NoError(t, err)
+	assert.NotZero(t, rule.ID)
+
+	_, err = db.UpdateSubscriptionRule("bob", rule.ID, models.SubscriptionRule{Name: "Mine", Query: "pizza"})
+	assert.ErrorIs(t, err, db.NoSubscriptionRuleInDB)
+	assert.ErrorIs(t, db.DeleteSubscriptionRule("bob", rule.ID), db.NoSubscriptionRuleInDB)
+
+	updated, err := db.UpdateSubscriptionRule("alice", rule.ID, models.SubscriptionRule{Name: "Noodles", Query: "ramen location:allison"})
+	require.NoError(t, err)
+	assert.Equal(t, "ramen location:allison", updated.Query)
+
+	rules, err := db.GetSubscriptionRules("bob")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	require.NoError(t, db.DeleteUserData("alice"))
+	rules, err = db.GetSubscriptionRules("alice")
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
@@ -0,0 +1,43 @@
+// Package dbtest provides the database fixtures shared by tests that run
+// against the db package.
+package dbtest
+
+import (
+	"backend/internal/db"
+	"backend/internal/models"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Setup opens a migrated in-memory sqlite database named after the test,
+// installs it as db.DB and closes it when the test ends.
+func Setup(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	testDB, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(testDB))
+	db.DB = testDB
+	t.Cleanup(func() {
+		sqlDB, err := testDB.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+	})
+	return testDB
+}
+
+// MealItem returns a menu row at the Comfort station for the given meal period.
+func MealItem(date, name, location, timeOfDay string) models.WeeklyItem {
+	return models.WeeklyItem{DailyItem: models.DailyItem{
+		Name:        name,
+		Date:        date,
+		Location:    location,
+		StationName: "Comfort",
+		TimeOfDay:   timeOfDay,
+	}}
+}
@@ -0,0 +1,293 @@
+// Package estimate fills in calories and macros that upstream labels leave
+// empty.
+//
+// An item's own history comes first: the same item name served on other days
+// or at other halls usually carries the label, and the median of those values
+// is a good stand-in. Items never seen with a label borrow from their nearest
+// neighbours instead, scored by how many name and ingredient words they share.
+// Every estimate carries a confidence so clients can tell a near-certain fill
+// from a rough guess.
+package estimate
+
+import (
+	"backend/internal/models"
+	"backend/internal/nutrition"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Estimate sources.
+const (
+	SourceHistory = "history"
+	SourceSimilar = "similar"
+)
+
+// Fields are the nutrients the estimator fills.
+var Fields = []string{nutrition.Calories, nutrition.Protein, nutrition.Carbs, nutrition.Fat}
+
+// Neighbour search bounds.
+const (
+	// Neighbors is how many similar items an estimate averages.
+	Neighbors = 3
+	// MinSimilarity is the lowest similarity (0-1) a neighbour may have.
+	MinSimilarity = 0.34
+)
+
+// stopWords carry no signal about what a dish is.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "with": true,
+	"in": true, "on": true, "or": true, "w": true, "style": true, "contains": true,
+}
+
+// reference is every appearance of one item name that has label values.
+type reference struct {
+	name        string
+	nameWords   map[string]bool
+	ingredients map[string]bool
+	values      map[string][]float64
+	median      map[string]float64
+}
+
+// Estimator estimates missing nutrients from a set of reference items.
+type Estimator struct {
+	references []*reference
+	byName     map[string]*reference
+	byWord     map[string][]*reference
+}
+
+// New builds an estimator from items, usually every item in the menu store.
+// Only label values are learned from, never earlier estimates.
+func New(items []models.DailyItem) *Estimator {
+	e := &Estimator{byName: make(map[string]*reference), byWord: make(map[string][]*reference)}
+	for _, item := range items {
+		key := nameKey(item.Name)
+		if key == "" {
+			continue
+		}
+		ref := e.byName[key]
+		if ref == nil {
+			ref = &reference{name: key, nameWords: words(item.Name), values: make(map[string][]float64)}
+			e.byName[key] = ref
+			e.references = append(e.references, ref)
+			for word := range ref.nameWords {
+				e.byWord[word] = append(e.byWord[word], ref)
+			}
+		}
+		if len(ref.ingredients) == 0 && strings.TrimSpace(item.Ingredients) != "" {
+			ref.ingredients = words(item.Ingredients)
+		}
+		for _, field := range Fields {
+			if value, ok := nutrition.Value(item, field); ok {
+				ref.values[field] = append(ref.values[field], value)
+			}
+		}
+	}
+	for _, ref := range e.references {
+		ref.median = make(map[string]float64)
+		for field, values := range ref.values {
+			ref.median[field] = median(values)
+		}
+	}
+	return e
+}
+
+// Estimate returns estimates for the fields item's label leaves empty, or nil
+// when nothing is missing or nothing could be estimated.
+func (e *Estimator) Estimate(item models.DailyItem) map[string]models.NutrientEstimate {
+	var missing []string
+	for _, field := range Fields {
+		if _, ok := nutrition.Value(item, field); !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	key := nameKey(item.Name)
+	var neighbors []neighbor
+	estimates := make(map[string]models.NutrientEstimate)
+	for _, field := range missing {
+		if ref := e.byName[key]; ref != nil && len(ref.values[field]) > 0 {
+			estimates[field] = fromHistory(ref.values[field])
+			continue
+		}
+		if neighbors == nil {
+			neighbors = e.neighbors(item, key)
+		}
+		if estimate, ok := fromNeighbors(neighbors, field); ok {
+			estimates[field] = estimate
+		}
+	}
+	if len(estimates) == 0 {
+		return nil
+	}
+	return estimates
+}
+
+// FillWeekly sets Estimates on every item of a weekly menu map, learning from
+// all of its items. Items are updated in place.
+func FillWeekly(weekly map[string][]models.DailyItem) {
+	var all []models.DailyItem
+	for _, items := range weekly {
+		all = append(all, items...)
+	}
+	estimator := New(all)
+	for _, items := range weekly {
+		for i := range items {
+			items[i].Estimates = estimator.Estimate(items[i])
+		}
+	}
+}
+
+// neighbor is a reference with its similarity to the item being estimated.
+type neighbor struct {
+	ref        *reference
+	similarity float64
+}
+
+// neighbors returns references sharing a name word with item, most similar
+// first, skipping item's own name and anything below MinSimilarity.
+func (e *Estimator) neighbors(item models.DailyItem, key string) []neighbor {
+	nameWords := words(item.Name)
+	ingredients := words(item.Ingredients)
+
+	seen := make(map[*reference]bool)
+	result := []neighbor{}
+	for word := range nameWords {
+		for _, ref := range e.byWord[word] {
+			if seen[ref] || ref.name == key {
+				continue
+			}
+			seen[ref] = true
+			if similarity := similarity(nameWords, ingredients, ref); similarity >= MinSimilarity {
+				result = append(result, neighbor{ref: ref, similarity: similarity})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].similarity != result[j].similarity {
+			return result[i].similarity > result[j].similarity
+		}
+		return result[i].ref.name < result[j].ref.name
+	})
+	return result
+}
+
+// similarity weighs shared name words over shared ingredients. Without
+// ingredients on both sides only the name counts, discounted since it is
+// weaker evidence.
+func similarity(nameWords, ingredients map[string]bool, ref *reference) float64 {
+	name := jaccard(nameWords, ref.nameWords)
+	if len(ingredients) == 0 || len(ref.ingredients) == 0 {
+		return name * 0.85
+	}
+	return 0.7*name + 0.3*jaccard(ingredients, ref.ingredients)
+}
+
+// fromHistory estimates from the same item's label values: their median, with
+// confidence growing with the number of appearances and shrinking as they
+// disagree.
+func fromHistory(values []float64) models.NutrientEstimate {
+	base := 0.6 + 0.1*float64(min(len(values), 3))
+	return models.NutrientEstimate{
+		Value:      round(median(values), 10),
+		Confidence: round(base*(1-math.Min(variation(values), 0.5)), 100),
+		Source:     SourceHistory,
+		Samples:    len(values),
+	}
+}
+
+// fromNeighbors averages up to Neighbors similar items that list field,
+// weighted by similarity. Confidence is capped well below a history estimate.
+func fromNeighbors(neighbors []neighbor, field string) (models.NutrientEstimate, bool) {
+	var weighted, weights float64
+	used := 0
+	for _, n := range neighbors {
+		value, ok := n.ref.median[field]
+		if !ok {
+			continue
+		}
+		weighted += value * n.similarity
+		weights += n.similarity
+		used++
+		if used == Neighbors {
+			break
+		}
+	}
+	if used == 0 {
+		return models.NutrientEstimate{}, false
+	}
+	meanSimilarity := weights / float64(used)
+	return models.NutrientEstimate{
+		Value:      round(weighted/weights, 10),
+		Confidence: round(0.7*meanSimilarity*(0.7+0.1*float64(used)), 100),
+		Source:     SourceSimilar,
+		Samples:    used,
+	}, true
+}
+
+func nameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// words splits text into lowercase words, dropping stop words and
+// single letters.
+func words(text string) map[string]bool {
+	result := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len(word) > 1 && !stopWords[word] {
+			result[word] = true
+		}
+	}
+	return result
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
+
+// variation is the coefficient of variation of values, 0 when they agree.
+func variation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(squares/float64(len(values))) / mean
+}
+
+func round(value, scale float64) float64 {
+	return math.Round(value*scale) / scale
+}
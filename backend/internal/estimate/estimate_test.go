@@ -0,0 +1,71 @@
+package estimate
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dish(name, ingredients, calories, protein, carbs, fat string) models.DailyItem {
+	return models.DailyItem{Name: name, Ingredients: ingredients, Calories: calories, Protein: protein, Carbs: carbs, Fat: fat}
+}
+
+func TestEstimateFromHistory(t *testing.T) {
+	items := []models.DailyItem{
+		dish("Chicken Tikka Masala", "chicken, cream, tomato", "410", "32", "18", "22"),
+		dish("chicken tikka masala", "chicken, cream, tomato", "430", "30", "", "22"),
+		dish("Chicken Tikka Masala", "chicken, cream, tomato", "420", "", "", "24"),
+	}
+	estimator := New(items)
+
+	estimates := estimator.Estimate(dish("Chicken Tikka Masala", "", "", "", "20", "23"))
+	require.Len(t, estimates, 2, "only missing fields are estimated")
+
+	calories := estimates["calories"]
+	assert.Equal(t, 420.0, calories.Value)
+	assert.Equal(t, SourceHistory, calories.Source)
+	assert.Equal(t, 3, calories.Samples)
+	assert.InDelta(t, 0.88, calories.Confidence, 0.01)
+
+	protein := estimates["protein"]
+	assert.Equal(t, 31.0, protein.Value)
+	assert.Less(t, protein.Confidence, calories.Confidence, "fewer appearances mean less confidence")
+
+	assert.Nil(t, estimator.Estimate(items[0]), "complete labels need no estimate")
+}
+
+func TestEstimateFromSimilarItems(t *testing.T) {
+	items := []models.DailyItem{
+		dish("Beef Chili", "ground beef, kidney beans, tomato, chili powder", "350", "25", "20", "18"),
+		dish("Turkey Chili", "ground turkey, kidney beans, tomato, chili powder", "300", "27", "22", "10"),
+		dish("Vanilla Cake", "flour, sugar, butter, eggs", "450", "5", "60", "20"),
+	}
+	estimator := New(items)
+
+	estimates := estimator.Estimate(dish("Chicken Chili", "chicken, kidney beans, tomato, chili powder", "", "", "", ""))
+	require.Len(t, estimates, 4)
+	calories := estimates["calories"]
+	assert.Equal(t, SourceSimilar, calories.Source)
+	assert.Equal(t, 2, calories.Samples, "the cake shares no words and is ignored")
+	assert.Greater(t, calories.Value, 300.0)
+	assert.Less(t, calories.Value, 350.0)
+	assert.Less(t, calories.Confidence, 0.7)
+
+	assert.Nil(t, estimator.Estimate(dish("Mystery Soup", "", "", "", "", "")), "nothing similar means no estimate")
+}
+
+func TestFillWeeklySetsEstimatesInPlace(t *testing.T) {
+	weekly := map[string][]models.DailyItem{
+		"2026-07-10": {dish("Pancakes", "", "250", "6", "40", "8")},
+		"2026-07-11": {dish("Pancakes", "", "", "", "", "")},
+	}
+
+	FillWeekly(weekly)
+
+	assert.Nil(t, weekly["2026-07-10"][0].Estimates)
+	estimates := weekly["2026-07-11"][0].Estimates
+	require.Len(t, estimates, 4)
+	assert.Equal(t, models.NutrientEstimate{Value: 250, Confidence: 0.7, Source: SourceHistory, Samples: 1}, estimates["calories"])
+}
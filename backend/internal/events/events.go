@@ -0,0 +1,142 @@
+// Package events fans out menu and operating-hours updates to connected
+// clients, so they learn about a refresh without polling. The server streams
+// them as server-sent events from /api/events.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	TypeMenu  = "menu"  // stored menu slices were replaced or cleared
+	TypeHours = "hours" // location operating hours were replaced
+)
+
+// DefaultBuffer is how many undelivered events a subscriber may fall behind by
+// before it is disconnected.
+const DefaultBuffer = 16
+
+// Period is one menu slice (date, location, time of day) an update touched.
+type Period struct {
+	Date      string `json:"date"`
+	Location  string `json:"location"`
+	TimeOfDay string `json:"timeOfDay"`
+	Cleared   bool   `json:"cleared"`
+}
+
+// Event is one update. For menu events, Version is the menu change version the
+// periods lead up to (see /api/menu/changes); Reset means the periods are not
+// known and clients should refetch the whole menu.
+type Event struct {
+	ID      uint64    `json:"id"`
+	Type    string    `json:"type"`
+	Version uint      `json:"version,omitempty"`
+	Reset   bool      `json:"reset,omitempty"`
+	Periods []Period  `json:"periods"`
+	At      time.Time `json:"at"`
+}
+
+// Subscription receives published events on C until it is closed, either by
+// Unsubscribe or because the subscriber fell too far behind.
+type Subscription struct {
+	C           <-chan Event
+	ch          chan Event
+	broadcaster *Broadcaster
+}
+
+// Unsubscribe stops delivery and closes C. It is safe to call more than once
+// and after the broadcaster has already dropped the subscription.
+func (s *Subscription) Unsubscribe() {
+	s.broadcaster.remove(s.ch)
+}
+
+// Broadcaster delivers every published event to every subscriber. Publishing
+// never blocks: each subscriber has its own buffer, and one whose buffer is
+// full is dropped rather than allowed to stall everyone else. A dropped client
+// reconnects and refetches.
+type Broadcaster struct {
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroadcaster creates a broadcaster with no subscribers.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subscribers: make(map[chan Event]struct{})}
+}
+
+// Subscribe registers a subscriber that may buffer up to buffer events.
+func (b *Broadcaster) Subscribe(buffer int) *Subscription {
+	if buffer < 1 {
+		buffer = 1
+	}
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return &Subscription{C: ch, ch: ch, broadcaster: b}
+}
+
+// Publish assigns the event an ID and timestamp and queues it for every
+// subscriber. It returns the event as delivered.
+func (b *Broadcaster) Publish(event Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event.ID = b.nextID
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+	if event.Periods == nil {
+		event.Periods = []Period{}
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return event
+}
+
+// Subscribers returns the number of connected subscribers.
+func (b *Broadcaster) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+func (b *Broadcaster) remove(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Global broadcaster shared by the publishers and the SSE endpoint.
+var broadcaster = NewBroadcaster()
+
+// Subscribe registers a subscriber on the global broadcaster.
+func Subscribe(buffer int) *Subscription {
+	return broadcaster.Subscribe(buffer)
+}
+
+// Publish sends an event to every subscriber of the global broadcaster.
+func Publish(event Event) Event {
+	return broadcaster.Publish(event)
+}
+
+// PublishHours announces that location operating hours were replaced.
+func PublishHours() Event {
+	return Publish(Event{Type: TypeHours})
+}
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcasterFansOutToEverySubscriber(t *testing.T) {
+	broadcaster := NewBroadcaster()
+	first := broadcaster.Subscribe(4)
+	second := broadcaster.Subscribe(4)
+
+	published := broadcaster.Publish(Event{Type: TypeMenu, Periods: []Period{{Date: "2026-07-10", Location: "Allison", TimeOfDay: "Lunch"}}})
+	assert.EqualValues(t, 1, published.ID)
+	assert.False(t, published.At.IsZero())
+
+	for _, subscription := range []*Subscription{first, second} {
+		event := <-subscription.C
+		assert.Equal(t, published.ID, event.ID)
+		assert.Equal(t, "Allison", event.Periods[0].Location)
+	}
+
+	hours := broadcaster.Publish(Event{Type: TypeHours})
+	assert.EqualValues(t, 2, hours.ID)
+	assert.NotNil(t, hours.Periods, "periods must encode as an array")
+}
+
+func TestBroadcasterDropsSlowSubscribers(t *testing.T) {
+	broadcaster := NewBroadcaster()
+	slow := broadcaster.Subscribe(1)
+	fast := broadcaster.Subscribe(4)
+
+	broadcaster.Publish(Event{Type: TypeMenu})
+	broadcaster.Publish(Event{Type: TypeMenu})
+	assert.Equal(t, 1, broadcaster.Subscribers())
+
+	_, ok := <-slow.C
+	require.True(t, ok, "the buffered event is still delivered")
+	_, ok = <-slow.C
+	assert.False(t, ok, "a subscriber that fell behind is closed")
+
+	assert.Len(t, fast.C, 2)
+	slow.Unsubscribe() // already dropped: must not panic
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	broadcaster := NewBroadcaster()
+	subscription := broadcaster.Subscribe(DefaultBuffer)
+
+	subscription.Unsubscribe()
+	subscription.Unsubscribe()
+
+	_, ok := <-subscription.C
+	assert.False(t, ok)
+	assert.Zero(t, broadcaster.Subscribers())
+	broadcaster.Publish(Event{Type: TypeHours})
+}
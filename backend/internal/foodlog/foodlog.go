@@ -0,0 +1,85 @@
+// Package foodlog totals a user's logged servings and compares them with
+// their nutrition goals.
+package foodlog
+
+import (
+	"backend/internal/models"
+	"backend/internal/nutrition"
+	"math"
+)
+
+// MaxServings bounds a single entry's servings multiplier.
+const MaxServings = 20
+
+// Summarize totals entries (all logged on date) and compares the totals with
+// goals. Nutrients an item does not list fall back to its estimates, which
+// mark the entry as estimated; with neither they count as zero and mark the
+// entry as incomplete.
+func Summarize(date string, entries []models.FoodLogEntry, goals models.NutritionGoals) models.FoodLogSummary {
+	summary := models.FoodLogSummary{
+		Date:    date,
+		Entries: len(entries),
+		Goals:   goals,
+	}
+
+	for _, entry := range entries {
+		complete, estimated := true, false
+		add := func(total *float64, field string) {
+			value, fieldEstimated, ok := nutrition.ValueOrEstimate(entry.Item, field)
+			if !ok {
+				complete = false
+				return
+			}
+			estimated = estimated || fieldEstimated
+			*total += value * entry.Servings
+		}
+		add(&summary.Totals.Calories, nutrition.Calories)
+		add(&summary.Totals.Protein, nutrition.Protein)
+		add(&summary.Totals.Carbs, nutrition.Carbs)
+		add(&summary.Totals.Fat, nutrition.Fat)
+		if !complete {
+			summary.Incomplete++
+		}
+		if estimated {
+			summary.Estimated++
+		}
+	}
+
+	summary.Totals = mapTotals(summary.Totals, round)
+	summary.Remaining = models.NutrientTotals{
+		Calories: round(goals.Calories - summary.Totals.Calories),
+		Protein:  round(goals.Protein - summary.Totals.Protein),
+		Carbs:    round(goals.Carbs - summary.Totals.Carbs),
+		Fat:      round(goals.Fat - summary.Totals.Fat),
+	}
+	summary.Percent = models.NutrientTotals{
+		Calories: percent(summary.Totals.Calories, goals.Calories),
+		Protein:  percent(summary.Totals.Protein, goals.Protein),
+		Carbs:    percent(summary.Totals.Carbs, goals.Carbs),
+		Fat:      percent(summary.Totals.Fat, goals.Fat),
+	}
+	return summary
+}
+
+// mapTotals applies fn to every nutrient of totals.
+func mapTotals(totals models.NutrientTotals, fn func(float64) float64) models.NutrientTotals {
+	return models.NutrientTotals{
+		Calories: fn(totals.Calories),
+		Protein:  fn(totals.Protein),
+		Carbs:    fn(totals.Carbs),
+		Fat:      fn(totals.Fat),
+	}
+}
+
+func percent(total, goal float64) float64 {
+	if goal <= 0 {
+		return 0
+	}
+	return round(total / goal * 100)
+}
+
+// round keeps one decimal place, enough for label values and free of float
+// noise such as 0.30000000000000004.
+func round(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,55 @@
+package foodlog
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeUsesEstimatesForMissingNutrients(t *testing.T) {
+	entries := []models.FoodLogEntry{
+		{Servings: 2, Item: models.DailyItem{
+			Name:     "Soup",
+			Calories: "150",
+			Estimates: map[string]models.NutrientEstimate{
+				"protein": {Value: 8, Confidence: 0.8, Source: "history", Samples: 2},
+			},
+		}},
+		{Servings: 1, Item: models.DailyItem{Name: "Bread", Calories: "100", Protein: "3", Carbs: "20", Fat: "1"}},
+	}
+
+	summary := Summarize("2026-07-10", entries, models.DefaultNutritionGoals())
+
+	assert.Equal(t, 400.0, summary.Totals.Calories)
+	assert.Equal(t, 19.0, summary.Totals.Protein)
+	assert.Equal(t, 1, summary.Estimated)
+	assert.Equal(t, 1, summary.Incomplete, "carbs and fat are still missing from the soup")
+}
+
+func TestSummarizeScalesServingsAndComparesWithGoals(t *testing.T) {
+	entries := []models.FoodLogEntry{
+		{Servings: 2, Item: models.DailyItem{Name: "Eggs", Calories: "70", Protein: "6", Carbs: "0.4", Fat: "5"}},
+		{Servings: 0.5, Item: models.DailyItem{
+			Name: "Pasta",
+			Nutrition: models.Nutrition{
+				Calories: &models.NutrientAmount{Value: 600},
+				Protein:  &models.NutrientAmount{Value: 20},
+				Carbs:    &models.NutrientAmount{Value: 90},
+				Fat:      &models.NutrientAmount{Value: 15},
+			},
+		}},
+		{Servings: 1, Item: models.DailyItem{Name: "Mystery Soup", Calories: "100"}},
+	}
+	goals := models.NutritionGoals{Calories: 2000, Protein: 50, Carbs: 0, Fat: 78}
+
+	summary := Summarize("2026-07-10", entries, goals)
+
+	assert.Equal(t, "2026-07-10", summary.Date)
+	assert.Equal(t, 3, summary.Entries)
+	assert.Equal(t, models.NutrientTotals{Calories: 540, Protein: 22, Carbs: 45.8, Fat: 17.5}, summary.Totals)
+	assert.Equal(t, models.NutrientTotals{Calories: 1460, Protein: 28, Carbs: -45.8, Fat: 60.5}, summary.Remaining)
+	assert.Equal(t, models.NutrientTotals{Calories: 27, Protein: 44, Carbs: 0, Fat: 22.4}, summary.Percent)
+	assert.Equal(t, 1, summary.Incomplete, "the soup lists only calories")
+	assert.Equal(t, goals, summary.Goals)
+}
@@ -0,0 +1,156 @@
+package foodlog
+
+import (
+	"backend/internal/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReportDays is the length of a weekly report.
+const ReportDays = 7
+
+// TopCount bounds the most-eaten item and hall lists of a weekly report.
+const TopCount = 5
+
+// OnTargetTolerance is how far from the calorie goal a day may land and still
+// count as on target, when the user has not set a calorie range.
+const OnTargetTolerance = 0.10
+
+// WeekEnding returns the ReportDays dates ending on end, oldest first.
+func WeekEnding(end string) ([]string, error) {
+	last, err := time.Parse(time.DateOnly, end)
+	if err != nil {
+		return nil, fmt.Errorf("parse report end date: %w", err)
+	}
+	days := make([]string, ReportDays)
+	for i := range days {
+		days[i] = last.AddDate(0, 0, i-(ReportDays-1)).Format(time.DateOnly)
+	}
+	return days, nil
+}
+
+// Weekly rolls entries up over days (as returned by WeekEnding): per-day
+// totals against goals, averages over the logged days, and the items and
+// halls logged most. Entries outside days are ignored.
+func Weekly(days []string, entries []models.FoodLogEntry, goals models.NutritionGoals) models.WeeklyReport {
+	report := models.WeeklyReport{
+		Goals:    goals,
+		Days:     make([]models.DayReport, 0, len(days)),
+		TopItems: []models.ItemCount{},
+		TopHalls: []models.HallCount{},
+	}
+	if len(days) == 0 {
+		return report
+	}
+	report.From, report.To = days[0], days[len(days)-1]
+
+	byDate := make(map[string][]models.FoodLogEntry)
+	for _, entry := range entries {
+		byDate[entry.Date] = append(byDate[entry.Date], entry)
+	}
+
+	low, high, hasTarget := calorieBounds(goals)
+	var sum models.NutrientTotals
+	items := make(map[string]*models.ItemCount)
+	halls := make(map[string]*models.HallCount)
+	for _, date := range days {
+		dayEntries := byDate[date]
+		summary := Summarize(date, dayEntries, goals)
+		day := models.DayReport{Date: date, Entries: summary.Entries, Totals: summary.Totals, Status: models.DayNotLogged}
+		report.Incomplete += summary.Incomplete
+
+		if len(dayEntries) > 0 {
+			report.DaysLogged++
+			sum = add(sum, summary.Totals)
+			switch {
+			case !hasTarget:
+				day.Status = models.DayLogged
+			case summary.Totals.Calories > high:
+				day.Status = models.DayOver
+				report.DaysOver++
+			case summary.Totals.Calories < low:
+				day.Status = models.DayUnder
+				report.DaysUnder++
+			default:
+				day.Status = models.DayOnTarget
+				report.DaysOnTarget++
+			}
+		}
+		report.Days = append(report.Days, day)
+
+		for _, entry := range dayEntries {
+			name := strings.TrimSpace(entry.Item.Name)
+			key := strings.ToLower(name)
+			if items[key] == nil {
+				items[key] = &models.ItemCount{Name: name}
+			}
+			items[key].Entries++
+			items[key].Servings = round(items[key].Servings + entry.Servings)
+
+			if location := strings.TrimSpace(entry.Item.Location); location != "" {
+				if halls[location] == nil {
+					halls[location] = &models.HallCount{Location: location}
+				}
+				halls[location].Entries++
+			}
+		}
+	}
+
+	if report.DaysLogged > 0 {
+		logged := float64(report.DaysLogged)
+		report.Average = mapTotals(sum, func(total float64) float64 { return round(total / logged) })
+	}
+
+	for _, count := range items {
+		report.TopItems = append(report.TopItems, *count)
+	}
+	sort.Slice(report.TopItems, func(i, j int) bool {
+		a, b := report.TopItems[i], report.TopItems[j]
+		if a.Servings != b.Servings {
+			return a.Servings > b.Servings
+		}
+		if a.Entries != b.Entries {
+			return a.Entries > b.Entries
+		}
+		return a.Name < b.Name
+	})
+	report.TopItems = report.TopItems[:min(len(report.TopItems), TopCount)]
+
+	for _, count := range halls {
+		report.TopHalls = append(report.TopHalls, *count)
+	}
+	sort.Slice(report.TopHalls, func(i, j int) bool {
+		a, b := report.TopHalls[i], report.TopHalls[j]
+		if a.Entries != b.Entries {
+			return a.Entries > b.Entries
+		}
+		return a.Location < b.Location
+	})
+	report.TopHalls = report.TopHalls[:min(len(report.TopHalls), TopCount)]
+
+	return report
+}
+
+// calorieBounds is the on-target calorie band: the user's range when set,
+// otherwise the goal plus or minus OnTargetTolerance. ok is false when there
+// is no calorie goal at all.
+func calorieBounds(goals models.NutritionGoals) (low, high float64, ok bool) {
+	if band := goals.CaloriesRange; band != nil && band.Max > 0 {
+		return band.Min, band.Max, true
+	}
+	if goals.Calories <= 0 {
+		return 0, 0, false
+	}
+	return goals.Calories * (1 - OnTargetTolerance), goals.Calories * (1 + OnTargetTolerance), true
+}
+
+func add(a, b models.NutrientTotals) models.NutrientTotals {
+	return models.NutrientTotals{
+		Calories: a.Calories + b.Calories,
+		Protein:  a.Protein + b.Protein,
+		Carbs:    a.Carbs + b.Carbs,
+		Fat:      a.Fat + b.Fat,
+	}
+}
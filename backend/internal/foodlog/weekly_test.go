@@ -0,0 +1,78 @@
+package foodlog
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logged(date, name, location string, servings float64, calories string) models.FoodLogEntry {
+	return models.FoodLogEntry{Date: date, Servings: servings, Item: models.DailyItem{
+		Name: name, Location: location, Calories: calories, Protein: "10", Carbs: "20", Fat: "5",
+	}}
+}
+
+func TestWeekEnding(t *testing.T) {
+	days, err := WeekEnding("2026-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-02-23", "2026-02-24", "2026-02-25", "2026-02-26", "2026-02-27", "2026-02-28", "2026-03-01"}, days)
+
+	_, err = WeekEnding("March 1")
+	assert.Error(t, err)
+}
+
+func TestWeeklyRollsUpDaysItemsAndHalls(t *testing.T) {
+	days, err := WeekEnding("2026-07-12")
+	require.NoError(t, err)
+	entries := []models.FoodLogEntry{
+		logged("2026-07-06", "Pasta", "Allison", 1, "1000"),
+		logged("2026-07-06", "Salad", "Sargent", 1, "1000"),
+		logged("2026-07-07", "Pasta", "Allison", 2, "1000"),
+		logged("2026-07-07", "Cookie", "Allison", 1, "500"),
+		logged("2026-07-09", "Salad", "Sargent", 1, "500"),
+		logged("2026-07-09", "Mystery Soup", "Plex", 1, ""),
+		logged("2026-07-20", "Pasta", "Allison", 5, "1000"),
+	}
+	goals := models.NutritionGoals{Calories: 2000, Protein: 50, Carbs: 275, Fat: 78}
+
+	report := Weekly(days, entries, goals)
+
+	assert.Equal(t, "2026-07-06", report.From)
+	assert.Equal(t, "2026-07-12", report.To)
+	require.Len(t, report.Days, ReportDays)
+	assert.Equal(t, models.DayOnTarget, report.Days[0].Status)
+	assert.Equal(t, models.DayOver, report.Days[1].Status)
+	assert.Equal(t, 2500.0, report.Days[1].Totals.Calories)
+	assert.Equal(t, models.DayNotLogged, report.Days[2].Status)
+	assert.Equal(t, models.DayUnder, report.Days[3].Status)
+	assert.Equal(t, 3, report.DaysLogged)
+	assert.Equal(t, 1, report.DaysOver)
+	assert.Equal(t, 1, report.DaysUnder)
+	assert.Equal(t, 1, report.DaysOnTarget)
+	assert.Equal(t, models.NutrientTotals{Calories: 1666.7, Protein: 23.3, Carbs: 46.7, Fat: 11.7}, report.Average)
+	assert.Equal(t, 1, report.Incomplete)
+
+	require.NotEmpty(t, report.TopItems)
+	assert.Equal(t, models.ItemCount{Name: "Pasta", Entries: 2, Servings: 3}, report.TopItems[0], "entries outside the week are ignored")
+	assert.Equal(t, []models.HallCount{{Location: "Allison", Entries: 3}, {Location: "Sargent", Entries: 2}, {Location: "Plex", Entries: 1}}, report.TopHalls)
+}
+
+func TestWeeklyUsesTheCalorieRangeAndHandlesMissingGoals(t *testing.T) {
+	days, err := WeekEnding("2026-07-12")
+	require.NoError(t, err)
+	entries := []models.FoodLogEntry{logged("2026-07-12", "Pasta", "Allison", 1, "2300")}
+
+	ranged := models.NutritionGoals{Calories: 2000, CaloriesRange: &models.GoalRange{Min: 1800, Max: 2400}}
+	assert.Equal(t, models.DayOnTarget, Weekly(days, entries, ranged).Days[6].Status)
+
+	report := Weekly(days, entries, models.NutritionGoals{})
+	assert.Equal(t, models.DayLogged, report.Days[6].Status)
+	assert.Zero(t, report.DaysOver+report.DaysUnder+report.DaysOnTarget)
+
+	empty := Weekly(days, nil, models.DefaultNutritionGoals())
+	assert.Zero(t, empty.DaysLogged)
+	assert.Empty(t, empty.TopItems)
+	assert.Equal(t, models.NutrientTotals{}, empty.Average)
+}
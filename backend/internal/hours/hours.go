@@ -0,0 +1,254 @@
+// Package hours answers "is it open?" from the stored operating hours.
+//
+// The hours feed lists, per location, a week of days, each with zero or more
+// service blocks given as wall-clock start and end times. A block whose end is
+// not after its start runs past midnight into the next day, so the status at
+// any instant depends on the previous day's blocks as well as the current
+// day's. Blocks are therefore resolved to absolute times first, in the campus
+// timezone, and every question is answered against that timeline.
+package hours
+
+import (
+	"backend/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClosingSoonWindow is how close to closing an open location is reported as
+// closing soon.
+const ClosingSoonWindow = 30 * time.Minute
+
+// Meal boundaries used when a block cannot be matched to a menu period. They
+// match the windows the clients assume.
+const (
+	breakfastEndMinutes = 10*60 + 30
+	lunchEndMinutes     = 15*60 + 30
+)
+
+// Block is one service block resolved to absolute times.
+type Block struct {
+	// Date is the YYYY-MM-DD day the block is listed under, which is the day
+	// whose menu it serves even after midnight.
+	Date string
+	// Index is the block's position among that day's blocks, in start order.
+	Index int
+	Start time.Time
+	End   time.Time
+}
+
+// Status is a location's state at one instant.
+type Status struct {
+	Open bool
+	// ClosingSoon is set when the location is open and closes within
+	// ClosingSoonWindow.
+	ClosingSoon bool
+	// OpensAt is the next opening when closed, nil if none is known.
+	OpensAt *time.Time
+	// ClosesAt is when the location next closes while open. Back-to-back
+	// blocks (including ones that meet at midnight) count as one opening.
+	ClosesAt *time.Time
+	// HasHours is false when the hours feed has no entry for the day being
+	// asked about, so "closed" only means "not known to be open".
+	HasHours bool
+	// Block is the block being served while open.
+	Block *Block
+}
+
+// Blocks resolves a location's week to absolute service blocks in loc, sorted
+// by start. Days marked closed, unparseable dates and empty blocks are
+// skipped.
+func Blocks(week []models.DailyOperatingTimes, loc *time.Location) []Block {
+	var blocks []Block
+	for _, day := range week {
+		date, ok := parseDate(day.Date, loc)
+		if !ok || isClosed(day) {
+			continue
+		}
+
+		var dayBlocks []Block
+		for _, hours := range day.Hours {
+			// time.Date rather than Add so DST days keep their wall-clock hours.
+			start := time.Date(date.Year(), date.Month(), date.Day(), hours.StartHour, hours.StartMinutes, 0, 0, loc)
+			end := time.Date(date.Year(), date.Month(), date.Day(), hours.EndHour, hours.EndMinutes, 0, 0, loc)
+			if !end.After(start) {
+				// 22:00–02:00 closes the next morning.
+				end = end.AddDate(0, 0, 1)
+			}
+			if !end.After(start) {
+				continue
+			}
+			dayBlocks = append(dayBlocks, Block{Date: day.Date, Start: start, End: end})
+		}
+
+		sort.Slice(dayBlocks, func(i, j int) bool { return dayBlocks[i].Start.Before(dayBlocks[j].Start) })
+		for i := range dayBlocks {
+			dayBlocks[i].Index = i
+		}
+		blocks = append(blocks, dayBlocks...)
+	}
+
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].Start.Before(blocks[j].Start) })
+	return blocks
+}
+
+// StatusAt reports whether a location with the given week of hours is open at
+// the instant at, resolving wall-clock hours in at's location.
+func StatusAt(week []models.DailyOperatingTimes, at time.Time) Status {
+	blocks := Blocks(week, at.Location())
+	status := Status{HasHours: hasDay(week, at.Format(time.DateOnly))}
+
+	for i := range blocks {
+		block := blocks[i]
+		if block.Start.After(at) {
+			if !status.Open && status.OpensAt == nil {
+				opensAt := block.Start
+				status.OpensAt = &opensAt
+			}
+			break
+		}
+		if at.Before(block.End) {
+			status.Open = true
+			status.Block = &block
+			// A block spilling over from yesterday counts as known hours.
+			status.HasHours = true
+		}
+	}
+
+	if status.Open {
+		closesAt := closingTime(blocks, at)
+		status.ClosesAt = &closesAt
+		status.ClosingSoon = closesAt.Sub(at) <= ClosingSoonWindow
+	}
+	return status
+}
+
+// closingTime returns when the opening containing at ends, following blocks
+// that start before (or exactly when) the previous one ends.
+func closingTime(blocks []Block, at time.Time) time.Time {
+	var end time.Time
+	for _, block := range blocks {
+		if !block.End.After(at) {
+			continue
+		}
+		if block.Start.After(at) && (end.IsZero() || block.Start.After(end)) {
+			break
+		}
+		if block.End.After(end) {
+			end = block.End
+		}
+	}
+	return end
+}
+
+// MealFor names the meal a block serves. offered lists the meal periods on the
+// block's menu (in any order); when there is one per block they are matched up
+// in serving order, which copes with halls serving "Brunch" or skipping a
+// meal. Otherwise the meal follows the block's start on the wall clock,
+// preferring an offered meal when the clock's choice is not on the menu.
+func MealFor(block Block, blockCount int, offered []string) string {
+	meals := distinctMeals(offered)
+	if len(meals) > 0 && len(meals) == blockCount && block.Index < len(meals) {
+		return meals[block.Index]
+	}
+
+	clock := clockMeal(block.Start.Hour()*60 + block.Start.Minute())
+	if len(meals) == 0 {
+		return clock
+	}
+	best := meals[0]
+	for _, meal := range meals {
+		if strings.EqualFold(meal, clock) {
+			return meal
+		}
+		if rank := mealRank(meal); rank >= 0 && rank <= mealRank(clock) {
+			best = meal
+		}
+	}
+	return best
+}
+
+// BlocksOn counts the blocks listed under date.
+func BlocksOn(blocks []Block, date string) int {
+	count := 0
+	for _, block := range blocks {
+		if block.Date == date {
+			count++
+		}
+	}
+	return count
+}
+
+// distinctMeals returns the distinct meal names in serving order. Names with
+// no known place in the day sort last, in the order given.
+func distinctMeals(offered []string) []string {
+	seen := make(map[string]bool)
+	var meals []string
+	for _, meal := range offered {
+		meal = strings.TrimSpace(meal)
+		key := strings.ToLower(meal)
+		if meal == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		meals = append(meals, meal)
+	}
+	sort.SliceStable(meals, func(i, j int) bool {
+		return servingRank(meals[i]) < servingRank(meals[j])
+	})
+	return meals
+}
+
+// servingRank orders meals through the day; brunch sits with lunch.
+func servingRank(meal string) int {
+	if strings.EqualFold(meal, "brunch") {
+		return 1
+	}
+	if rank := mealRank(meal); rank >= 0 {
+		return rank
+	}
+	return 3
+}
+
+func mealRank(meal string) int {
+	switch strings.ToLower(strings.TrimSpace(meal)) {
+	case "breakfast":
+		return 0
+	case "lunch":
+		return 1
+	case "dinner":
+		return 2
+	default:
+		return -1
+	}
+}
+
+// clockMeal maps a wall-clock minute-of-day to the meal being served.
+func clockMeal(minutes int) string {
+	switch {
+	case minutes < breakfastEndMinutes:
+		return "Breakfast"
+	case minutes < lunchEndMinutes:
+		return "Lunch"
+	default:
+		return "Dinner"
+	}
+}
+
+func parseDate(date string, loc *time.Location) (time.Time, bool) {
+	parsed, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(date), loc)
+	return parsed, err == nil
+}
+
+func isClosed(day models.DailyOperatingTimes) bool {
+	return strings.EqualFold(strings.TrimSpace(day.Status), "closed")
+}
+
+func hasDay(week []models.DailyOperatingTimes, date string) bool {
+	for _, day := range week {
+		if strings.TrimSpace(day.Date) == date {
+			return true
+		}
+	}
+	return false
+}
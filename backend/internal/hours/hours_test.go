@@ -0,0 +1,124 @@
+package hours
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var chicago = func() *time.Location {
+	loc, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}()
+
+func at(value string) time.Time {
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, chicago)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func block(startHour, startMinutes, endHour, endMinutes int) models.HourlyTimes {
+	return models.HourlyTimes{StartHour: startHour, StartMinutes: startMinutes, EndHour: endHour, EndMinutes: endMinutes}
+}
+
+// hallWeek is a hall serving breakfast, lunch and dinner on July 10 and a
+// late-night block on July 11 that runs past midnight.
+var hallWeek = []models.DailyOperatingTimes{
+	{Date: "2026-07-10", Status: "open", Hours: []models.HourlyTimes{block(17, 0, 20, 0), block(7, 0, 10, 30), block(11, 0, 14, 0)}},
+	{Date: "2026-07-11", Status: "open", Hours: []models.HourlyTimes{block(22, 0, 2, 0)}},
+	{Date: "2026-07-12", Status: "closed", Hours: []models.HourlyTimes{block(7, 0, 10, 0)}},
+}
+
+func TestStatusAtInsideAndBetweenBlocks(t *testing.T) {
+	status := StatusAt(hallWeek, at("2026-07-10 12:00"))
+	assert.True(t, status.Open)
+	assert.True(t, status.HasHours)
+	require.NotNil(t, status.ClosesAt)
+	assert.Equal(t, at("2026-07-10 14:00"), *status.ClosesAt)
+	assert.False(t, status.ClosingSoon)
+	require.NotNil(t, status.Block)
+	assert.Equal(t, 1, status.Block.Index, "blocks are indexed in start order, not feed order")
+
+	status = StatusAt(hallWeek, at("2026-07-10 13:45"))
+	assert.True(t, status.ClosingSoon)
+
+	status = StatusAt(hallWeek, at("2026-07-10 15:00"))
+	assert.False(t, status.Open)
+	assert.True(t, status.HasHours)
+	require.NotNil(t, status.OpensAt)
+	assert.Equal(t, at("2026-07-10 17:00"), *status.OpensAt)
+	assert.Nil(t, status.ClosesAt)
+
+	status = StatusAt(hallWeek, at("2026-07-10 14:00"))
+	assert.False(t, status.Open, "blocks end exclusively")
+}
+
+func TestStatusAtFollowsBlocksAcrossMidnight(t *testing.T) {
+	status := StatusAt(hallWeek, at("2026-07-11 23:30"))
+	assert.True(t, status.Open)
+	require.NotNil(t, status.ClosesAt)
+	assert.Equal(t, at("2026-07-12 02:00"), *status.ClosesAt)
+
+	// July 12 is marked closed, but the previous night's block is still open.
+	status = StatusAt(hallWeek, at("2026-07-12 01:00"))
+	assert.True(t, status.Open)
+	assert.True(t, status.HasHours)
+	require.NotNil(t, status.Block)
+	assert.Equal(t, "2026-07-11", status.Block.Date)
+
+	status = StatusAt(hallWeek, at("2026-07-12 08:00"))
+	assert.False(t, status.Open, "closed days have no blocks")
+	assert.Nil(t, status.OpensAt)
+}
+
+func TestStatusAtMergesBlocksThatMeet(t *testing.T) {
+	week := []models.DailyOperatingTimes{
+		{Date: "2026-07-10", Hours: []models.HourlyTimes{block(20, 0, 24, 0)}},
+		{Date: "2026-07-11", Hours: []models.HourlyTimes{block(0, 0, 1, 30)}},
+	}
+
+	status := StatusAt(week, at("2026-07-10 23:50"))
+	assert.True(t, status.Open)
+	require.NotNil(t, status.ClosesAt)
+	assert.Equal(t, at("2026-07-11 01:30"), *status.ClosesAt)
+	assert.False(t, status.ClosingSoon)
+}
+
+func TestStatusAtWithoutHoursForTheDay(t *testing.T) {
+	status := StatusAt(hallWeek, at("2026-07-09 12:00"))
+	assert.False(t, status.Open)
+	assert.False(t, status.HasHours)
+	require.NotNil(t, status.OpensAt)
+	assert.Equal(t, at("2026-07-10 07:00"), *status.OpensAt)
+
+	status = StatusAt(nil, at("2026-07-09 12:00"))
+	assert.False(t, status.Open)
+	assert.False(t, status.HasHours)
+	assert.Nil(t, status.OpensAt)
+}
+
+func TestMealFor(t *testing.T) {
+	blocks := Blocks(hallWeek, chicago)
+	require.Len(t, blocks, 4)
+	count := BlocksOn(blocks, "2026-07-10")
+	assert.Equal(t, 3, count)
+
+	offered := []string{"Dinner", "Breakfast", "Lunch", "Dinner"}
+	assert.Equal(t, "Breakfast", MealFor(blocks[0], count, offered))
+	assert.Equal(t, "Lunch", MealFor(blocks[1], count, offered))
+	assert.Equal(t, "Dinner", MealFor(blocks[2], count, offered))
+
+	// Brunch replaces breakfast and lunch: blocks no longer line up with meals,
+	// so the clock decides, settling on an offered meal.
+	assert.Equal(t, "Dinner", MealFor(blocks[2], count, []string{"Brunch", "Dinner"}))
+	assert.Equal(t, "Lunch", MealFor(blocks[1], count, nil), "no menu falls back to the clock")
+}
@@ -4,6 +4,7 @@ import (
 	"backend/internal/auth"
 	"backend/internal/db"
 	"backend/internal/models"
+	"backend/internal/subscription"
 	"fmt"
 	"html"
 	"log"
@@ -11,6 +12,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"crypto/hmac"
 	"crypto/sha256"
@@ -95,7 +97,15 @@ func SendEmails() error {
 		return fmt.Errorf("no mail provider configured")
 	}
 
-	preferencesData, err := db.GetMailingList()
+	// Every recipient's subscription rules are matched against one copy of
+	// today's menu.
+	today := time.Now().Format("2006-01-02")
+	menu, err := subscription.LoadMenu(models.MenuFilter{From: today, To: today})
+	if err != nil {
+		return fmt.Errorf("load today's menu: %w", err)
+	}
+
+	preferencesData, err := db.GetMailingList(menu)
 
 	if err != nil {
 		return fmt.Errorf("select mailing preferences: %w", err)
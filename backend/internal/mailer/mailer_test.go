@@ -93,3 +93,29 @@ func TestFormatPreferencesEscapesUserFacingValues(t *testing.T) {
 	assert.Contains(t, content, "Hall &amp; Cafe")
 	assert.Contains(t, content, "a=1&amp;b=2")
 }
+
+func TestFormatWeeklyReport(t *testing.T) {
+	report := models.WeeklyReport{
+		From: "2026-07-06",
+		To:   "2026-07-12",
+		Days: []models.DayReport{
+			{Date: "2026-07-06", Entries: 2, Totals: models.NutrientTotals{Calories: 2450}, Status: models.DayOver},
+			{Date: "2026-07-07", Status: models.DayNotLogged},
+		},
+		DaysLogged: 1,
+		DaysOver:   1,
+		Goals:      models.NutritionGoals{Calories: 2000},
+		Average:    models.NutrientTotals{Calories: 2450},
+		TopItems:   []models.ItemCount{{Name: "Mac & Cheese", Entries: 2, Servings: 1.5}},
+		TopHalls:   []models.HallCount{{Location: "Allison", Entries: 2}},
+	}
+
+	content, err := mailer.FormatWeeklyReport(report, "https://example.test/?list=weekly&a=1")
+	require.NoError(t, err)
+	assert.Contains(t, content, "1 of 2 days logged")
+	assert.Contains(t, content, "over target")
+	assert.Contains(t, content, "Mac &amp; Cheese")
+	assert.Contains(t, content, "1.5 servings")
+	assert.Contains(t, content, "Allison")
+	assert.Contains(t, content, "list=weekly&amp;a=1")
+}
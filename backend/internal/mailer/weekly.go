@@ -0,0 +1,182 @@
+package mailer
+
+import (
+	"backend/internal/auth"
+	"backend/internal/db"
+	"backend/internal/foodlog"
+	"backend/internal/models"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// SendWeeklyReports emails every opted-in user the food log report for the
+// seven days ending on end (YYYY-MM-DD). Users who logged nothing that week are
+// skipped rather than sent an empty report. Like SendEmails, one failing
+// recipient does not stop the run.
+func SendWeeklyReports(end string) error {
+	if !providerConfigured {
+		log.Println("weekly report mailing skipped: no provider configured")
+		return fmt.Errorf("no mail provider configured")
+	}
+
+	days, err := foodlog.WeekEnding(end)
+	if err != nil {
+		return err
+	}
+
+	userIDs, err := db.GetWeeklyReportList()
+	if err != nil {
+		return fmt.Errorf("select weekly report list: %w", err)
+	}
+
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		return fmt.Errorf("BASE_URL is required")
+	}
+
+	from := "NUFood <nufoodfinder11@gmail.com>"
+	subject := "Your Week in Food"
+
+	var sent, skipped, failed int
+	for _, userID := range userIDs {
+		report, err := weeklyReportFor(userID, days)
+		if err != nil {
+			log.Printf("weekly report: skip user %s: build report: %v", userID, err)
+			failed++
+			continue
+		}
+		if report.DaysLogged == 0 {
+			skipped++
+			continue
+		}
+
+		email, err := auth.GetEmailFromUID(userID)
+		if err != nil {
+			log.Printf("weekly report: skip user %s: resolve email: %v", userID, err)
+			failed++
+			continue
+		}
+
+		unsubscribeToken, err := GenerateUnsubscribeToken(userID)
+		if err != nil {
+			return fmt.Errorf("weekly report: generate unsubscribe token: %w", err)
+		}
+		unsubscribeURL := fmt.Sprintf("%s/api/unsubscribe?user=%s&token=%s&list=weekly",
+			baseURL, url.QueryEscape(userID), url.QueryEscape(unsubscribeToken))
+
+		htmlContent, err := FormatWeeklyReport(report, unsubscribeURL)
+		if err != nil {
+			log.Printf("weekly report: skip user %s: format report: %v", userID, err)
+			failed++
+			continue
+		}
+
+		plainText := fmt.Sprintf("Your food log for %s to %s", report.From, report.To)
+		if err := activeSender.Send(from, email, subject, plainText, htmlContent); err != nil {
+			log.Printf("weekly report: skip user %s: send: %v", userID, err)
+			failed++
+			continue
+		}
+		sent++
+	}
+
+	log.Printf("weekly report: complete (%d sent, %d with nothing logged, %d failed of %d)", sent, skipped, failed, len(userIDs))
+	if failed > 0 {
+		return fmt.Errorf("weekly report: %d of %d recipients failed (see logs)", failed, len(userIDs))
+	}
+	return nil
+}
+
+// weeklyReportFor reads a user's goals and food log and rolls up days.
+func weeklyReportFor(userID string, days []string) (models.WeeklyReport, error) {
+	goals, err := db.GetNutritionGoals(userID)
+	if err == db.NoUserGoalsInDB {
+		goals = models.DefaultNutritionGoals()
+	} else if err != nil {
+		return models.WeeklyReport{}, err
+	}
+
+	entries, err := db.GetFoodLogRange(userID, days[0], days[len(days)-1])
+	if err != nil {
+		return models.WeeklyReport{}, err
+	}
+	return foodlog.Weekly(days, entries, goals), nil
+}
+
+// FormatWeeklyReport renders a weekly report as an HTML email.
+func FormatWeeklyReport(report models.WeeklyReport, unsubscribeURL string) (string, error) {
+	statusLabels := map[string]string{
+		models.DayNotLogged: "not logged",
+		models.DayUnder:     "under target",
+		models.DayOnTarget:  "on target",
+		models.DayOver:      "over target",
+		models.DayLogged:    "logged",
+	}
+
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Your Week in Food</title>
+  <style>
+    body { font-family: Arial, sans-serif; background-color: #f6f6f6; margin: 0; padding: 20px; color: #333; }
+    .container { max-width: 600px; margin: 0 auto; background-color: #fff; padding: 20px; border-radius: 8px; box-shadow: 0 2px 5px rgba(0,0,0,0.1); }
+    h1 { text-align: center; color: #444; }
+    h2 { color: #007BFF; border-bottom: 2px solid #007BFF; padding-bottom: 5px; }
+    table { width: 100%; border-collapse: collapse; }
+    td, th { padding: 6px 4px; border-bottom: 1px solid #eaeaea; text-align: left; }
+    ul { list-style: none; padding: 0; }
+    li { padding: 6px 0; border-bottom: 1px solid #eaeaea; }
+    .muted { color: #777; }
+  </style>
+</head>
+<body>
+  <div class="container">
+    <h1>Your Week in Food</h1>
+`)
+	fmt.Fprintf(&b, "<p class=\"muted\">%s to %s: %d of %d days logged, %d on target, %d over, %d under.</p>\n",
+		html.EscapeString(report.From), html.EscapeString(report.To), report.DaysLogged, len(report.Days),
+		report.DaysOnTarget, report.DaysOver, report.DaysUnder)
+
+	b.WriteString("<h2>Daily totals</h2>\n<table>\n<tr><th>Day</th><th>Calories</th><th>Protein</th><th>Carbs</th><th>Fat</th><th></th></tr>\n")
+	for _, day := range report.Days {
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%g</td><td>%gg</td><td>%gg</td><td>%gg</td><td class=\"muted\">%s</td></tr>\n",
+			html.EscapeString(day.Date), day.Totals.Calories, day.Totals.Protein, day.Totals.Carbs, day.Totals.Fat,
+			html.EscapeString(statusLabels[day.Status]))
+	}
+	b.WriteString("</table>\n")
+	fmt.Fprintf(&b, "<p>Daily average: %g calories, %gg protein, %gg carbs, %gg fat (goal %g calories).</p>\n",
+		report.Average.Calories, report.Average.Protein, report.Average.Carbs, report.Average.Fat, report.Goals.Calories)
+
+	if len(report.TopItems) > 0 {
+		b.WriteString("<h2>Most eaten</h2>\n<ul>\n")
+		for _, item := range report.TopItems {
+			fmt.Fprintf(&b, "<li><strong>%s</strong> <span class=\"muted\">%g servings</span></li>\n", html.EscapeString(item.Name), item.Servings)
+		}
+		b.WriteString("</ul>\n")
+	}
+	if len(report.TopHalls) > 0 {
+		b.WriteString("<h2>Where you ate</h2>\n<ul>\n")
+		for _, hall := range report.TopHalls {
+			fmt.Fprintf(&b, "<li><strong>%s</strong> <span class=\"muted\">%d entries</span></li>\n", html.EscapeString(hall.Location), hall.Entries)
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	b.WriteString(`  <div style="margin-top: 30px; padding-top: 20px; border-top: 1px solid #eaeaea; text-align: center; color: #999; font-size: 12px;">
+    <p>If you no longer wish to receive weekly reports, <a href="`)
+	b.WriteString(html.EscapeString(unsubscribeURL))
+	b.WriteString(`">click here to unsubscribe</a>.</p>
+  </div>
+  </div>
+</body>
+</html>`)
+
+	return b.String(), nil
+}
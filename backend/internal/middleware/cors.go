@@ -55,7 +55,7 @@ func CorsMiddleware(next http.Handler) http.Handler {
 		if allowedOrigins[origin] {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 		}
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		w.Header().Set("Access-Control-Expose-Headers", "Authorization, Content-Type, Content-Length")
 
@@ -99,6 +99,20 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware authenticates requests that carry an Authorization
+// header exactly like AuthMiddleware, and passes anonymous requests through
+// without a user ID, for public endpoints that personalize signed-in responses.
+func OptionalAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	authenticated := AuthMiddleware(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next(w, r)
+			return
+		}
+		authenticated(w, r)
+	}
+}
+
 // AdminMiddleware verifies the request has admin privileges
 func AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
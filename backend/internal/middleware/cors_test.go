@@ -51,3 +51,22 @@ func TestScrapeMiddlewareRequiresConfiguration(t *testing.T) {
 
 	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
 }
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	handler := middleware.OptionalAuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		_, signedIn := r.Context().Value(middleware.UserIDKey).(string)
+		assert.False(t, signedIn)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+	response := httptest.NewRecorder()
+	handler(response, request)
+	assert.Equal(t, http.StatusNoContent, response.Code, "anonymous requests pass through")
+
+	request = httptest.NewRequest(http.MethodGet, "/api/menu", nil)
+	request.Header.Set("Authorization", "Token not-a-bearer")
+	response = httptest.NewRecorder()
+	handler(response, request)
+	assert.Equal(t, http.StatusUnauthorized, response.Code, "a bad token is rejected, not ignored")
+}
@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"backend/internal/store"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// cachedPaths are the public endpoints whose responses depend only on the
+// memory store, so one encoded body per store version can be shared by every
+// caller. Anything time- or user-dependent must stay off this list.
+var cachedPaths = map[string]bool{
+	"/api/generalData":    true,
+	"/api/operatingTimes": true,
+}
+
+// maxCachedResponses bounds how many distinct path+query bodies are kept for a
+// single store version, so arbitrary query strings cannot grow the cache.
+const maxCachedResponses = 64
+
+// cachedResponse is one fully encoded response. ready is closed once the body
+// and its compressed variants are built; until then concurrent requests for
+// the same key wait instead of rebuilding it. status stays 0 if the build
+// never finished.
+type cachedResponse struct {
+	ready    chan struct{}
+	status   int
+	header   http.Header
+	identity []byte
+	gzip     []byte
+	brotli   []byte
+}
+
+type responseCache struct {
+	mu      sync.Mutex
+	version uint64
+	entries map[string]*cachedResponse
+}
+
+var responses = &responseCache{entries: make(map[string]*cachedResponse)}
+
+// entry returns the cached response for key at version, and whether the
+// caller is responsible for building it. Entries from older versions are
+// dropped as soon as a newer version is seen.
+func (c *responseCache) entry(key string, version uint64) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version || len(c.entries) >= maxCachedResponses {
+		c.version = version
+		c.entries = make(map[string]*cachedResponse)
+	}
+	if cached, ok := c.entries[key]; ok {
+		return cached, false
+	}
+	cached := &cachedResponse{ready: make(chan struct{})}
+	c.entries[key] = cached
+	return cached, true
+}
+
+// discard forgets a response that turned out not to be cacheable.
+func (c *responseCache) discard(key string, cached *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[key] == cached {
+		delete(c.entries, key)
+	}
+}
+
+// ResponseCacheMiddleware serves the store-backed public endpoints from bodies
+// encoded (and gzip/brotli compressed) once per store version, choosing the
+// encoding from Accept-Encoding. It also answers If-None-Match from the cached
+// ETag without calling the handler at all.
+func ResponseCacheMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !cachedPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Take the version before the handler reads the store: if the store
+		// changes mid-build, the entry is merely rebuilt on the next request.
+		version, _ := store.Version()
+		key := r.URL.Path + "?" + r.URL.RawQuery
+
+		cached, build := responses.entry(key, version)
+		if build {
+			func() {
+				// Errors are passed through once and never cached.
+				defer func() {
+					if cached.status != http.StatusOK {
+						responses.discard(key, cached)
+					}
+				}()
+				buildResponse(cached, next, r)
+			}()
+		} else {
+			select {
+			case <-cached.ready:
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if cached.status == 0 {
+			// The build this request waited on panicked; serve it directly.
+			next.ServeHTTP(w, r)
+			return
+		}
+		writeCachedResponse(w, r, cached)
+	})
+}
+
+// buildResponse runs the handler into a buffer and prepares every encoding.
+func buildResponse(cached *cachedResponse, next http.Handler, r *http.Request) {
+	defer close(cached.ready)
+
+	// Strip conditional headers so the handler always produces a full body.
+	inner := r.Clone(r.Context())
+	inner.Header.Del("If-None-Match")
+	inner.Header.Del("If-Modified-Since")
+
+	buffered := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+	next.ServeHTTP(buffered, inner)
+
+	cached.header = buffered.header
+	cached.identity = buffered.body.Bytes()
+	cached.status = buffered.status
+	if cached.status != http.StatusOK {
+		return
+	}
+
+	var gzipped bytes.Buffer
+	gzipWriter, _ := gzip.NewWriterLevel(&gzipped, gzip.BestCompression)
+	if _, err := gzipWriter.Write(cached.identity); err == nil && gzipWriter.Close() == nil {
+		cached.gzip = gzipped.Bytes()
+	}
+
+	var brotlied bytes.Buffer
+	brotliWriter := brotli.NewWriterLevel(&brotlied, brotli.DefaultCompression)
+	if _, err := brotliWriter.Write(cached.identity); err == nil && brotliWriter.Close() == nil {
+		cached.brotli = brotlied.Bytes()
+	}
+}
+
+// writeCachedResponse replays a cached response in the best encoding the
+// client accepts, or a 304 when the client already holds it.
+func writeCachedResponse(w http.ResponseWriter, r *http.Request, cached *cachedResponse) {
+	for name, values := range cached.header {
+		if name == "Content-Length" {
+			continue
+		}
+		w.Header()[name] = append([]string(nil), values...)
+	}
+
+	if cached.status != http.StatusOK {
+		w.WriteHeader(cached.status)
+		w.Write(cached.identity)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	if etag := cached.header.Get("ETag"); etag != "" && ETagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body := cached.identity
+	switch negotiateEncoding(r.Header.Get("Accept-Encoding"), cached) {
+	case "br":
+		w.Header().Set("Content-Encoding", "br")
+		body = cached.brotli
+	case "gzip":
+		w.Header().Set("Content-Encoding", "gzip")
+		body = cached.gzip
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// negotiateEncoding picks br, gzip or identity ("") from an Accept-Encoding
+// header. Higher q-values win; on a tie brotli is preferred for its size, and
+// an encoding listed with q=0 is never used.
+func negotiateEncoding(header string, cached *cachedResponse) string {
+	available := map[string]bool{"br": cached.brotli != nil, "gzip": cached.gzip != nil}
+
+	best, bestQ := "", 0.0
+	wildcard := -1.0
+	listed := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		listed[name] = q
+	}
+
+	for _, encoding := range []string{"br", "gzip"} {
+		q, ok := listed[encoding]
+		if !ok {
+			q = max(wildcard, 0)
+		}
+		if available[encoding] && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// bufferedResponse is an http.ResponseWriter that keeps the whole response in
+// memory so it can be cached and compressed before anything is sent.
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status = status
+	b.wroteHeader = true
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// ETagMatches applies the weak comparison If-None-Match calls for: a W/ prefix
+// on either side is ignored, and "*" matches anything.
+func ETagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
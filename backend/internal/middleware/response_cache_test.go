@@ -0,0 +1,94 @@
+package middleware_test
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/store"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCacheMiddleware(t *testing.T) {
+	store.InitStore()
+	t.Cleanup(store.Clear)
+	store.Set([]models.AllDataItem{{Name: "Pasta"}})
+
+	calls := 0
+	payload := strings.Repeat(`{"name":"Pasta"}`, 100)
+	handler := middleware.ResponseCacheMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, payload)
+	}))
+
+	get := func(acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/api/generalData", nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+		request.Header.Set("If-None-Match", ifNoneMatch)
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		return response
+	}
+
+	response := get("gzip, br", "")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "br", response.Header().Get("Content-Encoding"))
+	body, err := io.ReadAll(brotli.NewReader(response.Body))
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(body))
+
+	response = get("gzip;q=1, br;q=0.5", "")
+	assert.Equal(t, "gzip", response.Header().Get("Content-Encoding"))
+	gzipReader, err := gzip.NewReader(response.Body)
+	require.NoError(t, err)
+	body, err = io.ReadAll(gzipReader)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(body))
+
+	response = get("br;q=0, gzip;q=0", "")
+	assert.Empty(t, response.Header().Get("Content-Encoding"))
+	assert.Equal(t, payload, response.Body.String())
+
+	response = get("", `W/"v1"`)
+	assert.Equal(t, http.StatusNotModified, response.Code)
+	assert.Empty(t, response.Body.String())
+	assert.Equal(t, 1, calls, "one store version must be encoded only once")
+
+	store.Set([]models.AllDataItem{{Name: "Ramen"}})
+	get("", "")
+	assert.Equal(t, 2, calls, "a new store version must rebuild the body")
+}
+
+func TestResponseCacheMiddlewareSkipsUncachedRequests(t *testing.T) {
+	store.InitStore()
+	t.Cleanup(store.Clear)
+
+	calls := 0
+	status := http.StatusInternalServerError
+	handler := middleware.ResponseCacheMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+
+	for range 2 {
+		response := httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/operatingTimes", nil))
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	}
+	assert.Equal(t, 2, calls, "errors must not be cached")
+
+	status = http.StatusOK
+	for range 2 {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/menu", nil))
+	}
+	assert.Equal(t, 4, calls, "paths outside the cached set must always reach the handler")
+}
@@ -0,0 +1,35 @@
+package models
+
+// BodyStats are the inputs to the nutrition goal calculator. Height and weight
+// are metric.
+type BodyStats struct {
+	Age           int     `json:"age"`
+	Sex           string  `json:"sex,omitempty"` // "male" or "female"; anything else uses the midpoint
+	HeightCm      float64 `json:"heightCm"`
+	WeightKg      float64 `json:"weightKg"`
+	ActivityLevel string  `json:"activityLevel"`       // sedentary, light, moderate, active or veryActive
+	Objective     string  `json:"objective,omitempty"` // cut, maintain (default) or bulk
+}
+
+// GoalCalculationRequest asks for goals calculated from body stats. Nothing
+// is stored unless the user opts in: Save replaces their nutrition goals with
+// the result, and RememberStats keeps the inputs themselves.
+type GoalCalculationRequest struct {
+	BodyStats
+	Save          bool `json:"save,omitempty"`
+	RememberStats bool `json:"rememberStats,omitempty"`
+}
+
+// GoalCalculation is the calculator's result.
+type GoalCalculation struct {
+	// Stats are the inputs after normalization.
+	Stats BodyStats `json:"stats"`
+	// BMR is the resting energy need in kcal/day (Mifflin-St Jeor).
+	BMR float64 `json:"bmr"`
+	// TDEE is BMR scaled by activity level, in kcal/day.
+	TDEE  float64        `json:"tdee"`
+	Goals NutritionGoals `json:"goals"`
+	// Saved and StatsSaved report what was stored for the user.
+	Saved      bool `json:"saved"`
+	StatsSaved bool `json:"statsSaved"`
+}
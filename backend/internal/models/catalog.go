@@ -0,0 +1,33 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CatalogItem is one canonical menu item. Every spelling the menu has used
+// for it is an alias, and the upstream item IDs seen with it are recorded so a
+// renamed item keeps its identity. Menu rows and favorites refer to it by ID.
+type CatalogItem struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`    // the first spelling seen
+	Aliases     []string `json:"aliases"` // every spelling seen, the name included
+	UpstreamIDs []string `json:"upstreamIds"`
+}
+
+// CatalogMergeRequest folds the From catalog items into Into: their aliases,
+// upstream IDs, menu rows and favorites all move to Into.
+type CatalogMergeRequest struct {
+	Into uint   `json:"into"`
+	From []uint `json:"from"`
+}
+
+// CatalogKey normalizes an item name for catalog matching: lowercase words of
+// letters and digits separated by single spaces, so "Chicken Tikka Masala "
+// and "chicken tikka  masala" share an entry.
+func CatalogKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
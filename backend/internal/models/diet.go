@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits on a dietary profile, so a stored profile stays small.
+const (
+	maxDietTags      = 30
+	maxDietTagLength = 50
+)
+
+// Reasons an item conflicts with a dietary profile.
+const (
+	ConflictContains    = "contains"     // the item carries an avoided tag
+	ConflictMayContain  = "may_contain"  // the item carries the "may contain" variant, e.g. "Sesame*"
+	ConflictMissingDiet = "missing_diet" // the item lacks a required diet tag
+	ConflictIngredient  = "ingredient"   // the item lists an avoided ingredient
+)
+
+// DietaryProfile is a user's standing food restrictions, matched against the
+// upstream tags in DailyItem.Filters and the parsed ingredient statement.
+// Avoiding a tag also avoids its "may contain" variant.
+type DietaryProfile struct {
+	Avoid   []string `json:"avoid"`   // allergen tags, e.g. "Milk", "Sesame"
+	Require []string `json:"require"` // diet tags every item must carry, e.g. "Vegan", "Halal"
+	// AvoidIngredients are matched against ingredients and sub-ingredients
+	// with IngredientMatches, e.g. "cilantro" or "mushroom".
+	AvoidIngredients []string `json:"avoidIngredients"`
+}
+
+// DietConflict names one reason an item is unsafe for a profile.
+type DietConflict struct {
+	Tag    string `json:"tag"`    // the profile tag or ingredient, e.g. "Sesame", "Vegan" or "cilantro"
+	Reason string `json:"reason"` // ConflictContains, ConflictMayContain, ConflictMissingDiet or ConflictIngredient
+}
+
+// Empty reports whether the profile restricts nothing.
+func (p DietaryProfile) Empty() bool {
+	return len(p.Avoid) == 0 && len(p.Require) == 0 && len(p.AvoidIngredients) == 0
+}
+
+// Normalize trims the tags, drops a trailing "*" from avoided tags (the may
+// contain variant is always avoided too) and removes blanks and
+// case-insensitive duplicates. It reports an error when a list is too long or
+// a tag too long to be a real filter name.
+func (p DietaryProfile) Normalize() (DietaryProfile, error) {
+	avoid, err := normalizeDietTags("avoid", p.Avoid, true)
+	if err != nil {
+		return DietaryProfile{}, err
+	}
+	require, err := normalizeDietTags("require", p.Require, false)
+	if err != nil {
+		return DietaryProfile{}, err
+	}
+	avoidIngredients, err := normalizeDietTags("avoidIngredients", p.AvoidIngredients, false)
+	if err != nil {
+		return DietaryProfile{}, err
+	}
+	return DietaryProfile{Avoid: avoid, Require: require, AvoidIngredients: avoidIngredients}, nil
+}
+
+func normalizeDietTags(field string, tags []string, stripMayContain bool) ([]string, error) {
+	if len(tags) > maxDietTags {
+		return nil, fmt.Errorf("%s must list at most %d tags", field, maxDietTags)
+	}
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if stripMayContain {
+			tag = strings.TrimSpace(strings.TrimSuffix(tag, "*"))
+		}
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		if len(tag) > maxDietTagLength {
+			return nil, fmt.Errorf("%s tag %q is longer than %d characters", field, tag, maxDietTagLength)
+		}
+		seen[strings.ToLower(tag)] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
+
+// Conflicts lists every way item breaks the profile: avoided tags, then
+// missing diets, then avoided ingredients. Tags match case-insensitively; nil
+// means the item is safe.
+func (p DietaryProfile) Conflicts(item DailyItem) []DietConflict {
+	var conflicts []DietConflict
+	for _, tag := range p.Avoid {
+		tag = strings.TrimSuffix(strings.TrimSpace(tag), "*")
+		if tag == "" {
+			continue
+		}
+		for _, filter := range item.Filters {
+			filter = strings.TrimSpace(filter)
+			name, mayContain := strings.CutSuffix(filter, "*")
+			if !strings.EqualFold(strings.TrimSpace(name), tag) {
+				continue
+			}
+			reason := ConflictContains
+			if mayContain {
+				reason = ConflictMayContain
+			}
+			conflicts = append(conflicts, DietConflict{Tag: tag, Reason: reason})
+			break
+		}
+	}
+	for _, tag := range p.Require {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		found := false
+		for _, filter := range item.Filters {
+			if strings.EqualFold(strings.TrimSpace(filter), tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			conflicts = append(conflicts, DietConflict{Tag: tag, Reason: ConflictMissingDiet})
+		}
+	}
+	if len(p.AvoidIngredients) > 0 {
+		tree := item.IngredientList()
+		for _, ingredient := range p.AvoidIngredients {
+			if _, found := FindIngredient(tree, ingredient); found {
+				conflicts = append(conflicts, DietConflict{Tag: ingredient, Reason: ConflictIngredient})
+			}
+		}
+	}
+	return conflicts
+}
+
+// Allows reports whether item has no conflicts with the profile.
+func (p DietaryProfile) Allows(item DailyItem) bool {
+	return len(p.Conflicts(item)) == 0
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDietaryProfileConflicts(t *testing.T) {
+	profile := DietaryProfile{Avoid: []string{"Milk", "sesame"}, Require: []string{"Vegan"}}
+
+	cases := []struct {
+		name    string
+		filters []string
+		want    []DietConflict
+	}{
+		{"safe", []string{"Vegan", "Soy"}, nil},
+		{"contains", []string{"Vegan", "Milk"}, []DietConflict{{Tag: "Milk", Reason: ConflictContains}}},
+		{"may contain", []string{"Vegan", "Sesame*"}, []DietConflict{{Tag: "sesame", Reason: ConflictMayContain}}},
+		{"missing diet", []string{"Vegetarian"}, []DietConflict{{Tag: "Vegan", Reason: ConflictMissingDiet}}},
+		{"several", []string{"Milk*", "Sesame"}, []DietConflict{
+			{Tag: "Milk", Reason: ConflictMayContain},
+			{Tag: "sesame", Reason: ConflictContains},
+			{Tag: "Vegan", Reason: ConflictMissingDiet},
+		}},
+	}
+	for _, c := range cases {
+		got := profile.Conflicts(DailyItem{Filters: c.filters})
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: Conflicts = %+v, want %+v", c.name, got, c.want)
+		}
+		if profile.Allows(DailyItem{Filters: c.filters}) != (c.want == nil) {
+			t.Errorf("%s: Allows disagrees with Conflicts", c.name)
+		}
+	}
+
+	if !(DietaryProfile{}).Allows(DailyItem{Filters: []string{"Milk"}}) {
+		t.Error("an empty profile allows everything")
+	}
+}
+
+func TestDietaryProfileAvoidsIngredients(t *testing.T) {
+	profile := DietaryProfile{AvoidIngredients: []string{"cilantro", "mushroom"}}
+	item := DailyItem{Ingredients: "Rice, Salsa^ (Tomatoes, Cilantro), Onion"}
+
+	want := []DietConflict{{Tag: "cilantro", Reason: ConflictIngredient}}
+	if got := profile.Conflicts(item); !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts = %+v, want %+v", got, want)
+	}
+	if !profile.Allows(DailyItem{Ingredients: "Rice, Beans"}) {
+		t.Error("an item without avoided ingredients is allowed")
+	}
+
+	item.IngredientTree = []Ingredient{{Name: "Rice"}}
+	if !profile.Allows(item) {
+		t.Error("a parsed tree takes precedence over the raw statement")
+	}
+}
+
+func TestDietaryProfileNormalize(t *testing.T) {
+	profile, err := DietaryProfile{
+		Avoid:            []string{" Milk ", "milk*", "Sesame*", ""},
+		Require:          []string{"Vegan", "vegan", " Halal"},
+		AvoidIngredients: []string{"Cilantro", " cilantro "},
+	}.Normalize()
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	want := DietaryProfile{Avoid: []string{"Milk", "Sesame"}, Require: []string{"Vegan", "Halal"}, AvoidIngredients: []string{"Cilantro"}}
+	if !reflect.DeepEqual(profile, want) {
+		t.Errorf("Normalize = %+v, want %+v", profile, want)
+	}
+
+	if _, err := (DietaryProfile{Avoid: []string{strings.Repeat("x", 60)}}).Normalize(); err == nil {
+		t.Error("an overlong tag is rejected")
+	}
+	if _, err := (DietaryProfile{Require: make([]string, 31)}).Normalize(); err == nil {
+		t.Error("an overlong list is rejected")
+	}
+}
@@ -3,6 +3,10 @@ package models
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 )
 
 // FlexString decodes a JSON value the API sends inconsistently as either a
@@ -91,8 +95,8 @@ type Category struct {
 }
 
 type Item struct {
-	// ID is the upstream item id. It is used only as a sanity check: identical
-	// IDs across a whole menu indicate a malformed payload.
+	// ID is the upstream item id. Identical IDs across a whole menu indicate a
+	// malformed payload; otherwise it is recorded in the item catalog.
 	ID   string `json:"id"`
 	Name string `json:"name"`
 	// mrn int `json:"mrn"`
@@ -115,7 +119,9 @@ type Nutrient struct {
 	// id string `json:"id"`
 	Name  string     `json:"name"`
 	Value FlexString `json:"value"`
-	// uom string `json:"uom"`
+	// UOM is the unit of measure ("g", "mg", ...); older payloads leave it
+	// empty and carry the unit in the name instead ("Protein (g)").
+	UOM string `json:"uom"`
 	// value_numeric string `json:"value_numeric"`
 }
 
@@ -140,12 +146,42 @@ type DailyItem struct {
 	Protein     string `json:"protein"`
 	Carbs       string `json:"carbs"`
 	Fat         string `json:"fat"`
+	// CatalogID links the row to its canonical item in the catalog, which
+	// favorites match on. Zero for rows not linked yet.
+	CatalogID uint `json:"catalogId,omitempty" gorm:"index"`
+	// UpstreamID is the scraped Item.ID, recorded in the catalog when the row
+	// is written. It is never stored on the row itself.
+	UpstreamID string `json:"-" gorm:"-"`
 	// Ingredients is the upstream ingredient statement for the item.
 	Ingredients string `json:"ingredients"`
+	// IngredientTree is Ingredients parsed into entries and sub-ingredients.
+	// It is derived when the menu store loads and is never stored. Menu
+	// payloads leave it out; ItemDetail carries it for a single item.
+	IngredientTree []Ingredient `json:"-" gorm:"-"`
 	// Filters holds the upstream tag names (allergens, diets and marketing
 	// callouts) verbatim, including "may contain" variants such as "Sesame*".
-	// Clients decide how to categorize them. Stored as a JSON text column.
+	// The server categorizes them through the filter taxonomy; clients should
+	// read Tags rather than interpret these names. Stored as a JSON text
+	// column.
 	Filters []string `json:"filters" gorm:"serializer:json"`
+	// Tags resolves Filters through the filter taxonomy, in the same order.
+	// They are derived when the menu store loads and are never stored.
+	Tags []FilterTag `json:"tags,omitempty" gorm:"-"`
+	// Nutrition is the full upstream label as parsed numbers. The string
+	// fields above are kept for older clients. Stored as a JSON text column.
+	Nutrition Nutrition `json:"nutrition" gorm:"serializer:json"`
+	// Serving is PortionSize parsed into a quantity and unit. Stored as a JSON
+	// text column; rows stored before it existed are parsed when the menu
+	// store loads.
+	Serving Portion `json:"serving" gorm:"serializer:json"`
+	// Estimates fills in calories and macros the label left empty, keyed by
+	// nutrient ("calories", "protein", "carbs", "fat"). They are derived when
+	// the menu store loads and are not stored on menu rows; a food log entry
+	// keeps them in its item snapshot so its totals outlive the row.
+	Estimates map[string]NutrientEstimate `json:"estimates,omitempty" gorm:"-"`
+	// DietConflicts explains why the item is unsafe for the signed-in user's
+	// dietary profile. It is set per response and never stored.
+	DietConflicts []DietConflict `json:"dietConflicts,omitempty" gorm:"-"`
 }
 
 type WeeklyItem struct {
@@ -157,6 +193,10 @@ type WeeklyItem struct {
 
 type AllDataItem struct {
 	Name string
+	// CatalogID links a favorite to its catalog item so renames and spacing
+	// changes keep matching. Zero means not yet resolved. Stored only inside
+	// the favorites JSON, never on the all-data table.
+	CatalogID uint `json:"catalogId,omitempty" gorm:"-"`
 }
 
 type PreferenceReturn struct {
@@ -168,10 +208,254 @@ type DisplayPreferences struct {
 	VisibleLocations []string `json:"visibleLocations"`
 }
 
-// NutritionGoals represents user-defined nutrition goals
+// NutritionGoals represents user-defined nutrition goals. The four flat
+// targets are the original payload and are always present; everything after
+// them is optional, so older clients keep working unchanged.
 type NutritionGoals struct {
 	Calories float64
 	Protein  float64
 	Carbs    float64
 	Fat      float64
+	// Optional acceptable bands around the targets.
+	CaloriesRange *GoalRange `json:",omitempty"`
+	ProteinRange  *GoalRange `json:",omitempty"`
+	CarbsRange    *GoalRange `json:",omitempty"`
+	FatRange      *GoalRange `json:",omitempty"`
+	// MealSplit optionally divides the day's goals across meals.
+	MealSplit *MealSplit `json:",omitempty"`
+	// Optional daily limits: sodium (mg) and sugar (g) caps, a fiber (g) floor.
+	SodiumMax *float64 `json:",omitempty"`
+	SugarMax  *float64 `json:",omitempty"`
+	FiberMin  *float64 `json:",omitempty"`
+}
+
+// GoalRange is an inclusive acceptable band for one nutrient.
+type GoalRange struct {
+	Min float64
+	Max float64
+}
+
+// MealSplit is the percentage of the day's goals planned for each meal. The
+// three shares add up to 100.
+type MealSplit struct {
+	Breakfast float64
+	Lunch     float64
+	Dinner    float64
+}
+
+// DefaultNutritionGoals are served to users who have not saved goals.
+func DefaultNutritionGoals() NutritionGoals {
+	return NutritionGoals{
+		Calories: 2000,
+		Protein:  50,
+		Carbs:    275,
+		Fat:      78,
+	}
+}
+
+// maxGoalValue bounds every goal value; anything larger is a typo.
+const maxGoalValue = 100000
+
+// Validate reports the first problem with the goals: a negative or absurd
+// value, a range whose minimum exceeds its maximum or excludes its target, or
+// a meal split that does not add up to 100%.
+func (g NutritionGoals) Validate() error {
+	targets := []struct {
+		name   string
+		target float64
+		band   *GoalRange
+	}{
+		{"Calories", g.Calories, g.CaloriesRange},
+		{"Protein", g.Protein, g.ProteinRange},
+		{"Carbs", g.Carbs, g.CarbsRange},
+		{"Fat", g.Fat, g.FatRange},
+	}
+	for _, t := range targets {
+		if err := checkGoalValue(t.name, t.target); err != nil {
+			return err
+		}
+		if t.band == nil {
+			continue
+		}
+		if err := checkGoalValue(t.name+"Range.Min", t.band.Min); err != nil {
+			return err
+		}
+		if err := checkGoalValue(t.name+"Range.Max", t.band.Max); err != nil {
+			return err
+		}
+		if t.band.Min > t.band.Max {
+			return fmt.Errorf("%sRange.Min must not exceed %sRange.Max", t.name, t.name)
+		}
+		if t.target > 0 && (t.target < t.band.Min || t.target > t.band.Max) {
+			return fmt.Errorf("%s must lie within %sRange", t.name, t.name)
+		}
+	}
+
+	limits := []struct {
+		name  string
+		value *float64
+	}{
+		{"SodiumMax", g.SodiumMax},
+		{"SugarMax", g.SugarMax},
+		{"FiberMin", g.FiberMin},
+	}
+	for _, limit := range limits {
+		if limit.value == nil {
+			continue
+		}
+		if err := checkGoalValue(limit.name, *limit.value); err != nil {
+			return err
+		}
+	}
+
+	if split := g.MealSplit; split != nil {
+		for _, share := range []float64{split.Breakfast, split.Lunch, split.Dinner} {
+			if math.IsNaN(share) || share < 0 || share > 100 {
+				return errors.New("MealSplit percentages must be between 0 and 100")
+			}
+		}
+		if total := split.Breakfast + split.Lunch + split.Dinner; math.Abs(total-100) > 0.5 {
+			return fmt.Errorf("MealSplit percentages must add up to 100, got %g", total)
+		}
+	}
+	return nil
+}
+
+// MealShare returns the fraction of the day's goals planned for meal and
+// whether the user's split covers it.
+func (g NutritionGoals) MealShare(meal string) (float64, bool) {
+	if g.MealSplit == nil {
+		return 0, false
+	}
+	switch strings.ToLower(strings.TrimSpace(meal)) {
+	case "breakfast":
+		return g.MealSplit.Breakfast / 100, true
+	case "lunch", "brunch":
+		return g.MealSplit.Lunch / 100, true
+	case "dinner":
+		return g.MealSplit.Dinner / 100, true
+	}
+	return 0, false
+}
+
+func checkGoalValue(name string, value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) || value < 0 || value > maxGoalValue {
+		return fmt.Errorf("%s must be between 0 and %d", name, maxGoalValue)
+	}
+	return nil
+}
+
+// MenuFilter scopes a menu read to a date range and, optionally, one hall, meal
+// and station. Dates are inclusive YYYY-MM-DD bounds; an empty bound is open.
+// Location, Meal and Station match case-insensitively and an empty value
+// matches everything.
+type MenuFilter struct {
+	From     string
+	To       string
+	Location string
+	Meal     string
+	Station  string
+	// Ingredients keeps only items whose ingredient tree holds every entry,
+	// matched as FindIngredient does.
+	Ingredients []string
+}
+
+// Matches reports whether a menu item falls inside the filter.
+func (f MenuFilter) Matches(item DailyItem) bool {
+	if f.From != "" && item.Date < f.From {
+		return false
+	}
+	if f.To != "" && item.Date > f.To {
+		return false
+	}
+	if f.Location != "" && !strings.EqualFold(item.Location, f.Location) {
+		return false
+	}
+	if f.Meal != "" && !strings.EqualFold(item.TimeOfDay, f.Meal) {
+		return false
+	}
+	if f.Station != "" && !strings.EqualFold(item.StationName, f.Station) {
+		return false
+	}
+	if len(f.Ingredients) > 0 {
+		tree := item.IngredientList()
+		for _, ingredient := range f.Ingredients {
+			if _, found := FindIngredient(tree, ingredient); !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MenuResponse is the payload of the scoped menu endpoint. Items are ordered by
+// date, location, time of day, station and name.
+type MenuResponse struct {
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Location string      `json:"location,omitempty"`
+	Meal     string      `json:"meal,omitempty"`
+	Station  string      `json:"station,omitempty"`
+	Count    int         `json:"count"`
+	Items    []DailyItem `json:"items"`
+	// Excluded counts items left out for conflicting with the signed-in
+	// user's dietary profile.
+	Excluded int `json:"excluded,omitempty"`
+}
+
+// ItemSuggestion is one typeahead match from the food catalog. Score is in
+// (0, 1]; higher is a closer match.
+type ItemSuggestion struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// ItemAppearance is one menu slot an item was (or is scheduled to be) served in.
+type ItemAppearance struct {
+	Date        string `json:"date"`
+	Location    string `json:"location"`
+	StationName string `json:"stationName"`
+	TimeOfDay   string `json:"timeOfDay"`
+}
+
+// ItemDetail describes one food item: its most recent nutrition, ingredients
+// and tags, plus every retained appearance, newest first.
+type ItemDetail struct {
+	Name           string                      `json:"name"`
+	Description    string                      `json:"description"`
+	PortionSize    string                      `json:"portion"`
+	Calories       string                      `json:"calories"`
+	Protein        string                      `json:"protein"`
+	Carbs          string                      `json:"carbs"`
+	Fat            string                      `json:"fat"`
+	Ingredients    string                      `json:"ingredients"`
+	IngredientTree []Ingredient                `json:"ingredientTree,omitempty"`
+	Filters        []string                    `json:"filters"`
+	Nutrition      Nutrition                   `json:"nutrition"`
+	Serving        Portion                     `json:"serving"`
+	Estimates      map[string]NutrientEstimate `json:"estimates,omitempty"`
+	LastServed     string                      `json:"lastServed"`
+	Since          string                      `json:"since"` // earliest date the history covers
+	Appearances    []ItemAppearance            `json:"appearances"`
+}
+
+// MenuPeriodChange is one menu slice (date, location, time of day) that was
+// replaced or cleared, with the items it holds now. A slice pruned for age is
+// reported as cleared.
+type MenuPeriodChange struct {
+	Date      string      `json:"date"`
+	Location  string      `json:"location"`
+	TimeOfDay string      `json:"timeOfDay"`
+	Cleared   bool        `json:"cleared"`
+	Items     []DailyItem `json:"items"`
+}
+
+// MenuChanges is the payload of the delta-sync endpoint. Clients store Version
+// and pass it back as since; when Reset is true they must refetch the full
+// menu instead of applying Periods.
+type MenuChanges struct {
+	Since   uint               `json:"since"`
+	Version uint               `json:"version"`
+	Reset   bool               `json:"reset"`
+	Periods []MenuPeriodChange `json:"periods"`
 }
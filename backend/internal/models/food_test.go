@@ -25,3 +25,58 @@ func TestFlexStringAcceptsStringAndNumber(t *testing.T) {
 		}
 	}
 }
+
+func TestNutritionGoalsValidate(t *testing.T) {
+	sodium, negative := 2300.0, -1.0
+	valid := []NutritionGoals{
+		DefaultNutritionGoals(),
+		{},
+		{
+			Calories:      2200,
+			Protein:       120,
+			Carbs:         250,
+			Fat:           70,
+			CaloriesRange: &GoalRange{Min: 2000, Max: 2400},
+			ProteinRange:  &GoalRange{Min: 100, Max: 150},
+			MealSplit:     &MealSplit{Breakfast: 25, Lunch: 35, Dinner: 40},
+			SodiumMax:     &sodium,
+		},
+	}
+	for i, goals := range valid {
+		if err := goals.Validate(); err != nil {
+			t.Errorf("case %d: unexpected error %v", i, err)
+		}
+	}
+
+	invalid := map[string]NutritionGoals{
+		"negative target":    {Calories: -5},
+		"inverted range":     {Calories: 2000, CaloriesRange: &GoalRange{Min: 2500, Max: 1800}},
+		"target outside":     {Protein: 200, ProteinRange: &GoalRange{Min: 100, Max: 150}},
+		"split not 100":      {MealSplit: &MealSplit{Breakfast: 30, Lunch: 30, Dinner: 30}},
+		"negative share":     {MealSplit: &MealSplit{Breakfast: -10, Lunch: 50, Dinner: 60}},
+		"negative limit":     {FiberMin: &negative},
+		"absurd calorie cap": {Calories: 1e9},
+	}
+	for name, goals := range invalid {
+		if err := goals.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNutritionGoalsAcceptLegacyPayload(t *testing.T) {
+	var goals NutritionGoals
+	if err := json.Unmarshal([]byte(`{"Calories":1800,"Protein":90,"Carbs":200,"Fat":60}`), &goals); err != nil {
+		t.Fatal(err)
+	}
+	if goals.Calories != 1800 || goals.CaloriesRange != nil || goals.MealSplit != nil || goals.SodiumMax != nil {
+		t.Errorf("unexpected goals %+v", goals)
+	}
+	encoded, err := json.Marshal(goals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"Calories":1800,"Protein":90,"Carbs":200,"Fat":60}` {
+		t.Errorf("unset optional goals should be omitted, got %s", encoded)
+	}
+}
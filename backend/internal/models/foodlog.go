@@ -0,0 +1,112 @@
+package models
+
+import "time"
+
+// FoodLogEntry is one serving record in a user's food log. Item is a snapshot
+// of the menu item taken when it was logged, so the entry keeps its nutrition
+// after the menu row itself is pruned or replaced.
+type FoodLogEntry struct {
+	ID       uint      `json:"id"`
+	Date     string    `json:"date"` // YYYY-MM-DD the food was eaten
+	Servings float64   `json:"servings"`
+	Item     DailyItem `json:"item"`
+	LoggedAt time.Time `json:"loggedAt"`
+}
+
+// FoodLogRequest names a stored menu item to log. The item is looked up by
+// date, location, meal and name. The amount is either Servings (default 1) or
+// Grams, which needs the item's portion size in units of mass.
+type FoodLogRequest struct {
+	Date      string  `json:"date"`
+	Location  string  `json:"location"`
+	TimeOfDay string  `json:"timeOfDay"`
+	Name      string  `json:"name"`
+	Servings  float64 `json:"servings,omitempty"`
+	Grams     float64 `json:"grams,omitempty"`
+}
+
+// FoodLog is one user's entries for a date, oldest first.
+type FoodLog struct {
+	Date    string         `json:"date"`
+	Entries []FoodLogEntry `json:"entries"`
+}
+
+// NutrientTotals sums the four goal nutrients.
+type NutrientTotals struct {
+	Calories float64 `json:"calories"`
+	Protein  float64 `json:"protein"`
+	Carbs    float64 `json:"carbs"`
+	Fat      float64 `json:"fat"`
+}
+
+// FoodLogSummary compares a day's logged totals with the user's goals.
+type FoodLogSummary struct {
+	Date    string         `json:"date"`
+	Entries int            `json:"entries"`
+	Totals  NutrientTotals `json:"totals"`
+	Goals   NutritionGoals `json:"goals"`
+	// Remaining is goal minus total, negative once a goal is exceeded.
+	Remaining NutrientTotals `json:"remaining"`
+	// Percent is total as a percentage of goal; 0 where the goal is 0.
+	Percent NutrientTotals `json:"percent"`
+	// Incomplete counts entries missing at least one nutrient, whose totals
+	// are therefore understated.
+	Incomplete int `json:"incomplete"`
+	// Estimated counts entries whose totals include estimated values for
+	// nutrients their label left empty.
+	Estimated int `json:"estimated"`
+}
+
+// Day statuses in a weekly report.
+const (
+	DayNotLogged = "notLogged"
+	DayUnder     = "under"
+	DayOnTarget  = "onTarget"
+	DayOver      = "over"
+	// DayLogged marks a logged day when there is no calorie goal to compare
+	// against.
+	DayLogged = "logged"
+)
+
+// DayReport is one day of a weekly report.
+type DayReport struct {
+	Date    string         `json:"date"`
+	Entries int            `json:"entries"`
+	Totals  NutrientTotals `json:"totals"`
+	// Status compares the day's calories with the calorie goal, or its range
+	// when one is set.
+	Status string `json:"status"`
+}
+
+// ItemCount is how often one item was logged in a report period.
+type ItemCount struct {
+	Name     string  `json:"name"`
+	Entries  int     `json:"entries"`
+	Servings float64 `json:"servings"`
+}
+
+// HallCount is how many logged entries came from one dining hall.
+type HallCount struct {
+	Location string `json:"location"`
+	Entries  int    `json:"entries"`
+}
+
+// WeeklyReport rolls up seven days of a user's food log.
+type WeeklyReport struct {
+	From  string         `json:"from"`
+	To    string         `json:"to"`
+	Goals NutritionGoals `json:"goals"`
+	Days  []DayReport    `json:"days"`
+	// DaysLogged counts days with at least one entry; the over, under and
+	// on-target counts are among those.
+	DaysLogged   int `json:"daysLogged"`
+	DaysOver     int `json:"daysOver"`
+	DaysUnder    int `json:"daysUnder"`
+	DaysOnTarget int `json:"daysOnTarget"`
+	// Average is the mean daily total over the logged days.
+	Average  NutrientTotals `json:"average"`
+	TopItems []ItemCount    `json:"topItems"`
+	TopHalls []HallCount    `json:"topHalls"`
+	// Incomplete counts entries missing at least one nutrient.
+	Incomplete int `json:"incomplete"`
+}
@@ -0,0 +1,155 @@
+package models
+
+import "strings"
+
+// Ingredient is one entry of a parsed ingredient statement. Sub holds the
+// parenthesized ingredients of a compound entry, so "Barbecue Sauce^ (Tomato
+// Paste, Sugar)" becomes Barbecue Sauce with two sub-ingredients.
+type Ingredient struct {
+	Name string `json:"name"`
+	// Markers are the hall's footnote characters stripped from the name, in
+	// order of appearance, e.g. "^" or "*".
+	Markers string       `json:"markers,omitempty"`
+	Sub     []Ingredient `json:"sub,omitempty"`
+}
+
+// ingredientMarkers are the footnote characters halls append to names.
+const ingredientMarkers = "*^"
+
+// ParseIngredients parses an upstream ingredient statement into a tree.
+// Entries are separated by commas or semicolons; "(...)" and "[...]" open a
+// sub-list belonging to the entry before them. Footnote markers move from
+// the name into Markers. Unbalanced brackets are tolerated: a stray closer is
+// ignored and an unclosed sub-list ends with the statement. A blank statement
+// yields nil.
+func ParseIngredients(statement string) []Ingredient {
+	p := ingredientParser{input: []rune(statement)}
+	return p.list(0)
+}
+
+type ingredientParser struct {
+	input []rune
+	pos   int
+}
+
+// list parses entries until the closing bracket matching depth, or the end.
+func (p *ingredientParser) list(depth int) []Ingredient {
+	var list []Ingredient
+	var name strings.Builder
+	var sub []Ingredient
+
+	flush := func() {
+		if ingredient, ok := newIngredient(name.String(), sub); ok {
+			list = append(list, ingredient)
+		} else {
+			// A bare "(...)" has no entry to belong to; keep its contents.
+			list = append(list, sub...)
+		}
+		name.Reset()
+		sub = nil
+	}
+
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		p.pos++
+		switch r {
+		case ',', ';':
+			flush()
+		case '(', '[':
+			sub = append(sub, p.list(depth+1)...)
+		case ')', ']':
+			if depth > 0 {
+				flush()
+				return list
+			}
+		default:
+			name.WriteRune(r)
+		}
+	}
+	flush()
+	return list
+}
+
+// newIngredient cleans a raw entry name, reporting false when nothing but
+// markers and punctuation is left.
+func newIngredient(raw string, sub []Ingredient) (Ingredient, bool) {
+	var markers strings.Builder
+	var cleaned strings.Builder
+	for _, r := range raw {
+		if strings.ContainsRune(ingredientMarkers, r) {
+			if !strings.ContainsRune(markers.String(), r) {
+				markers.WriteRune(r)
+			}
+			continue
+		}
+		cleaned.WriteRune(r)
+	}
+
+	name := strings.Join(strings.Fields(cleaned.String()), " ")
+	name = strings.TrimSpace(strings.TrimRight(name, "."))
+	if name == "" {
+		return Ingredient{}, false
+	}
+	return Ingredient{Name: name, Markers: markers.String(), Sub: sub}, true
+}
+
+// IngredientList returns the item's parsed ingredients: IngredientTree when
+// the menu store filled it in, otherwise a fresh parse of Ingredients.
+func (item DailyItem) IngredientList() []Ingredient {
+	if item.IngredientTree != nil {
+		return item.IngredientTree
+	}
+	return ParseIngredients(item.Ingredients)
+}
+
+// FindIngredient searches the tree, sub-ingredients included, for an
+// ingredient matching query and returns its name. See IngredientMatches.
+func FindIngredient(tree []Ingredient, query string) (string, bool) {
+	for _, ingredient := range tree {
+		if IngredientMatches(ingredient.Name, query) {
+			return ingredient.Name, true
+		}
+		if name, ok := FindIngredient(ingredient.Sub, query); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// IngredientMatches reports whether the words of query appear, in order and
+// next to each other, among the words of name. Case is ignored and a trailing
+// "s" or "es" is tolerated on either side, so "mushroom" matches "Button
+// Mushrooms" and "green onions" matches "Green Onion".
+func IngredientMatches(name, query string) bool {
+	nameWords := IngredientWords(name)
+	queryWords := IngredientWords(query)
+	if len(queryWords) == 0 || len(queryWords) > len(nameWords) {
+		return false
+	}
+
+	for start := 0; start+len(queryWords) <= len(nameWords); start++ {
+		matched := true
+		for i, word := range queryWords {
+			if !samePlural(nameWords[start+i], word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IngredientWords splits text into the lowercase words IngredientMatches
+// compares: runs of ASCII letters and digits.
+func IngredientWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+}
+
+func samePlural(a, b string) bool {
+	return a == b || a == b+"s" || a == b+"es" || b == a+"s" || b == a+"es"
+}
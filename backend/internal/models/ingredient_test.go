@@ -0,0 +1,61 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIngredients(t *testing.T) {
+	got := ParseIngredients("Chicken, Barbecue Sauce^ (Tomato Paste, Sugar [Cane Sugar, Molasses*], Vinegar), Canola Oil.")
+	want := []Ingredient{
+		{Name: "Chicken"},
+		{Name: "Barbecue Sauce", Markers: "^", Sub: []Ingredient{
+			{Name: "Tomato Paste"},
+			{Name: "Sugar", Sub: []Ingredient{{Name: "Cane Sugar"}, {Name: "Molasses", Markers: "*"}}},
+			{Name: "Vinegar"},
+		}},
+		{Name: "Canola Oil"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseIngredients = %+v, want %+v", got, want)
+	}
+
+	cases := map[string][]Ingredient{
+		"":                          nil,
+		" , ^ ,":                    nil,
+		"Salt) , Pepper":            {{Name: "Salt"}, {Name: "Pepper"}},
+		"Bun (Flour, Yeast":         {{Name: "Bun", Sub: []Ingredient{{Name: "Flour"}, {Name: "Yeast"}}}},
+		"(Milk, Cream); Salt":       {{Name: "Milk"}, {Name: "Cream"}, {Name: "Salt"}},
+		"Cheese (Milk, Salt)^*^":    {{Name: "Cheese", Markers: "^*", Sub: []Ingredient{{Name: "Milk"}, {Name: "Salt"}}}},
+		"Green   Onions , CILANTRO": {{Name: "Green Onions"}, {Name: "CILANTRO"}},
+	}
+	for statement, want := range cases {
+		if got := ParseIngredients(statement); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseIngredients(%q) = %+v, want %+v", statement, got, want)
+		}
+	}
+}
+
+func TestFindIngredient(t *testing.T) {
+	tree := ParseIngredients("Rice, Salsa (Tomatoes, Cilantro, Green Onion), Button Mushrooms")
+
+	cases := []struct {
+		query string
+		want  string
+		found bool
+	}{
+		{"cilantro", "Cilantro", true},
+		{"mushroom", "Button Mushrooms", true},
+		{"tomato", "Tomatoes", true},
+		{"green onions", "Green Onion", true},
+		{"onion green", "", false},
+		{"mush", "", false},
+		{"", "", false},
+	}
+	for _, c := range cases {
+		name, found := FindIngredient(tree, c.query)
+		if name != c.want || found != c.found {
+			t.Errorf("FindIngredient(%q) = %q, %v; want %q, %v", c.query, name, found, c.want, c.found)
+		}
+	}
+}
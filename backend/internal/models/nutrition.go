@@ -0,0 +1,61 @@
+package models
+
+// NutrientAmount is one parsed nutrition label value.
+type NutrientAmount struct {
+	Value float64 `json:"value"`
+	// Unit is the upstream unit of measure ("g", "mg", "mcg", "IU", "kcal").
+	Unit string `json:"unit,omitempty"`
+	// LessThan marks labels printed as a bound, e.g. "<1"; Value is the bound.
+	LessThan bool `json:"lessThan,omitempty"`
+}
+
+// Nutrition is an item's full nutrition label as parsed numbers. A nil entry
+// means the label did not list the nutrient or printed it as "-".
+type Nutrition struct {
+	Calories     *NutrientAmount `json:"calories,omitempty"`
+	Protein      *NutrientAmount `json:"protein,omitempty"`
+	Carbs        *NutrientAmount `json:"carbs,omitempty"`
+	Fat          *NutrientAmount `json:"fat,omitempty"`
+	SaturatedFat *NutrientAmount `json:"saturatedFat,omitempty"`
+	TransFat     *NutrientAmount `json:"transFat,omitempty"`
+	Cholesterol  *NutrientAmount `json:"cholesterol,omitempty"`
+	Sodium       *NutrientAmount `json:"sodium,omitempty"`
+	Sugars       *NutrientAmount `json:"sugars,omitempty"`
+	Fiber        *NutrientAmount `json:"fiber,omitempty"`
+	// Other holds vitamins, minerals and any other labeled nutrient, keyed by
+	// the upstream name without its unit suffix (e.g. "Vitamin C", "Iron").
+	Other map[string]NutrientAmount `json:"other,omitempty"`
+}
+
+// IsEmpty reports whether no nutrient was parsed, as for rows stored before
+// the structured label existed.
+func (n Nutrition) IsEmpty() bool {
+	return n.Calories == nil && n.Protein == nil && n.Carbs == nil && n.Fat == nil &&
+		n.SaturatedFat == nil && n.TransFat == nil && n.Cholesterol == nil &&
+		n.Sodium == nil && n.Sugars == nil && n.Fiber == nil && len(n.Other) == 0
+}
+
+// Portion is an item's upstream portion size ("1 each", "4 oz", "1/2 cup")
+// as a quantity and unit. Parsed is false when the text could not be read;
+// the other fields are then empty rather than guessed.
+type Portion struct {
+	Quantity float64 `json:"quantity,omitempty"`
+	// Unit is the normalized unit: "g", "oz", "cup", "each", "slice" and so on.
+	Unit string `json:"unit,omitempty"`
+	// Grams is the portion's weight, set only for units of mass.
+	Grams  *float64 `json:"grams,omitempty"`
+	Parsed bool     `json:"parsed"`
+}
+
+// NutrientEstimate stands in for a nutrient the upstream label left empty.
+type NutrientEstimate struct {
+	Value float64 `json:"value"`
+	// Confidence runs from 0 (a guess) to 1 (as good as a label value).
+	Confidence float64 `json:"confidence"`
+	// Source is "history" when the value comes from the same item on other
+	// days, or "similar" when it comes from items with similar names and
+	// ingredients.
+	Source string `json:"source"`
+	// Samples counts the appearances or similar items the value came from.
+	Samples int `json:"samples"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// PlateComponent is one item on a saved plate, matched against the menu by
+// name through the item catalog, taken at a serving multiplier.
+type PlateComponent struct {
+	Name     string  `json:"name"`
+	Servings float64 `json:"servings"`
+}
+
+// SavedPlate is a named combination of items a user keeps on the server, e.g.
+// a plate accepted from the planner.
+type SavedPlate struct {
+	ID        uint             `json:"id"`
+	Name      string           `json:"name"`
+	Items     []PlateComponent `json:"items"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// SavedPlateRequest creates or replaces a saved plate. A component's servings
+// default to 1.
+type SavedPlateRequest struct {
+	Name  string           `json:"name"`
+	Items []PlateComponent `json:"items"`
+}
+
+// PlateSlot is one meal period at one hall.
+type PlateSlot struct {
+	Date      string `json:"date"`
+	TimeOfDay string `json:"timeOfDay"`
+	Location  string `json:"location"`
+}
+
+// PlateAvailability lists the upcoming slots serving every item on a plate.
+type PlateAvailability struct {
+	Plate SavedPlate  `json:"plate"`
+	Slots []PlateSlot `json:"slots"`
+}
+
+// PlateAvailabilityResponse reports availability for each of a user's saved
+// plates from From onwards.
+type PlateAvailabilityResponse struct {
+	From   string              `json:"from"`
+	Plates []PlateAvailability `json:"plates"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// SubscriptionRule is a standing search a user follows like a favorite: every
+// menu item matching Query is announced in the meal notifications and the
+// favorites email. Query uses the menu search language without a date
+// clause, e.g. "ramen", "tag:vegan location:sargent" or
+// "station:flame protein>30".
+type SubscriptionRule struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SubscriptionRuleRequest creates or replaces a subscription rule. The name
+// defaults to the query.
+type SubscriptionRuleRequest struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// Filter categories in the taxonomy. Tags no mapping covers are reported as
+// FilterUncategorized.
+const (
+	FilterAllergen      = "allergen"
+	FilterDiet          = "diet"
+	FilterMarketing     = "marketing"
+	FilterUncategorized = "uncategorized"
+)
+
+// FilterMapping maps one upstream filter name to a category and a canonical
+// key. Name is the base name without the "may contain" "*"; the mapping covers
+// both variants.
+type FilterMapping struct {
+	Name     string `json:"name"`     // e.g. "Tree Nuts"
+	Key      string `json:"key"`      // e.g. "tree_nuts"
+	Category string `json:"category"` // FilterAllergen, FilterDiet or FilterMarketing
+}
+
+// FilterTag is one of an item's raw filter names resolved through the
+// taxonomy.
+type FilterTag struct {
+	Name       string `json:"name"` // the raw upstream name, e.g. "Sesame*"
+	Key        string `json:"key"`
+	Category   string `json:"category"`
+	MayContain bool   `json:"mayContain"`
+}
+
+// FilterTaxonomy is the full mapping table along with the raw names seen on
+// the menu that no mapping covers yet.
+type FilterTaxonomy struct {
+	Mappings []FilterMapping `json:"mappings"`
+	Unmapped []string        `json:"unmapped"`
+}
+
+// FilterKey derives a canonical key from a filter name: lowercase words joined
+// by underscores, without the "may contain" "*". "Tree Nuts*" becomes
+// "tree_nuts".
+func FilterKey(name string) string {
+	name = strings.TrimSuffix(strings.TrimSpace(name), "*")
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	return strings.Join(words, "_")
+}
+
+// Validate reports the first problem with a mapping. An empty key is allowed
+// and means FilterKey(Name).
+func (m FilterMapping) Validate() error {
+	name := strings.TrimSpace(m.Name)
+	switch {
+	case name == "":
+		return errors.New("name is required")
+	case strings.HasSuffix(name, "*"):
+		return errors.New(`name must not end in "*"; the mapping covers the may contain variant too`)
+	case len(name) > 100:
+		return errors.New("name must be at most 100 characters")
+	}
+	if m.Key != "" && FilterKey(m.Key) != m.Key {
+		return errors.New("key must be lowercase letters and digits joined by underscores")
+	}
+	switch m.Category {
+	case FilterAllergen, FilterDiet, FilterMarketing:
+		return nil
+	}
+	return errors.New("category must be allergen, diet or marketing")
+}
+
+// DefaultFilterMappings is the taxonomy seeded into an empty table: the major
+// allergens, the diets the dining halls label and the common marketing
+// callouts.
+func DefaultFilterMappings() []FilterMapping {
+	var mappings []FilterMapping
+	add := func(category string, names ...string) {
+		for _, name := range names {
+			mappings = append(mappings, FilterMapping{Name: name, Key: FilterKey(name), Category: category})
+		}
+	}
+	add(FilterAllergen, "Milk", "Eggs", "Fish", "Shellfish", "Tree Nuts", "Peanuts", "Wheat", "Soy", "Sesame", "Gluten", "Coconut")
+	add(FilterDiet, "Vegan", "Vegetarian", "Halal", "Kosher", "Avoiding Gluten", "Plant Forward")
+	add(FilterMarketing, "Good Source of Protein", "Good Source of Fiber", "Climate Friendly", "Local", "Sustainable", "Organic")
+	return mappings
+}
@@ -0,0 +1,41 @@
+package models
+
+import "testing"
+
+func TestFilterKey(t *testing.T) {
+	cases := map[string]string{
+		"Milk":                   "milk",
+		"Tree Nuts*":             "tree_nuts",
+		" Good Source of Fiber ": "good_source_of_fiber",
+		"Made w/ Whole-Grain":    "made_w_whole_grain",
+	}
+	for name, want := range cases {
+		if got := FilterKey(name); got != want {
+			t.Errorf("FilterKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFilterMappingValidate(t *testing.T) {
+	valid := []FilterMapping{
+		{Name: "Milk", Key: "milk", Category: FilterAllergen},
+		{Name: "Halal", Category: FilterDiet},
+	}
+	for _, mapping := range valid {
+		if err := mapping.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error %v", mapping, err)
+		}
+	}
+
+	invalid := []FilterMapping{
+		{Name: "", Category: FilterAllergen},
+		{Name: "Sesame*", Category: FilterAllergen},
+		{Name: "Milk", Key: "Milk Key", Category: FilterAllergen},
+		{Name: "Milk", Category: "dairy"},
+	}
+	for _, mapping := range invalid {
+		if err := mapping.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", mapping)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package nutrition parses upstream nutrition labels into numbers and reads
+// them back off menu items.
+//
+// Label values arrive as free-form text: "210", "4.5", "<1", "-", sometimes
+// with the unit glued on ("120mg") or only present in the nutrient's name
+// ("Protein (g)"). ParseAmount normalizes all of these; FromLabel maps a whole
+// label onto models.Nutrition.
+package nutrition
+
+import (
+	"backend/internal/models"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Canonical nutrient field names, as used by search clauses and Value.
+const (
+	Calories     = "calories"
+	Protein      = "protein"
+	Carbs        = "carbs"
+	Fat          = "fat"
+	SaturatedFat = "saturatedFat"
+	TransFat     = "transFat"
+	Cholesterol  = "cholesterol"
+	Sodium       = "sodium"
+	Sugars       = "sugars"
+	Fiber        = "fiber"
+)
+
+// labelFields maps lowercased upstream nutrient names, with any "(unit)"
+// suffix removed, to canonical fields. Names not listed land in Other.
+var labelFields = map[string]string{
+	"calories":                Calories,
+	"protein":                 Protein,
+	"total carbohydrates":     Carbs,
+	"total carbohydrate":      Carbs,
+	"carbohydrates":           Carbs,
+	"carbohydrate":            Carbs,
+	"total fat":               Fat,
+	"fat":                     Fat,
+	"saturated fat":           SaturatedFat,
+	"sat. fat":                SaturatedFat,
+	"trans fat":               TransFat,
+	"cholesterol":             Cholesterol,
+	"sodium":                  Sodium,
+	"sugars":                  Sugars,
+	"sugar":                   Sugars,
+	"total sugars":            Sugars,
+	"dietary fiber":           Fiber,
+	"total dietary fiber":     Fiber,
+	"fiber":                   Fiber,
+	"calories from fat":       "",
+	"calories from saturated": "",
+}
+
+// FromLabel parses an upstream nutrient list. Entries whose value is missing
+// or printed as "-" are left out.
+func FromLabel(nutrients []models.Nutrient) models.Nutrition {
+	var label models.Nutrition
+	for _, nutrient := range nutrients {
+		name, nameUnit := splitName(nutrient.Name)
+		if name == "" {
+			continue
+		}
+		unit := strings.TrimSpace(nutrient.UOM)
+		if unit == "" {
+			unit = nameUnit
+		}
+		amount, ok := ParseAmount(string(nutrient.Value), unit)
+		if !ok {
+			continue
+		}
+
+		field, known := labelFields[strings.ToLower(name)]
+		if known && field == "" {
+			// Derived lines such as "Calories from Fat" repeat other entries.
+			continue
+		}
+		if !known {
+			if label.Other == nil {
+				label.Other = make(map[string]models.NutrientAmount)
+			}
+			label.Other[name] = amount
+			continue
+		}
+		if field == Calories && amount.Unit == "" {
+			amount.Unit = "kcal"
+		}
+		if target := fieldPointer(&label, field); target != nil {
+			*target = &amount
+		}
+	}
+	return label
+}
+
+// ParseAmount parses one label value. It accepts plain numbers ("4.5",
+// "1,200"), bounds ("<1", "< 0.5", "less than 1"), and a unit glued to the
+// number ("120mg", "5 g"), which is used when unit is empty. Empty values and
+// placeholders ("-", "--", "N/A") report false.
+func ParseAmount(value, unit string) (models.NutrientAmount, bool) {
+	text := strings.TrimSpace(value)
+	switch strings.ToLower(text) {
+	case "", "-", "--", "—", "–", "n/a", "na":
+		return models.NutrientAmount{}, false
+	}
+
+	amount := models.NutrientAmount{Unit: strings.TrimSpace(unit)}
+	lower := strings.ToLower(text)
+	switch {
+	case strings.HasPrefix(lower, "less than"):
+		amount.LessThan = true
+		text = strings.TrimSpace(text[len("less than"):])
+	case strings.HasPrefix(text, "<"):
+		amount.LessThan = true
+		text = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(text, "<"), "="))
+	}
+
+	// Split the leading number from a trailing unit.
+	end := strings.IndexFunc(text, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != ','
+	})
+	number, suffix := text, ""
+	if end >= 0 {
+		number, suffix = text[:end], strings.TrimSpace(text[end:])
+	}
+	parsed, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", ""), 64)
+	if err != nil || parsed < 0 {
+		return models.NutrientAmount{}, false
+	}
+	amount.Value = parsed
+	if amount.Unit == "" && isUnit(suffix) {
+		amount.Unit = suffix
+	}
+	return amount, true
+}
+
+// Amount returns the structured value of a canonical field.
+func Amount(label models.Nutrition, field string) (models.NutrientAmount, bool) {
+	target := fieldPointer(&label, field)
+	if target == nil || *target == nil {
+		return models.NutrientAmount{}, false
+	}
+	return **target, true
+}
+
+// Value reads a nutrient off an item as a number. The structured label wins;
+// the four legacy strings back it up for rows stored before it existed. Bounds
+// such as "<1" read as the bound.
+func Value(item models.DailyItem, field string) (float64, bool) {
+	if amount, ok := Amount(item.Nutrition, field); ok {
+		return amount.Value, true
+	}
+
+	var raw string
+	switch field {
+	case Calories:
+		raw = item.Calories
+	case Protein:
+		raw = item.Protein
+	case Carbs:
+		raw = item.Carbs
+	case Fat:
+		raw = item.Fat
+	default:
+		return 0, false
+	}
+	amount, ok := ParseAmount(raw, "")
+	return amount.Value, ok
+}
+
+// ValueOrEstimate is Value, falling back to the item's estimate for field
+// (see package estimate). estimated reports whether the estimate was used.
+func ValueOrEstimate(item models.DailyItem, field string) (value float64, estimated, ok bool) {
+	if value, ok := Value(item, field); ok {
+		return value, false, true
+	}
+	if estimate, ok := item.Estimates[field]; ok {
+		return estimate.Value, true, true
+	}
+	return 0, false, false
+}
+
+func fieldPointer(label *models.Nutrition, field string) **models.NutrientAmount {
+	switch field {
+	case Calories:
+		return &label.Calories
+	case Protein:
+		return &label.Protein
+	case Carbs:
+		return &label.Carbs
+	case Fat:
+		return &label.Fat
+	case SaturatedFat:
+		return &label.SaturatedFat
+	case TransFat:
+		return &label.TransFat
+	case Cholesterol:
+		return &label.Cholesterol
+	case Sodium:
+		return &label.Sodium
+	case Sugars:
+		return &label.Sugars
+	case Fiber:
+		return &label.Fiber
+	}
+	return nil
+}
+
+// splitName separates "Protein (g)" into "Protein" and "g".
+func splitName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	open := strings.LastIndex(name, "(")
+	if open < 0 || !strings.HasSuffix(name, ")") {
+		return name, ""
+	}
+	return strings.TrimSpace(name[:open]), strings.TrimSpace(name[open+1 : len(name)-1])
+}
+
+func isUnit(suffix string) bool {
+	switch strings.ToLower(suffix) {
+	case "g", "mg", "mcg", "µg", "iu", "kcal", "cal", "re", "%":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package nutrition
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		value, unit string
+		want        models.NutrientAmount
+		ok          bool
+	}{
+		{"210", "", models.NutrientAmount{Value: 210}, true},
+		{" 4.5 ", "g", models.NutrientAmount{Value: 4.5, Unit: "g"}, true},
+		{"1,200", "mg", models.NutrientAmount{Value: 1200, Unit: "mg"}, true},
+		{"<1", "g", models.NutrientAmount{Value: 1, Unit: "g", LessThan: true}, true},
+		{"< 0.5", "", models.NutrientAmount{Value: 0.5, LessThan: true}, true},
+		{"less than 1", "g", models.NutrientAmount{Value: 1, Unit: "g", LessThan: true}, true},
+		{"120mg", "", models.NutrientAmount{Value: 120, Unit: "mg"}, true},
+		{"5 g", "mg", models.NutrientAmount{Value: 5, Unit: "mg"}, true},
+		{"-", "g", models.NutrientAmount{}, false},
+		{"", "g", models.NutrientAmount{}, false},
+		{"N/A", "", models.NutrientAmount{}, false},
+		{"trace", "", models.NutrientAmount{}, false},
+	}
+
+	for _, tc := range cases {
+		got, ok := ParseAmount(tc.value, tc.unit)
+		assert.Equal(t, tc.ok, ok, tc.value)
+		assert.Equal(t, tc.want, got, tc.value)
+	}
+}
+
+func TestFromLabelMapsUpstreamNames(t *testing.T) {
+	label := FromLabel([]models.Nutrient{
+		{Name: "Calories", Value: "310"},
+		{Name: "Calories from Fat", Value: "90"},
+		{Name: "Total Carbohydrates (g)", Value: "41"},
+		{Name: "Total Fat (g)", Value: "10"},
+		{Name: "Saturated Fat (g)", Value: "3.5"},
+		{Name: "Cholesterol (mg)", Value: "15"},
+		{Name: "Sugars (g)", Value: "<1"},
+		{Name: "Vitamin A", Value: "120", UOM: "IU"},
+		{Name: "Calcium (mg)", Value: "-"},
+	})
+
+	assert.Equal(t, &models.NutrientAmount{Value: 310, Unit: "kcal"}, label.Calories)
+	assert.Equal(t, &models.NutrientAmount{Value: 41, Unit: "g"}, label.Carbs)
+	assert.Equal(t, &models.NutrientAmount{Value: 10, Unit: "g"}, label.Fat)
+	assert.Equal(t, &models.NutrientAmount{Value: 3.5, Unit: "g"}, label.SaturatedFat)
+	assert.Equal(t, &models.NutrientAmount{Value: 15, Unit: "mg"}, label.Cholesterol)
+	assert.Equal(t, &models.NutrientAmount{Value: 1, Unit: "g", LessThan: true}, label.Sugars)
+	assert.Nil(t, label.Protein)
+	assert.Equal(t, map[string]models.NutrientAmount{"Vitamin A": {Value: 120, Unit: "IU"}}, label.Other,
+		"derived and dashed lines are dropped")
+	assert.True(t, FromLabel(nil).IsEmpty())
+}
+
+func TestValuePrefersLabelAndFallsBackToLegacyStrings(t *testing.T) {
+	legacy := models.DailyItem{Calories: "450", Protein: "<1", Fat: "-"}
+	value, ok := Value(legacy, Calories)
+	assert.True(t, ok)
+	assert.Equal(t, 450.0, value)
+	value, ok = Value(legacy, Protein)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, value)
+	_, ok = Value(legacy, Fat)
+	assert.False(t, ok)
+	_, ok = Value(legacy, Sodium)
+	assert.False(t, ok)
+
+	labeled := legacy
+	labeled.Nutrition = models.Nutrition{
+		Calories: &models.NutrientAmount{Value: 430, Unit: "kcal"},
+		Sodium:   &models.NutrientAmount{Value: 800, Unit: "mg"},
+	}
+	value, _ = Value(labeled, Calories)
+	assert.Equal(t, 430.0, value)
+	value, ok = Value(labeled, Sodium)
+	assert.True(t, ok)
+	assert.Equal(t, 800.0, value)
+}
@@ -0,0 +1,165 @@
+package nutrition
+
+import (
+	"backend/internal/models"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// massUnits maps mass unit spellings to their normalized unit and weight in
+// grams.
+var massUnits = map[string]struct {
+	unit  string
+	grams float64
+}{
+	"g":         {"g", 1},
+	"gm":        {"g", 1},
+	"gram":      {"g", 1},
+	"grams":     {"g", 1},
+	"kg":        {"kg", 1000},
+	"mg":        {"mg", 0.001},
+	"oz":        {"oz", 28.3495},
+	"ounce":     {"oz", 28.3495},
+	"ounces":    {"oz", 28.3495},
+	"wt oz":     {"oz", 28.3495},
+	"lb":        {"lb", 453.592},
+	"lbs":       {"lb", 453.592},
+	"pound":     {"lb", 453.592},
+	"pounds":    {"lb", 453.592},
+	"kilogram":  {"kg", 1000},
+	"kilograms": {"kg", 1000},
+}
+
+// otherUnits maps volume and count unit spellings to their normalized unit.
+// These have no fixed weight, so their portions carry no grams.
+var otherUnits = map[string]string{
+	"cup": "cup", "cups": "cup", "c": "cup",
+	"tbsp": "tbsp", "tbs": "tbsp", "tablespoon": "tbsp", "tablespoons": "tbsp",
+	"tsp": "tsp", "teaspoon": "tsp", "teaspoons": "tsp",
+	"fl oz": "fl oz", "floz": "fl oz", "fluid ounce": "fl oz", "fluid ounces": "fl oz",
+	"ml": "ml", "milliliter": "ml", "milliliters": "ml",
+	"l": "l", "liter": "l", "liters": "l",
+	"pint": "pint", "pints": "pint", "quart": "quart", "quarts": "quart",
+	"ladle": "ladle", "ladles": "ladle", "scoop": "scoop", "scoops": "scoop",
+	"each": "each", "ea": "each", "whole": "each", "item": "each", "items": "each",
+	"piece": "piece", "pieces": "piece", "pc": "piece", "pcs": "piece",
+	"slice": "slice", "slices": "slice",
+	"serving": "serving", "servings": "serving", "portion": "serving",
+	"order": "order", "orders": "order",
+	"bowl": "bowl", "bowls": "bowl", "plate": "plate", "plates": "plate",
+	"sandwich": "sandwich", "sandwiches": "sandwich", "wrap": "wrap", "wraps": "wrap",
+	"cookie": "cookie", "cookies": "cookie", "muffin": "muffin", "muffins": "muffin",
+	"bagel": "bagel", "bagels": "bagel", "taco": "taco", "tacos": "taco",
+	"patty": "patty", "patties": "patty", "link": "link", "links": "link",
+	"strip": "strip", "strips": "strip", "container": "container", "containers": "container",
+	"bottle": "bottle", "bottles": "bottle", "can": "can", "cans": "can",
+	"packet": "packet", "packets": "packet", "pkg": "packet", "package": "packet",
+}
+
+// countUnits may appear without a quantity ("Each"), which then means one.
+var countUnits = map[string]bool{"each": true, "piece": true, "slice": true, "serving": true, "order": true}
+
+// vulgarFractions are the single-rune fractions upstream sometimes prints.
+var vulgarFractions = map[rune]float64{'½': 0.5, '¼': 0.25, '¾': 0.75, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '⅛': 0.125}
+
+// ParsePortion reads an upstream portion size such as "1 each", "4 oz",
+// "1/2 cup", "1 1/2 slices" or "8oz". Ranges, several units and unknown units
+// are not guessed at: the result then has Parsed false.
+func ParsePortion(text string) models.Portion {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return models.Portion{}
+	}
+	for r, value := range vulgarFractions {
+		text = strings.ReplaceAll(text, string(r), " "+strconv.FormatFloat(value, 'f', -1, 64))
+	}
+
+	// Split the leading number words from the unit, allowing the unit to be
+	// glued on ("8oz").
+	end := 0
+	for end < len(text) && (unicode.IsDigit(rune(text[end])) || strings.ContainsRune("./ ", rune(text[end]))) {
+		end++
+	}
+	quantity, ok := parseQuantity(text[:end])
+	unitText := strings.Join(strings.Fields(strings.Trim(text[end:], ".")), " ")
+	if end == 0 {
+		quantity, ok = 1, true
+		if !countUnits[otherUnits[unitText]] {
+			return models.Portion{}
+		}
+	}
+	if !ok || quantity <= 0 {
+		return models.Portion{}
+	}
+
+	if mass, known := massUnits[unitText]; known {
+		grams := math.Round(quantity*mass.grams*10) / 10
+		return models.Portion{Quantity: quantity, Unit: mass.unit, Grams: &grams, Parsed: true}
+	}
+	if unit, known := otherUnits[unitText]; known {
+		return models.Portion{Quantity: quantity, Unit: unit, Parsed: true}
+	}
+	return models.Portion{}
+}
+
+// PortionOf returns item's parsed portion, parsing PortionSize for items
+// stored before portions were parsed at scrape time.
+func PortionOf(item models.DailyItem) models.Portion {
+	if item.Serving.Parsed {
+		return item.Serving
+	}
+	return ParsePortion(item.PortionSize)
+}
+
+// ScalePortion multiplies a parsed portion by servings. Unparsed portions are
+// returned unchanged.
+func ScalePortion(portion models.Portion, servings float64) models.Portion {
+	if !portion.Parsed {
+		return portion
+	}
+	portion.Quantity = math.Round(portion.Quantity*servings*1000) / 1000
+	if portion.Grams != nil {
+		grams := math.Round(*portion.Grams*servings*10) / 10
+		portion.Grams = &grams
+	}
+	return portion
+}
+
+// parseQuantity reads "2", "1.5", "1/2" or a mixed number such as "1 1/2".
+func parseQuantity(text string) (float64, bool) {
+	parts := strings.Fields(text)
+	if len(parts) == 0 || len(parts) > 2 {
+		return 0, false
+	}
+	total := 0.0
+	for i, part := range parts {
+		value, ok := parseNumber(part)
+		if !ok {
+			return 0, false
+		}
+		// Only the second word of a mixed number may be a fraction below one.
+		if i == 1 && (value >= 1 || !strings.Contains(part, "/") && !strings.HasPrefix(part, "0.")) {
+			return 0, false
+		}
+		total += value
+	}
+	return total, true
+}
+
+func parseNumber(text string) (float64, bool) {
+	if numerator, denominator, isFraction := strings.Cut(text, "/"); isFraction {
+		n, err := strconv.ParseFloat(numerator, 64)
+		if err != nil {
+			return 0, false
+		}
+		d, err := strconv.ParseFloat(denominator, 64)
+		if err != nil || d == 0 {
+			return 0, false
+		}
+		return n / d, true
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	return value, err == nil
+}
@@ -0,0 +1,52 @@
+package nutrition
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func grams(value float64) *float64 { return &value }
+
+func TestParsePortion(t *testing.T) {
+	cases := []struct {
+		text string
+		want models.Portion
+	}{
+		{"1 each", models.Portion{Quantity: 1, Unit: "each", Parsed: true}},
+		{"Each", models.Portion{Quantity: 1, Unit: "each", Parsed: true}},
+		{"4 oz", models.Portion{Quantity: 4, Unit: "oz", Grams: grams(113.4), Parsed: true}},
+		{"8oz.", models.Portion{Quantity: 8, Unit: "oz", Grams: grams(226.8), Parsed: true}},
+		{"150 Grams", models.Portion{Quantity: 150, Unit: "g", Grams: grams(150), Parsed: true}},
+		{"1/2 cup", models.Portion{Quantity: 0.5, Unit: "cup", Parsed: true}},
+		{"½ Cup", models.Portion{Quantity: 0.5, Unit: "cup", Parsed: true}},
+		{"1 1/2 slices", models.Portion{Quantity: 1.5, Unit: "slice", Parsed: true}},
+		{"6 fl oz", models.Portion{Quantity: 6, Unit: "fl oz", Parsed: true}},
+		{"2 Tbsp", models.Portion{Quantity: 2, Unit: "tbsp", Parsed: true}},
+		{"", models.Portion{}},
+		{"cup", models.Portion{}},
+		{"2-3 each", models.Portion{}},
+		{"1 cup (8 oz)", models.Portion{}},
+		{"1 handful", models.Portion{}},
+		{"0 oz", models.Portion{}},
+		{"1/0 cup", models.Portion{}},
+		{"1 2 cup", models.Portion{}},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, ParsePortion(tc.text), tc.text)
+	}
+}
+
+func TestPortionOfAndScalePortion(t *testing.T) {
+	legacy := models.DailyItem{PortionSize: "4 oz"}
+	portion := PortionOf(legacy)
+	assert.Equal(t, models.Portion{Quantity: 4, Unit: "oz", Grams: grams(113.4), Parsed: true}, portion,
+		"items stored before parsing are parsed on read")
+
+	assert.Equal(t, models.Portion{Quantity: 2, Unit: "oz", Grams: grams(56.7), Parsed: true}, ScalePortion(portion, 0.5))
+	assert.Equal(t, models.Portion{Quantity: 8, Unit: "oz", Grams: grams(226.8), Parsed: true}, ScalePortion(portion, 2))
+	assert.Equal(t, 113.4, *portion.Grams, "scaling does not change the original")
+	assert.Equal(t, models.Portion{}, ScalePortion(models.Portion{}, 2))
+}
@@ -0,0 +1,407 @@
+// Package openapi builds an OpenAPI 3 document from Go request and response
+// types, so client models can be generated from the same structs the server
+// encodes. Schemas are derived by reflection following encoding/json rules:
+// json tag names, "-" to skip, omitempty for optional fields and promotion of
+// embedded structs.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the OpenAPI specification version documents declare.
+const Version = "3.0.3"
+
+// Document is the root of an OpenAPI document. Only the parts this server
+// uses are modeled.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info describes the API as a whole.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lowercase HTTP methods to operations on one path.
+type PathItem map[string]*Operation
+
+// Operation is one method on one path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes a JSON request body.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes one response status.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema for one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how authenticated operations are authorized.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Schema is a JSON schema as OpenAPI 3.0 understands it.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Param declares a path or query parameter of a route.
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+	// Type is the JSON type of the value: "string" (the default), "integer",
+	// "number" or "boolean".
+	Type string
+}
+
+// Route declares one operation. Request and Response are example values of
+// the body types (e.g. FooRequest{}); a nil Request means no body and a nil
+// Response means the success status has no content.
+type Route struct {
+	Method      string
+	Path        string // mux style, e.g. /api/v2/items/{name}
+	OperationID string
+	Summary     string
+	Description string
+	Tag         string
+	Auth        bool
+	// OptionalAuth documents a route that accepts, but does not require, the
+	// bearer token.
+	OptionalAuth bool
+	PathParams   []Param
+	Query        []Param
+	Request      any
+	Response     any
+	// Status is the success status; 0 means 200.
+	Status int
+	// Errors lists the error statuses the operation documents, each returning
+	// the builder's error schema.
+	Errors []int
+}
+
+// Builder accumulates routes into a Document.
+type Builder struct {
+	doc         Document
+	names       map[reflect.Type]string
+	errorSchema *Schema
+	authScheme  string
+}
+
+// NewBuilder starts a document with the given title, version and description.
+func NewBuilder(title, version, description string) *Builder {
+	return &Builder{
+		doc: Document{
+			OpenAPI: Version,
+			Info:    Info{Title: title, Version: version, Description: description},
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+			},
+		},
+		names: make(map[reflect.Type]string),
+	}
+}
+
+// BearerAuth registers a bearer-token security scheme used by routes with
+// Auth set.
+func (b *Builder) BearerAuth(name, format, description string) {
+	if b.doc.Components.SecuritySchemes == nil {
+		b.doc.Components.SecuritySchemes = make(map[string]SecurityScheme)
+	}
+	b.doc.Components.SecuritySchemes[name] = SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: format,
+		Description:  description,
+	}
+	b.authScheme = name
+}
+
+// ErrorBody sets the type every documented error status returns.
+func (b *Builder) ErrorBody(example any) {
+	b.errorSchema = b.Schema(reflect.TypeOf(example))
+}
+
+// Tag adds a described operation group.
+func (b *Builder) Tag(name, description string) {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+}
+
+// Add documents a route. Adding the same method and path twice panics, as it
+// would silently hide an operation.
+func (b *Builder) Add(route Route) {
+	method := strings.ToLower(route.Method)
+	item, ok := b.doc.Paths[route.Path]
+	if !ok {
+		item = make(PathItem)
+		b.doc.Paths[route.Path] = item
+	}
+	if _, exists := item[method]; exists {
+		panic(fmt.Sprintf("openapi: %s %s documented twice", route.Method, route.Path))
+	}
+
+	operation := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   make(map[string]Response),
+	}
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+	for _, param := range route.PathParams {
+		param.Required = true
+		operation.Parameters = append(operation.Parameters, parameter(param, "path"))
+	}
+	for _, param := range route.Query {
+		operation.Parameters = append(operation.Parameters, parameter(param, "query"))
+	}
+	if route.Auth && b.authScheme != "" {
+		operation.Security = []map[string][]string{{b.authScheme: {}}}
+	} else if route.OptionalAuth && b.authScheme != "" {
+		operation.Security = []map[string][]string{{}, {b.authScheme: {}}}
+	}
+
+	if route.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(b.Schema(reflect.TypeOf(route.Request))),
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		success.Content = jsonContent(b.Schema(reflect.TypeOf(route.Response)))
+	}
+	operation.Responses[strconv.Itoa(status)] = success
+
+	for _, code := range route.Errors {
+		response := Response{Description: http.StatusText(code)}
+		if b.errorSchema != nil {
+			response.Content = jsonContent(b.errorSchema)
+		}
+		operation.Responses[strconv.Itoa(code)] = response
+	}
+
+	item[method] = operation
+}
+
+// Document returns the accumulated document.
+func (b *Builder) Document() Document {
+	return b.doc
+}
+
+// Schema returns the schema for t, registering named struct types as
+// components and referring to them by $ref.
+func (b *Builder) Schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == reflect.TypeOf(json.RawMessage{}):
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := b.Schema(t.Elem())
+		if schema.Ref == "" {
+			nullable := *schema
+			nullable.Nullable = true
+			return &nullable
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + b.component(t)}
+	}
+	// Interfaces and anything else accept any JSON value.
+	return &Schema{}
+}
+
+// component registers a named struct type and returns its component name.
+// Types from different packages that share a name are qualified with their
+// package name.
+func (b *Builder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	for _, other := range b.names {
+		if other == name {
+			name = exportedName(path.Base(t.PkgPath())) + t.Name()
+			break
+		}
+	}
+	b.names[t] = name
+
+	// Register before building so recursive types terminate.
+	b.doc.Components.Schemas[name] = &Schema{}
+	*b.doc.Components.Schemas[name] = *b.structSchema(t)
+	return name
+}
+
+// structSchema describes a struct's JSON object form.
+func (b *Builder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.addFields(schema, t)
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (b *Builder) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		// Untagged embedded structs have their fields promoted, as in
+		// encoding/json.
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = b.Schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func parameter(param Param, in string) Parameter {
+	kind := param.Type
+	if kind == "" {
+		kind = "string"
+	}
+	return Parameter{
+		Name:        param.Name,
+		In:          in,
+		Description: param.Description,
+		Required:    param.Required,
+		Schema:      &Schema{Type: kind},
+	}
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBase struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type testItem struct {
+	testBase
+	Name     string          `json:"name"`
+	Calories *float64        `json:"calories"`
+	Tags     []string        `json:"tags,omitempty"`
+	Counts   map[string]int  `json:"counts"`
+	Children []testItem      `json:"children,omitempty"`
+	Ignored  string          `json:"-"`
+	Extra    json.RawMessage `json:"extra,omitempty"`
+	hidden   string
+}
+
+type testError struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+func TestSchemaFollowsEncodingJSONRules(t *testing.T) {
+	builder := NewBuilder("Test", "1.0.0", "")
+	ref := builder.Schema(reflect.TypeOf(testItem{}))
+	assert.Equal(t, "#/components/schemas/testItem", ref.Ref)
+
+	schema := builder.Document().Components.Schemas["testItem"]
+	require.NotNil(t, schema)
+	assert.Equal(t, "object", schema.Type)
+
+	// Embedded fields are promoted; "-" and unexported fields are skipped.
+	assert.Contains(t, schema.Properties, "id")
+	assert.Equal(t, "date-time", schema.Properties["createdAt"].Format)
+	assert.NotContains(t, schema.Properties, "Ignored")
+	assert.NotContains(t, schema.Properties, "hidden")
+
+	assert.True(t, schema.Properties["calories"].Nullable)
+	assert.Equal(t, "number", schema.Properties["calories"].Type)
+	assert.Equal(t, "array", schema.Properties["tags"].Type)
+	assert.Equal(t, "integer", schema.Properties["counts"].AdditionalProperties.Type)
+	assert.Equal(t, "#/components/schemas/testItem", schema.Properties["children"].Items.Ref, "recursive types refer to themselves")
+
+	assert.Equal(t, []string{"calories", "counts", "createdAt", "id", "name"}, schema.Required, "omitempty fields are optional")
+}
+
+func TestAddDocumentsParametersBodiesAndErrors(t *testing.T) {
+	builder := NewBuilder("Test", "1.0.0", "")
+	builder.BearerAuth("token", "JWT", "")
+	builder.ErrorBody(testError{})
+	builder.Add(Route{
+		Method:      http.MethodPut,
+		Path:        "/items/{name}",
+		OperationID: "putItem",
+		Auth:        true,
+		PathParams:  []Param{{Name: "name"}},
+		Query:       []Param{{Name: "limit", Type: "integer"}},
+		Request:     testItem{},
+		Response:    testItem{},
+		Status:      http.StatusCreated,
+		Errors:      []int{http.StatusBadRequest},
+	})
+
+	doc := builder.Document()
+	operation := doc.Paths["/items/{name}"]["put"]
+	require.NotNil(t, operation)
+
+	require.Len(t, operation.Parameters, 2)
+	assert.Equal(t, "path", operation.Parameters[0].In)
+	assert.True(t, operation.Parameters[0].Required, "path parameters are always required")
+	assert.Equal(t, "integer", operation.Parameters[1].Schema.Type)
+
+	require.NotNil(t, operation.RequestBody)
+	assert.Equal(t, "#/components/schemas/testItem", operation.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Contains(t, operation.Responses, "201")
+	assert.Equal(t, "#/components/schemas/testError", operation.Responses["400"].Content["application/json"].Schema.Ref)
+	assert.Equal(t, []map[string][]string{{"token": {}}}, operation.Security)
+	assert.Equal(t, "bearer", doc.Components.SecuritySchemes["token"].Scheme)
+
+	_, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	assert.Panics(t, func() {
+		builder.Add(Route{Method: http.MethodPut, Path: "/items/{name}", OperationID: "again"})
+	})
+
+	builder.Add(Route{Method: http.MethodGet, Path: "/items", OperationID: "listItems", OptionalAuth: true})
+	assert.Equal(t, []map[string][]string{{}, {"token": {}}}, builder.Document().Paths["/items"]["get"].Security, "anonymous or signed in")
+}
@@ -0,0 +1,375 @@
+// Package planner suggests plates: small combinations of menu items whose
+// combined nutrition lands close to a calorie and macro target.
+//
+// Finding the best combination exactly is a subset-sum style problem, so the
+// search is a beam search over item combinations. Plates grow one item at a
+// time; after each round only the BeamWidth most promising partial plates are
+// extended further. Every plate seen along the way is a candidate answer, and
+// the best TopK are returned. With a few dozen items per meal period this
+// answers in well under a millisecond per hall.
+package planner
+
+import (
+	"backend/internal/models"
+	"backend/internal/nutrition"
+	"container/heap"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Search bounds. Requests may lower MaxItems and TopK but not raise them past
+// these limits.
+const (
+	DefaultMaxItems = 3
+	MaxItemsLimit   = 6
+	DefaultTopK     = 5
+	TopKLimit       = 20
+	BeamWidth       = 256
+	// MaxServingOptions bounds Options.Servings, since every option adds a
+	// copy of each item to the search.
+	MaxServingOptions = 4
+)
+
+// Nutrient weights in the score. Calories matter most; the macros equally.
+const (
+	calorieWeight = 2.0
+	macroWeight   = 1.0
+)
+
+// Options configures a suggestion run.
+type Options struct {
+	Target models.NutrientTotals
+	// MaxItems bounds the plate size; 0 means DefaultMaxItems.
+	MaxItems int
+	// TopK is how many plates to return; 0 means DefaultTopK.
+	TopK int
+	// Exclude lists filter tags (allergens, usually) no item may carry. A tag
+	// also excludes its "may contain" variant: "Milk" rules out "Milk*".
+	Exclude []string
+	// Require lists filter tags (diets, usually) every item must carry.
+	Require []string
+	// Servings lists the multipliers an item may be taken at, e.g. 0.5, 1
+	// and 2. Empty means whole single servings only. A plate holds at most
+	// one amount of each item.
+	Servings []float64
+}
+
+// Plate is one suggested combination of items.
+type Plate struct {
+	Items []models.DailyItem `json:"items"`
+	// Servings[i] is the multiplier Items[i] is taken at; Totals include it.
+	Servings []float64             `json:"servings"`
+	Totals   models.NutrientTotals `json:"totals"`
+	// Score is the weighted relative distance from the target; lower is
+	// better and 0 is a perfect hit.
+	Score float64 `json:"score"`
+	// Estimated is set when Totals include estimated values for nutrients
+	// an item's label left empty.
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// candidate is a usable item at one serving size, with its nutrition read
+// once and scaled. The serving sizes of one item are adjacent in the
+// candidate list and share a group.
+type candidate struct {
+	item      models.DailyItem
+	group     int
+	servings  float64
+	totals    models.NutrientTotals
+	estimated bool
+}
+
+// partial is a plate under construction: indexes into the candidate list, in
+// increasing order so every combination is built exactly once.
+type partial struct {
+	picks  []int
+	totals models.NutrientTotals
+	score  float64
+}
+
+// growth is a partial plate extended by one item, before it is known whether
+// it makes the beam. It refers to its parent by index so losing candidates
+// cost no allocation.
+type growth struct {
+	parent    int
+	item      int
+	totals    models.NutrientTotals
+	potential float64
+}
+
+// growthHeap is a max-heap on potential, so its root is the first to go.
+type growthHeap []growth
+
+func (h growthHeap) Len() int           { return len(h) }
+func (h growthHeap) Less(i, j int) bool { return h[i].potential > h[j].potential }
+func (h growthHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *growthHeap) Push(x any)        { *h = append(*h, x.(growth)) }
+func (h *growthHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// Suggest returns up to TopK plates built from items, best first. Missing
+// label values fall back to the item's estimates. Items without even an
+// estimated calorie value, excluded by tag, or repeating an earlier item's
+// name are not considered.
+func Suggest(items []models.DailyItem, options Options) []Plate {
+	maxItems := clamp(options.MaxItems, DefaultMaxItems, MaxItemsLimit)
+	topK := clamp(options.TopK, DefaultTopK, TopKLimit)
+	target := options.Target
+
+	candidates := usableItems(items, options.Exclude, options.Require, servingSizes(options.Servings))
+	if len(candidates) == 0 || target.Calories <= 0 && target.Protein <= 0 && target.Carbs <= 0 && target.Fat <= 0 {
+		return []Plate{}
+	}
+
+	var best []partial
+	keep := func(parent partial, item int, totals models.NutrientTotals, plateScore float64) {
+		size := len(parent.picks) + 1
+		if len(best) == topK {
+			worst := best[len(best)-1]
+			if plateScore > worst.score || plateScore == worst.score && size >= len(worst.picks) {
+				return
+			}
+			best = best[:len(best)-1]
+		}
+		plate := partial{
+			picks:  append(append(make([]int, 0, size), parent.picks...), item),
+			totals: totals,
+			score:  plateScore,
+		}
+		at := sort.Search(len(best), func(i int) bool { return better(plate, best[i]) })
+		best = append(best, partial{})
+		copy(best[at+1:], best[at:])
+		best[at] = plate
+	}
+
+	beam := []partial{{}}
+	for size := 1; size <= maxItems && len(beam) > 0; size++ {
+		// Rank partial plates by how much room they leave to improve:
+		// overshoot is permanent, undershoot can still be filled. Only the
+		// BeamWidth best survive, kept in a max-heap on potential.
+		var frontier growthHeap
+		for parent, plate := range beam {
+			start, lastGroup := 0, -1
+			if len(plate.picks) > 0 {
+				last := plate.picks[len(plate.picks)-1]
+				start, lastGroup = last+1, candidates[last].group
+			}
+			for i := start; i < len(candidates); i++ {
+				if candidates[i].group == lastGroup {
+					// Another amount of the item just picked.
+					continue
+				}
+				totals := add(plate.totals, candidates[i].totals)
+				// Adding food never lowers a total, so a plate already well
+				// over its calorie target cannot lead anywhere useful.
+				if target.Calories > 0 && totals.Calories > target.Calories*1.5 {
+					continue
+				}
+				keep(plate, i, totals, score(totals, target))
+
+				step := growth{parent: parent, item: i, totals: totals, potential: potential(totals, target)}
+				if len(frontier) < BeamWidth {
+					heap.Push(&frontier, step)
+				} else if step.potential < frontier[0].potential {
+					frontier[0] = step
+					heap.Fix(&frontier, 0)
+				}
+			}
+		}
+
+		next := make([]partial, 0, len(frontier))
+		for _, step := range frontier {
+			parent := beam[step.parent]
+			next = append(next, partial{
+				picks:  append(append(make([]int, 0, size), parent.picks...), step.item),
+				totals: step.totals,
+			})
+		}
+		beam = next
+	}
+
+	plates := make([]Plate, 0, len(best))
+	for _, plate := range best {
+		items := make([]models.DailyItem, 0, len(plate.picks))
+		servings := make([]float64, 0, len(plate.picks))
+		estimated := false
+		for _, pick := range plate.picks {
+			items = append(items, candidates[pick].item)
+			servings = append(servings, candidates[pick].servings)
+			estimated = estimated || candidates[pick].estimated
+		}
+		plates = append(plates, Plate{
+			Items:     items,
+			Servings:  servings,
+			Totals:    round(plate.totals),
+			Score:     math.Round(plate.score*1000) / 1000,
+			Estimated: estimated,
+		})
+	}
+	return plates
+}
+
+// usableItems filters items down to the ones a plate may use, in their
+// original order, with one candidate per serving size.
+func usableItems(items []models.DailyItem, exclude, require []string, servings []float64) []candidate {
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for _, item := range items {
+		key := strings.ToLower(strings.TrimSpace(item.Name))
+		if key == "" || seen[key] {
+			continue
+		}
+		if !Allowed(item, exclude, require) {
+			continue
+		}
+		calories, estimated, ok := nutrition.ValueOrEstimate(item, nutrition.Calories)
+		if !ok {
+			continue
+		}
+		group := len(seen)
+		seen[key] = true
+		value := func(field string) float64 {
+			value, fieldEstimated, _ := nutrition.ValueOrEstimate(item, field)
+			estimated = estimated || fieldEstimated
+			return value
+		}
+		totals := models.NutrientTotals{
+			Calories: calories,
+			Protein:  value(nutrition.Protein),
+			Carbs:    value(nutrition.Carbs),
+			Fat:      value(nutrition.Fat),
+		}
+		for _, amount := range servings {
+			candidates = append(candidates, candidate{item: item, group: group, servings: amount, totals: scale(totals, amount), estimated: estimated})
+		}
+	}
+	return candidates
+}
+
+// servingSizes cleans up requested multipliers: positive, distinct, in
+// increasing order and at most MaxServingOptions of them. None means 1.
+func servingSizes(requested []float64) []float64 {
+	var sizes []float64
+	for _, amount := range requested {
+		if amount > 0 && !math.IsInf(amount, 0) && !slices.Contains(sizes, amount) {
+			sizes = append(sizes, amount)
+		}
+	}
+	if len(sizes) == 0 {
+		return []float64{1}
+	}
+	slices.Sort(sizes)
+	if len(sizes) > MaxServingOptions {
+		sizes = sizes[:MaxServingOptions]
+	}
+	return sizes
+}
+
+// Allowed reports whether item carries none of the excluded tags (nor their
+// "may contain" variants) and every required tag.
+func Allowed(item models.DailyItem, exclude, require []string) bool {
+	return models.DietaryProfile{Avoid: exclude, Require: require}.Allows(item)
+}
+
+// mealShares is the part of a day's goals each meal is planned against when
+// the user has not split their goals themselves.
+var mealShares = map[string]float64{
+	"breakfast": 0.25,
+	"brunch":    0.35,
+	"lunch":     0.35,
+	"dinner":    0.40,
+}
+
+// MealTarget returns the part of a day's goals a meal should cover: the
+// user's own meal split when they saved one, otherwise mealShares. Meals
+// without a known share get a third of the day.
+func MealTarget(goals models.NutritionGoals, meal string) models.NutrientTotals {
+	share, ok := goals.MealShare(meal)
+	if !ok {
+		share, ok = mealShares[strings.ToLower(strings.TrimSpace(meal))]
+	}
+	if !ok {
+		share = 1.0 / 3
+	}
+	return round(models.NutrientTotals{
+		Calories: goals.Calories * share,
+		Protein:  goals.Protein * share,
+		Carbs:    goals.Carbs * share,
+		Fat:      goals.Fat * share,
+	})
+}
+
+// score is the weighted relative distance of totals from target. Nutrients
+// with no target do not count.
+func score(totals, target models.NutrientTotals) float64 {
+	return distance(totals.Calories, target.Calories, calorieWeight, 1) +
+		distance(totals.Protein, target.Protein, macroWeight, 1) +
+		distance(totals.Carbs, target.Carbs, macroWeight, 1) +
+		distance(totals.Fat, target.Fat, macroWeight, 1)
+}
+
+// potential is score with undershoot discounted, since later items can still
+// make it up.
+func potential(totals, target models.NutrientTotals) float64 {
+	const undershoot = 0.5
+	return distance(totals.Calories, target.Calories, calorieWeight, undershoot) +
+		distance(totals.Protein, target.Protein, macroWeight, undershoot) +
+		distance(totals.Carbs, target.Carbs, macroWeight, undershoot) +
+		distance(totals.Fat, target.Fat, macroWeight, undershoot)
+}
+
+func distance(total, target, weight, undershoot float64) float64 {
+	if target <= 0 {
+		return 0
+	}
+	diff := (total - target) / target
+	if diff < 0 {
+		return -diff * weight * undershoot
+	}
+	return diff * weight
+}
+
+// better orders finished plates: lower score first, and on ties the plate
+// with fewer items, which is easier to get.
+func better(a, b partial) bool {
+	if a.score != b.score {
+		return a.score < b.score
+	}
+	return len(a.picks) < len(b.picks)
+}
+
+func add(a, b models.NutrientTotals) models.NutrientTotals {
+	return models.NutrientTotals{
+		Calories: a.Calories + b.Calories,
+		Protein:  a.Protein + b.Protein,
+		Carbs:    a.Carbs + b.Carbs,
+		Fat:      a.Fat + b.Fat,
+	}
+}
+
+func scale(totals models.NutrientTotals, factor float64) models.NutrientTotals {
+	return models.NutrientTotals{
+		Calories: totals.Calories * factor,
+		Protein:  totals.Protein * factor,
+		Carbs:    totals.Carbs * factor,
+		Fat:      totals.Fat * factor,
+	}
+}
+
+func round(totals models.NutrientTotals) models.NutrientTotals {
+	r := func(value float64) float64 { return math.Round(value*10) / 10 }
+	return models.NutrientTotals{Calories: r(totals.Calories), Protein: r(totals.Protein), Carbs: r(totals.Carbs), Fat: r(totals.Fat)}
+}
+
+func clamp(value, fallback, limit int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return min(value, limit)
+}
@@ -0,0 +1,138 @@
+package planner
+
+import (
+	"backend/internal/models"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func food(name, calories, protein, carbs, fat string, filters ...string) models.DailyItem {
+	return models.DailyItem{Name: name, Calories: calories, Protein: protein, Carbs: carbs, Fat: fat, Filters: filters}
+}
+
+func names(plate Plate) []string {
+	var result []string
+	for _, item := range plate.Items {
+		result = append(result, item.Name)
+	}
+	return result
+}
+
+func TestSuggestFindsTheClosestCombination(t *testing.T) {
+	items := []models.DailyItem{
+		food("Grilled Chicken", "250", "40", "0", "9"),
+		food("Brown Rice", "220", "5", "45", "2"),
+		food("Broccoli", "50", "4", "10", "0"),
+		food("Cheesecake", "450", "7", "40", "30"),
+		food("Pizza Slice", "300", "12", "36", "12"),
+		food("grilled chicken", "250", "40", "0", "9"),
+		food("Mystery Soup", "", "", "", ""),
+	}
+	target := models.NutrientTotals{Calories: 520, Protein: 49, Carbs: 55, Fat: 11}
+
+	plates := Suggest(items, Options{Target: target, TopK: 3})
+	require.Len(t, plates, 3)
+	assert.Equal(t, []string{"Grilled Chicken", "Brown Rice", "Broccoli"}, names(plates[0]))
+	assert.Equal(t, models.NutrientTotals{Calories: 520, Protein: 49, Carbs: 55, Fat: 11}, plates[0].Totals)
+	assert.Zero(t, plates[0].Score)
+	assert.LessOrEqual(t, plates[0].Score, plates[1].Score)
+	assert.LessOrEqual(t, plates[1].Score, plates[2].Score)
+
+	plates = Suggest(items, Options{Target: target, MaxItems: 1})
+	for _, plate := range plates {
+		assert.Len(t, plate.Items, 1)
+	}
+}
+
+func TestSuggestRespectsExclusionsAndRequirements(t *testing.T) {
+	items := []models.DailyItem{
+		food("Tofu Bowl", "450", "25", "50", "15", "Vegan"),
+		food("Peanut Noodles", "500", "18", "60", "20", "Vegan", "Peanuts*"),
+		food("Chicken Bowl", "480", "38", "45", "14"),
+	}
+	target := models.NutrientTotals{Calories: 480, Protein: 30}
+
+	plates := Suggest(items, Options{Target: target, Exclude: []string{"peanuts"}, Require: []string{"vegan"}})
+	require.NotEmpty(t, plates)
+	for _, plate := range plates {
+		assert.Equal(t, []string{"Tofu Bowl"}, names(plate), "may-contain allergens are excluded too")
+	}
+
+	assert.Empty(t, Suggest(items, Options{Target: models.NutrientTotals{}}), "an empty target has nothing to aim at")
+}
+
+func TestSuggestScalesServings(t *testing.T) {
+	items := []models.DailyItem{
+		food("Grilled Chicken", "250", "40", "0", "9"),
+		food("Brown Rice", "220", "5", "45", "2"),
+	}
+	target := models.NutrientTotals{Calories: 720, Protein: 85, Carbs: 45, Fat: 20}
+
+	plates := Suggest(items, Options{Target: target, Servings: []float64{2, 1, 0.5, 2, -1}})
+	require.NotEmpty(t, plates)
+	assert.Equal(t, []string{"Grilled Chicken", "Brown Rice"}, names(plates[0]))
+	assert.Equal(t, []float64{2, 1}, plates[0].Servings)
+	assert.Equal(t, models.NutrientTotals{Calories: 720, Protein: 85, Carbs: 45, Fat: 20}, plates[0].Totals)
+	for _, plate := range plates {
+		assert.Len(t, plate.Servings, len(plate.Items))
+		if len(plate.Items) == 2 {
+			assert.NotEqual(t, plate.Items[0].Name, plate.Items[1].Name, "one amount of each item per plate")
+		}
+	}
+
+	plates = Suggest(items, Options{Target: target})
+	for _, plate := range plates {
+		for _, servings := range plate.Servings {
+			assert.Equal(t, 1.0, servings, "whole servings by default")
+		}
+	}
+}
+
+func TestSuggestFallsBackToEstimates(t *testing.T) {
+	estimated := food("Chef's Special", "", "", "", "")
+	estimated.Estimates = map[string]models.NutrientEstimate{
+		"calories": {Value: 500, Confidence: 0.5, Source: "similar", Samples: 2},
+		"protein":  {Value: 40, Confidence: 0.5, Source: "similar", Samples: 2},
+	}
+	items := []models.DailyItem{estimated, food("Side Salad", "100", "2", "10", "5")}
+
+	plates := Suggest(items, Options{Target: models.NutrientTotals{Calories: 500, Protein: 40}, MaxItems: 1})
+	require.Len(t, plates, 2)
+	assert.Equal(t, []string{"Chef's Special"}, names(plates[0]))
+	assert.True(t, plates[0].Estimated)
+	assert.False(t, plates[1].Estimated)
+}
+
+func TestSuggestStaysFastOnLargeMenus(t *testing.T) {
+	var items []models.DailyItem
+	for i := 0; i < 150; i++ {
+		items = append(items, food(fmt.Sprintf("Item %d", i),
+			fmt.Sprint(100+(i*37)%500), fmt.Sprint((i*7)%40), fmt.Sprint((i*11)%70), fmt.Sprint((i*5)%25)))
+	}
+
+	start := time.Now()
+	plates := Suggest(items, Options{Target: models.NutrientTotals{Calories: 800, Protein: 45, Carbs: 90, Fat: 25}, MaxItems: MaxItemsLimit, TopK: TopKLimit})
+	elapsed := time.Since(start)
+
+	assert.Len(t, plates, TopKLimit)
+	assert.Less(t, elapsed, time.Second, "the beam keeps the search bounded")
+	for _, plate := range plates {
+		assert.LessOrEqual(t, len(plate.Items), MaxItemsLimit)
+	}
+}
+
+func TestMealTargetSplitsTheDay(t *testing.T) {
+	goals := models.NutritionGoals{Calories: 2000, Protein: 100, Carbs: 250, Fat: 80}
+	assert.Equal(t, models.NutrientTotals{Calories: 500, Protein: 25, Carbs: 62.5, Fat: 20}, MealTarget(goals, "Breakfast"))
+	assert.Equal(t, models.NutrientTotals{Calories: 800, Protein: 40, Carbs: 100, Fat: 32}, MealTarget(goals, "dinner"))
+	assert.Equal(t, 666.7, MealTarget(goals, "Late Night").Calories)
+
+	goals.MealSplit = &models.MealSplit{Breakfast: 10, Lunch: 40, Dinner: 50}
+	assert.Equal(t, models.NutrientTotals{Calories: 200, Protein: 10, Carbs: 25, Fat: 8}, MealTarget(goals, "Breakfast"))
+	assert.Equal(t, 1000.0, MealTarget(goals, "dinner").Calories, "the user's split wins over the defaults")
+	assert.Equal(t, 666.7, MealTarget(goals, "Late Night").Calories)
+}
@@ -57,6 +57,19 @@ func BuildNotification(meal string, items []models.DailyItem) (title, body strin
 	return title, body
 }
 
+// BuildNotificationForProfile is BuildNotification over only the items that are
+// safe for the user's dietary profile, so the copy can never announce an item
+// the user avoids. It returns empty strings when no safe item is left.
+func BuildNotificationForProfile(meal string, items []models.DailyItem, profile models.DietaryProfile) (title, body string) {
+	safe := make([]models.DailyItem, 0, len(items))
+	for _, item := range items {
+		if profile.Allows(item) {
+			safe = append(safe, item)
+		}
+	}
+	return BuildNotification(meal, safe)
+}
+
 // dedupeItems drops repeats keyed on (case-insensitive name, case-insensitive
 // location) so the same dish at several stations counts once, while preserving
 // first-seen order for stable output.
@@ -133,3 +133,21 @@ func TestBuildNotificationNeverExceedsHardMax(t *testing.T) {
 		t.Errorf("body length %d exceeds hard max %d: %q", len(body), bodyHardMax, body)
 	}
 }
+
+func TestBuildNotificationForProfileSkipsUnsafeItems(t *testing.T) {
+	pizza := item("Pizza", "Sargent")
+	pizza.Filters = []string{"Milk", "Wheat"}
+	salad := item("Salad", "Allison")
+	salad.Filters = []string{"Vegan", "Sesame*"}
+	profile := models.DietaryProfile{Avoid: []string{"Milk"}}
+
+	title, body := BuildNotificationForProfile("Dinner", []models.DailyItem{pizza, salad}, profile)
+	if title != "Dinner favorites" || body != "Salad is at Allison for dinner." {
+		t.Errorf("got %q / %q", title, body)
+	}
+
+	profile.Avoid = append(profile.Avoid, "Sesame")
+	if _, body := BuildNotificationForProfile("Dinner", []models.DailyItem{pizza, salad}, profile); body != "" {
+		t.Errorf("expected no notification when every item is unsafe, got %q", body)
+	}
+}
@@ -0,0 +1,160 @@
+// Package ranking orders a day's menu items by a nutrient-density metric and
+// scores each dining hall by its best options.
+package ranking
+
+import (
+	"backend/internal/models"
+	"backend/internal/nutrition"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Metric names accepted by Rank.
+const (
+	ProteinPerCal = "protein_per_cal"
+	Fiber         = "fiber"
+	LowSodium     = "low_sodium"
+)
+
+// Metrics lists every metric, in the order documentation shows them.
+var Metrics = []string{ProteinPerCal, Fiber, LowSodium}
+
+// HallTop is how many items each hall's score and list are built from.
+const HallTop = 5
+
+// MinCalories keeps near-zero-calorie items (condiments, garnishes, drinks)
+// out of every ranking: a tiny denominator would put them on top of the
+// per-calorie ranking, and they would top the sodium and fiber rankings
+// without being a meal option.
+const MinCalories = 50
+
+// RankedItem is one item with its metric value.
+type RankedItem struct {
+	Item models.DailyItem `json:"item"`
+	// Value is grams of protein per 100 kcal, grams of fiber, or milligrams
+	// of sodium, depending on the metric.
+	Value float64 `json:"value"`
+}
+
+// HallRanking is one hall's best items and its score: the mean value of
+// those items. Halls with fewer than HallTop ranked items are listed after
+// the halls that fill the window, so one strong item cannot outrank five good
+// ones.
+type HallRanking struct {
+	Location string       `json:"location"`
+	Score    float64      `json:"score"`
+	Items    []RankedItem `json:"items"`
+}
+
+// Result is a ranking of one set of items.
+type Result struct {
+	Items []RankedItem  `json:"items"`
+	Halls []HallRanking `json:"halls"`
+	// Skipped counts items left out because they lack the nutrition data the
+	// metric needs or fall under MinCalories.
+	Skipped int `json:"skipped"`
+}
+
+// Valid reports whether metric is one Rank understands.
+func Valid(metric string) bool {
+	return slices.Contains(Metrics, metric)
+}
+
+// Rank orders items by metric, best first, keeping the top limit overall and
+// the top HallTop per hall. An item served at one hall under several meals or
+// stations is counted once.
+func Rank(items []models.DailyItem, metric string, limit int) Result {
+	result := Result{Items: []RankedItem{}, Halls: []HallRanking{}}
+
+	seen := make(map[string]bool)
+	var ranked []RankedItem
+	for _, item := range items {
+		key := strings.ToLower(strings.TrimSpace(item.Location)) + "\x00" + strings.ToLower(strings.TrimSpace(item.Name))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		value, ok := Value(item, metric)
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		ranked = append(ranked, RankedItem{Item: item, Value: value})
+	}
+
+	less := func(a, b RankedItem) bool {
+		if a.Value != b.Value {
+			if metric == LowSodium {
+				return a.Value < b.Value
+			}
+			return a.Value > b.Value
+		}
+		return a.Item.Name < b.Item.Name
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return less(ranked[i], ranked[j]) })
+
+	byHall := make(map[string][]RankedItem)
+	var halls []string
+	for _, entry := range ranked {
+		location := entry.Item.Location
+		if _, ok := byHall[location]; !ok {
+			halls = append(halls, location)
+		}
+		if len(byHall[location]) < HallTop {
+			byHall[location] = append(byHall[location], entry)
+		}
+	}
+	for _, location := range halls {
+		best := byHall[location]
+		total := 0.0
+		for _, entry := range best {
+			total += entry.Value
+		}
+		result.Halls = append(result.Halls, HallRanking{Location: location, Score: round(total / float64(len(best))), Items: best})
+	}
+	sort.SliceStable(result.Halls, func(i, j int) bool {
+		a, b := result.Halls[i], result.Halls[j]
+		if aFull, bFull := len(a.Items) == HallTop, len(b.Items) == HallTop; aFull != bFull {
+			return aFull
+		}
+		if metric == LowSodium {
+			return a.Score < b.Score
+		}
+		return a.Score > b.Score
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	result.Items = append(result.Items, ranked...)
+	return result
+}
+
+// Value returns item's metric value, or false when the item lacks the data or
+// has fewer than MinCalories calories.
+func Value(item models.DailyItem, metric string) (float64, bool) {
+	calories, ok := nutrition.Value(item, nutrition.Calories)
+	if !ok || calories < MinCalories {
+		return 0, false
+	}
+	switch metric {
+	case ProteinPerCal:
+		protein, ok := nutrition.Value(item, nutrition.Protein)
+		if !ok {
+			return 0, false
+		}
+		return round(protein / calories * 100), true
+	case Fiber:
+		return nutrition.Value(item, nutrition.Fiber)
+	case LowSodium:
+		return nutrition.Value(item, nutrition.Sodium)
+	}
+	return 0, false
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
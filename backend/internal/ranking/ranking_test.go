@@ -0,0 +1,96 @@
+package ranking
+
+import (
+	"backend/internal/models"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func item(name, location, calories, protein string, sodium float64) models.DailyItem {
+	result := models.DailyItem{Name: name, Location: location, Calories: calories, Protein: protein}
+	if sodium >= 0 {
+		result.Nutrition.Sodium = &models.NutrientAmount{Value: sodium, Unit: "mg"}
+	}
+	return result
+}
+
+func names(items []RankedItem) []string {
+	var result []string
+	for _, entry := range items {
+		result = append(result, entry.Item.Name)
+	}
+	return result
+}
+
+func TestRankProteinPerCalorie(t *testing.T) {
+	items := []models.DailyItem{
+		item("Grilled Chicken", "Allison", "200", "40", 300),
+		item("Pasta", "Allison", "400", "12", 500),
+		item("Tofu", "Sargent", "150", "18", 200),
+		item("Hot Sauce", "Sargent", "5", "1", 400),
+		item("Mystery Stew", "Sargent", "", "", -1),
+		item("Grilled Chicken", "Allison", "200", "40", 300),
+	}
+
+	result := Rank(items, ProteinPerCal, 10)
+	assert.Equal(t, []string{"Grilled Chicken", "Tofu", "Pasta"}, names(result.Items))
+	assert.Equal(t, 20.0, result.Items[0].Value)
+	assert.Equal(t, 2, result.Skipped, "missing data and near-zero calories are skipped")
+
+	require.Len(t, result.Halls, 2)
+	assert.Equal(t, "Sargent", result.Halls[0].Location, "halls are ordered by the mean of their best items")
+	assert.Equal(t, 12.0, result.Halls[0].Score)
+	assert.Equal(t, "Allison", result.Halls[1].Location)
+	assert.Equal(t, 11.5, result.Halls[1].Score)
+}
+
+func TestRankLowSodiumOrdersAscendingAndKeepsHallTop(t *testing.T) {
+	var items []models.DailyItem
+	for i := 0; i < 8; i++ {
+		items = append(items, item(fmt.Sprintf("Dish %d", i), "Elder", "100", "1", float64(800-i*100)))
+	}
+	items = append(items, item("Salad", "Plex", "100", "1", 50), item("Bread", "Plex", "100", "1", -1))
+
+	result := Rank(items, LowSodium, 3)
+	assert.Equal(t, []string{"Salad", "Dish 7", "Dish 6"}, names(result.Items))
+	assert.Equal(t, 1, result.Skipped)
+
+	require.Len(t, result.Halls, 2)
+	assert.Equal(t, "Elder", result.Halls[0].Location, "a hall filling the window ranks ahead of one with a single item")
+	assert.Len(t, result.Halls[0].Items, HallTop)
+	assert.Equal(t, 300.0, result.Halls[0].Score)
+	assert.Equal(t, "Plex", result.Halls[1].Location)
+}
+
+func TestRankAppliesTheCalorieFloorToEveryMetric(t *testing.T) {
+	items := []models.DailyItem{
+		item("Soy Sauce Packet", "Allison", "10", "1", 900),
+		item("Diet Soda", "Allison", "0", "0", 0),
+		item("Chili", "Allison", "300", "20", 600),
+	}
+
+	result := Rank(items, LowSodium, 10)
+	assert.Equal(t, []string{"Chili"}, names(result.Items))
+	assert.Equal(t, 2, result.Skipped)
+
+	broth := models.DailyItem{Calories: "20", Nutrition: models.Nutrition{Fiber: &models.NutrientAmount{Value: 3, Unit: "g"}}}
+	_, ok := Value(broth, Fiber)
+	assert.False(t, ok)
+}
+
+func TestValueAndValid(t *testing.T) {
+	withFiber := models.DailyItem{Calories: "200", Nutrition: models.Nutrition{Fiber: &models.NutrientAmount{Value: 6, Unit: "g"}}}
+	value, ok := Value(withFiber, Fiber)
+	assert.True(t, ok)
+	assert.Equal(t, 6.0, value)
+
+	_, ok = Value(models.DailyItem{}, Fiber)
+	assert.False(t, ok)
+
+	assert.True(t, Valid(LowSodium))
+	assert.False(t, Valid("sugar"))
+	assert.Empty(t, Rank(nil, Fiber, 5).Items)
+}
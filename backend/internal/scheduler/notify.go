@@ -2,7 +2,9 @@ package scheduler
 
 import (
 	"backend/internal/db"
+	"backend/internal/models"
 	"backend/internal/push"
+	"backend/internal/subscription"
 	"context"
 	"log"
 	"os"
@@ -72,8 +74,12 @@ func runNotifyOnce(loc *time.Location) {
 }
 
 // notifyForTime refreshes and then announces the meal that the given fire time
-// maps to. The fire time is a parameter rather than read from the clock so the
-// pass can be driven from a fixed moment.
+// maps to. Each user hears about their favorites and the items their
+// subscription rules match, both via db.GetAvailableFavoritesForMeal; the
+// meal's menu is loaded once for the whole pass and every user's rules are
+// matched against it. The fire time is a parameter
+// rather than read from the clock so the pass can be driven from a fixed
+// moment.
 func notifyForTime(now time.Time) {
 	meal := mealForFireTime(now)
 	if meal == "" {
@@ -92,6 +98,12 @@ func notifyForTime(now time.Time) {
 		return
 	}
 
+	menu, err := subscription.LoadMenu(models.MenuFilter{From: date, To: date, Meal: meal})
+	if err != nil {
+		log.Printf("meal notifications failed to load the %s menu: %v", meal, err)
+		return
+	}
+
 	ctx := context.Background()
 	var notified, skipped int
 	var invalidTokens []string
@@ -100,13 +112,21 @@ func notifyForTime(now time.Time) {
 			continue
 		}
 
-		favorites, err := db.GetAvailableFavoritesForMeal(userID, date, meal)
+		favorites, err := db.GetAvailableFavoritesForMeal(userID, date, meal, menu)
 		if err != nil {
 			log.Printf("meal notifications: error getting favorites for user %s: %v", userID, err)
 			continue
 		}
 
-		title, body := push.BuildNotification(meal, favorites)
+		// The profile is loaded once per user and applied once, by the
+		// builder, so no announced item conflicts with it.
+		profile, err := db.GetDietaryProfile(userID)
+		if err != nil {
+			log.Printf("meal notifications: error getting dietary profile for user %s: %v", userID, err)
+			continue
+		}
+
+		title, body := push.BuildNotificationForProfile(meal, favorites, profile)
 		if body == "" {
 			skipped++
 			continue
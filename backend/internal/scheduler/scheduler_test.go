@@ -508,3 +508,36 @@ func TestMealForFireTime(t *testing.T) {
 	}
 }
 
+func TestNextWeeklyRun(t *testing.T) {
+	loc := mustChicago(t)
+	hours := []int{18}
+
+	cases := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "midweek -> coming Sunday",
+			now:  time.Date(2026, 7, 8, 9, 0, 0, 0, loc), // Wednesday
+			want: time.Date(2026, 7, 12, 18, 0, 0, 0, loc),
+		},
+		{
+			name: "Sunday before the slot -> today",
+			now:  time.Date(2026, 7, 12, 8, 0, 0, 0, loc),
+			want: time.Date(2026, 7, 12, 18, 0, 0, 0, loc),
+		},
+		{
+			name: "Sunday on the slot -> next week",
+			now:  time.Date(2026, 7, 12, 18, 0, 0, 0, loc),
+			want: time.Date(2026, 7, 19, 18, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tc := range cases {
+		got := nextWeeklyRun(tc.now, time.Sunday, hours, loc)
+		if !got.Equal(tc.want) {
+			t.Errorf("%s: nextWeeklyRun(%s) = %s, want %s", tc.name, tc.now, got, tc.want)
+		}
+	}
+}
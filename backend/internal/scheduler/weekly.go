@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"backend/internal/mailer"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultWeeklyReportHours: Sunday at 6pm Central, after the week's last
+// dinner has usually been logged.
+var defaultWeeklyReportHours = []int{18}
+
+// weeklyReportDay is the weekday the weekly report goes out.
+const weeklyReportDay = time.Sunday
+
+// StartWeeklyReportMailing launches the background weekly food log report
+// email loop unless disabled via ENABLE_WEEKLY_REPORT_CRON=false. The report
+// goes out on Sundays at WEEKLY_REPORT_HOURS_CST (comma-separated hours 0-23
+// in America/Chicago, default "18") and covers the seven days ending that
+// Sunday. Only users who opted in receive it. Failures are logged, never
+// fatal.
+func StartWeeklyReportMailing() {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("ENABLE_WEEKLY_REPORT_CRON")), "false") {
+		log.Println("weekly report mailing disabled via ENABLE_WEEKLY_REPORT_CRON=false")
+		return
+	}
+
+	loc, err := time.LoadLocation(campusZone)
+	if err != nil {
+		log.Printf("failed to load timezone %q (%v); weekly report cron disabled", campusZone, err)
+		return
+	}
+
+	hours := parseHoursEnv("WEEKLY_REPORT_HOURS_CST", defaultWeeklyReportHours)
+	go func() {
+		for {
+			next := nextWeeklyRun(time.Now(), weeklyReportDay, hours, loc)
+			wait := time.Until(next)
+			log.Printf("next weekly report mailing at %s (in %s)", next.Format("2006-01-02 15:04 MST"), wait.Truncate(time.Second))
+			time.Sleep(wait)
+			runWeeklyReportOnce(next.In(loc).Format(time.DateOnly))
+		}
+	}()
+}
+
+// runWeeklyReportOnce sends the reports for the week ending on end, isolating
+// panics so the scheduler loop (and the server) survive any failure.
+func runWeeklyReportOnce(end string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("weekly report mailing panicked: %v", r)
+		}
+	}()
+
+	log.Printf("weekly report mailing starting for the week ending %s", end)
+	if err := mailer.SendWeeklyReports(end); err != nil {
+		log.Printf("weekly report mailing failed: %v", err)
+		return
+	}
+	log.Println("weekly report mailing complete")
+}
+
+// nextWeeklyRun returns the soonest time strictly after now that falls on
+// weekday at one of hours (interpreted in loc).
+func nextWeeklyRun(now time.Time, weekday time.Weekday, hours []int, loc *time.Location) time.Time {
+	nowLoc := now.In(loc)
+	var best time.Time
+	for dayOffset := 0; dayOffset <= 7; dayOffset++ {
+		day := nowLoc.AddDate(0, 0, dayOffset)
+		if day.Weekday() != weekday {
+			continue
+		}
+		for _, h := range hours {
+			cand := time.Date(day.Year(), day.Month(), day.Day(), h, 0, 0, 0, loc)
+			if cand.After(nowLoc) && (best.IsZero() || cand.Before(best)) {
+				best = cand
+			}
+		}
+		if !best.IsZero() {
+			return best
+		}
+	}
+	return best
+}
@@ -9,6 +9,7 @@ package scrapejob
 
 import (
 	"backend/internal/db"
+	"backend/internal/events"
 	"backend/internal/models"
 	"backend/internal/scraper"
 	"backend/internal/store"
@@ -222,6 +223,7 @@ func Run(opts Options) error {
 	}
 	// Keep the read store in sync with the freshly persisted hours (see above).
 	store.Set(hours)
+	events.PublishHours()
 
 	log.Printf("hours update complete locations=%d", len(hours))
 	return nil
@@ -435,4 +437,52 @@ func refreshMenuStore() {
 	} else {
 		store.Set(hours)
 	}
+
+	publishMenuChanges()
+}
+
+// PublishMenuChanges announces menu periods changed since the last
+// announcement. refreshMenuStore already does this; callers that update the
+// store some other way (the manual scrape handlers) call it themselves.
+func PublishMenuChanges() { publishMenuChanges() }
+
+// announced remembers the newest menu change version already published to
+// event subscribers, so each refresh names only the periods changed since.
+var announced = struct {
+	mu      sync.Mutex
+	version uint
+}{}
+
+// publishMenuChanges tells event subscribers which menu periods changed since
+// the previous refresh. It always publishes, even when nothing changed, so an
+// explicit reload is visible to clients. If the change log cannot be read the
+// event asks clients to refetch everything.
+func publishMenuChanges() {
+	announced.mu.Lock()
+	defer announced.mu.Unlock()
+
+	changes, err := db.GetMenuChangeLog(announced.version)
+	if err != nil {
+		log.Printf("warning: read menu change log for events failed: %v", err)
+		events.Publish(events.Event{Type: events.TypeMenu, Version: announced.version, Reset: true})
+		return
+	}
+
+	periods := make([]events.Period, 0, len(changes.Periods))
+	for _, change := range changes.Periods {
+		periods = append(periods, events.Period{
+			Date:      change.Date,
+			Location:  change.Location,
+			TimeOfDay: change.TimeOfDay,
+			Cleared:   change.Cleared,
+		})
+	}
+	events.Publish(events.Event{
+		Type:    events.TypeMenu,
+		Version: changes.Version,
+		// An empty log has nothing to replay, so there is nothing to reset to.
+		Reset:   changes.Reset && changes.Version != 0,
+		Periods: periods,
+	})
+	announced.version = changes.Version
 }
@@ -2,6 +2,7 @@ package scraper
 
 import (
 	"backend/internal/models"
+	"backend/internal/nutrition"
 	"fmt"
 	"log"
 	"strings"
@@ -79,6 +80,7 @@ func parseItems(jsonResponse models.DiningHallResponse, location, timeOfDay stri
 
 			dailyItem := models.DailyItem{
 				Name:        strings.TrimSpace(item.Name),
+				UpstreamID:  strings.TrimSpace(item.ID),
 				Description: item.Description,
 				Date:        date,
 				Location:    location,
@@ -87,6 +89,8 @@ func parseItems(jsonResponse models.DiningHallResponse, location, timeOfDay stri
 				PortionSize: item.Portion,
 				Ingredients: strings.TrimSpace(item.Ingredients),
 				Filters:     flattenFilterNames(item.Filters),
+				Nutrition:   nutrition.FromLabel(item.Nutrients),
+				Serving:     nutrition.ParsePortion(item.Portion),
 			}
 
 			for _, nutrient := range item.Nutrients {
@@ -114,9 +118,9 @@ func parseItems(jsonResponse models.DiningHallResponse, location, timeOfDay stri
 // trimmed, empties dropped, and duplicates removed while preserving first-seen
 // order. Every remaining name is kept verbatim -- allergens ("Milk"), "may
 // contain" variants ("Sesame*"), diets ("Vegan", "Avoiding Gluten") and
-// marketing callouts ("Good Source of Protein") alike -- because clients own
-// the categorization. The result is always non-nil so the API emits [] rather
-// than null for items without tags.
+// marketing callouts ("Good Source of Protein") alike; the taxonomy package
+// categorizes them into an item's Tags when the menu is loaded. The result is
+// always non-nil so the API emits [] rather than null for items without tags.
 func flattenFilterNames(filters []models.Filter) []string {
 	names := make([]string, 0, len(filters))
 	seen := make(map[string]struct{}, len(filters))
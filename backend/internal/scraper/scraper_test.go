@@ -30,6 +30,10 @@ func TestParseItems(t *testing.T) {
 						Nutrients: []models.Nutrient{
 							{Name: "Calories", Value: "250"},
 							{Name: "Protein (g)", Value: "8"},
+							{Name: "Sodium", Value: "410", UOM: "mg"},
+							{Name: "Trans Fat (g)", Value: "<1"},
+							{Name: "Dietary Fiber (g)", Value: "-"},
+							{Name: "Iron (mg)", Value: "1.8"},
 						},
 					},
 					{Name: "Butter"},
@@ -50,6 +54,13 @@ func TestParseItems(t *testing.T) {
 	assert.Equal(t, "2026-07-10", dailyItems[0].Date)
 	assert.Equal(t, "250", dailyItems[0].Calories)
 	assert.Equal(t, "8", dailyItems[0].Protein)
+	nutrition := dailyItems[0].Nutrition
+	assert.Equal(t, &models.NutrientAmount{Value: 250, Unit: "kcal"}, nutrition.Calories)
+	assert.Equal(t, &models.NutrientAmount{Value: 410, Unit: "mg"}, nutrition.Sodium)
+	assert.Equal(t, &models.NutrientAmount{Value: 1, Unit: "g", LessThan: true}, nutrition.TransFat)
+	assert.Nil(t, nutrition.Fiber, "a dash means not listed")
+	assert.Equal(t, map[string]models.NutrientAmount{"Iron": {Value: 1.8, Unit: "mg"}}, nutrition.Other)
+	assert.Equal(t, models.Portion{Quantity: 2, Unit: "each", Parsed: true}, dailyItems[0].Serving)
 	assert.Equal(t, "Flour, Eggs, Milk", dailyItems[0].Ingredients)
 	// Names are kept verbatim (including "may contain" and marketing tags),
 	// trimmed, deduped, and in first-seen order.
@@ -0,0 +1,217 @@
+// Package search implements the menu search query language and ranks stored
+// menu items against it.
+//
+// A query is a whitespace separated list of clauses, for example
+//
+//	ramen location:allison meal:dinner tag:vegan ingredient:tofu protein>25 date:tomorrow
+//
+// Clauses come in three forms:
+//   - bare words (and "quoted phrases") are free-text terms; every term must
+//     match the item's name, station, description or ingredients;
+//   - key:value clauses filter on location (also loc/hall), meal, station,
+//     tag, ingredient (also ing) and date; values may be quoted ("plex
+//     east"). An ingredient clause matches the parsed ingredient statement at
+//     any depth, so ingredient:garlic finds garlic inside a sauce;
+//   - field<op>number clauses bound a nutrient, where field is cal/calories,
+//     protein, carbs, fat, satfat, sodium, sugar or fiber and op is one of
+//     < <= > >= =.
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed search. String fields are lowercased; an empty field does
+// not constrain the results.
+type Query struct {
+	Terms    []string
+	Location string
+	Meal     string
+	Station  string
+	Tags     []string
+	// Ingredients must each be found in the item's ingredient tree.
+	Ingredients []string
+	// Date is the resolved YYYY-MM-DD a date: clause named, or "" for any date.
+	Date    string
+	Numeric []NumericFilter
+}
+
+// NumericFilter bounds one nutrient, e.g. protein > 25.
+type NumericFilter struct {
+	Field string // a canonical field from package nutrition, e.g. "protein"
+	Op    string // <, <=, >, >= or =
+	Value float64
+}
+
+// IsEmpty reports whether the query has no clauses at all.
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0 && q.Location == "" && q.Meal == "" && q.Station == "" &&
+		len(q.Tags) == 0 && len(q.Ingredients) == 0 && q.Date == "" && len(q.Numeric) == 0
+}
+
+// numericFields maps every accepted nutrient spelling to its canonical name.
+var numericFields = map[string]string{
+	"cal":      "calories",
+	"cals":     "calories",
+	"calories": "calories",
+	"kcal":     "calories",
+	"protein":  "protein",
+	"carb":     "carbs",
+	"carbs":    "carbs",
+	"fat":      "fat",
+	"satfat":   "saturatedFat",
+	"sodium":   "sodium",
+	"salt":     "sodium",
+	"sugar":    "sugars",
+	"sugars":   "sugars",
+	"fiber":    "fiber",
+	"fibre":    "fiber",
+}
+
+// comparisonOps is ordered so two-character operators are tried first.
+var comparisonOps = []string{"<=", ">=", "<", ">", "="}
+
+// Parse turns a query string into a Query. now anchors relative dates such as
+// date:tomorrow and should be on the campus clock.
+func Parse(input string, now time.Time) (Query, error) {
+	var q Query
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		return q, err
+	}
+
+	for _, token := range tokens {
+		if token.quoted {
+			if term := strings.ToLower(strings.TrimSpace(token.text)); term != "" {
+				q.Terms = append(q.Terms, term)
+			}
+			continue
+		}
+
+		if filter, ok, err := parseNumeric(token.text); err != nil {
+			return q, err
+		} else if ok {
+			q.Numeric = append(q.Numeric, filter)
+			continue
+		}
+
+		key, value, isClause := strings.Cut(token.text, ":")
+		if !isClause {
+			q.Terms = append(q.Terms, strings.ToLower(token.text))
+			continue
+		}
+
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			return q, fmt.Errorf("%s: needs a value", key)
+		}
+
+		switch strings.ToLower(key) {
+		case "location", "loc", "hall":
+			q.Location = value
+		case "meal":
+			q.Meal = value
+		case "station":
+			q.Station = value
+		case "tag":
+			q.Tags = append(q.Tags, value)
+		case "ingredient", "ing":
+			q.Ingredients = append(q.Ingredients, value)
+		case "date":
+			date, err := resolveDate(value, now)
+			if err != nil {
+				return q, err
+			}
+			q.Date = date
+		default:
+			return q, fmt.Errorf("unknown search key %q", key)
+		}
+	}
+
+	return q, nil
+}
+
+// parseNumeric recognizes field<op>number clauses. It reports false, with no
+// error, for tokens that are not nutrient comparisons at all.
+func parseNumeric(text string) (NumericFilter, bool, error) {
+	for _, op := range comparisonOps {
+		field, raw, found := strings.Cut(text, op)
+		if !found {
+			continue
+		}
+		canonical, known := numericFields[strings.ToLower(field)]
+		if !known {
+			return NumericFilter{}, false, nil
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return NumericFilter{}, false, fmt.Errorf("%s%s needs a number, got %q", field, op, raw)
+		}
+		return NumericFilter{Field: canonical, Op: op, Value: value}, true, nil
+	}
+	return NumericFilter{}, false, nil
+}
+
+// resolveDate accepts today, tomorrow, yesterday or an explicit YYYY-MM-DD.
+func resolveDate(value string, now time.Time) (string, error) {
+	switch value {
+	case "today":
+		return now.Format("2006-01-02"), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format("2006-01-02"), nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", fmt.Errorf("invalid date %q: use today, tomorrow, yesterday or YYYY-MM-DD", value)
+	}
+	return value, nil
+}
+
+// token is one whitespace separated query clause. quoted marks a bare
+// "quoted phrase", which is always a free-text term.
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits on whitespace while keeping double-quoted runs together, both
+// as whole tokens ("mac and cheese") and as clause values (location:"plex east").
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	var current strings.Builder
+	inQuotes := false
+	startedQuoted := false
+
+	flush := func() {
+		if current.Len() > 0 || startedQuoted {
+			tokens = append(tokens, token{text: current.String(), quoted: startedQuoted})
+		}
+		current.Reset()
+		startedQuoted = false
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			if !inQuotes && current.Len() == 0 {
+				startedQuoted = true
+			}
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in search query")
+	}
+	flush()
+
+	return tokens, nil
+}
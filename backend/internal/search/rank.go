@@ -0,0 +1,207 @@
+package search
+
+import (
+	"backend/internal/models"
+	"backend/internal/nutrition"
+	"sort"
+	"strings"
+)
+
+// Result is one matched menu item and its relevance score. The item's fields
+// are flattened into the JSON object next to the score.
+type Result struct {
+	models.DailyItem
+	Score float64 `json:"score"`
+}
+
+// Field weights for free-text matches. A term found in several fields scores
+// only its best field, so a dish whose name matches always outranks one that
+// merely lists the term as an ingredient.
+const (
+	weightNameWord   = 10.0 // a whole word of the name
+	weightNamePrefix = 7.0  // the start of a word of the name
+	weightName       = 5.0  // anywhere in the name
+	weightStation    = 3.0
+	weightDesc       = 2.0
+	weightIngredient = 1.0
+
+	// bonusExactName rewards a query whose terms spell the whole name.
+	bonusExactName = 15.0
+)
+
+// Run filters items by every clause of q and ranks the survivors by text
+// relevance, best first. Ties (including every item when q has no terms) keep
+// menu order: date, location, time of day, station, name.
+func Run(items []models.DailyItem, q Query) []Result {
+	results := make([]Result, 0)
+	for _, item := range items {
+		if !matchesFilters(item, q) {
+			continue
+		}
+		score, ok := textScore(item, q.Terms)
+		if !ok {
+			continue
+		}
+		results = append(results, Result{DailyItem: item, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		a, b := results[i].DailyItem, results[j].DailyItem
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		if a.TimeOfDay != b.TimeOfDay {
+			return a.TimeOfDay < b.TimeOfDay
+		}
+		if a.StationName != b.StationName {
+			return a.StationName < b.StationName
+		}
+		return a.Name < b.Name
+	})
+
+	return results
+}
+
+// matchesFilters applies every non-text clause. Location and station match by
+// containment so location:plex finds both Plex halls; meal and tags must match
+// exactly (ignoring case), so tag:sesame does not match the "may contain"
+// variant "Sesame*" unless the query asks for it. Ingredients match with
+// models.IngredientMatches anywhere in the ingredient tree.
+func matchesFilters(item models.DailyItem, q Query) bool {
+	if q.Date != "" && item.Date != q.Date {
+		return false
+	}
+	if q.Location != "" && !strings.Contains(strings.ToLower(item.Location), q.Location) {
+		return false
+	}
+	if q.Meal != "" && !strings.EqualFold(item.TimeOfDay, q.Meal) {
+		return false
+	}
+	if q.Station != "" && !strings.Contains(strings.ToLower(item.StationName), q.Station) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !hasTag(item.Filters, tag) {
+			return false
+		}
+	}
+	if len(q.Ingredients) > 0 {
+		tree := item.IngredientList()
+		for _, ingredient := range q.Ingredients {
+			if _, found := models.FindIngredient(tree, ingredient); !found {
+				return false
+			}
+		}
+	}
+	for _, filter := range q.Numeric {
+		value, ok := nutrition.Value(item, filter.Field)
+		if !ok || !compare(value, filter.Op, filter.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(filters []string, tag string) bool {
+	for _, filter := range filters {
+		if strings.EqualFold(strings.TrimSpace(filter), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare(value float64, op string, bound float64) bool {
+	switch op {
+	case "<":
+		return value < bound
+	case "<=":
+		return value <= bound
+	case ">":
+		return value > bound
+	case ">=":
+		return value >= bound
+	case "=":
+		return value == bound
+	}
+	return false
+}
+
+// textScore sums each term's best field weight. It reports false when any term
+// matches nowhere, since every term is required.
+func textScore(item models.DailyItem, terms []string) (float64, bool) {
+	if len(terms) == 0 {
+		return 0, true
+	}
+
+	name := strings.ToLower(item.Name)
+	nameWords := strings.FieldsFunc(name, isWordSeparator)
+	station := strings.ToLower(item.StationName)
+	description := strings.ToLower(item.Description)
+	ingredients := strings.ToLower(item.Ingredients)
+
+	var score float64
+	for _, term := range terms {
+		best := 0.0
+		switch {
+		case containsWord(nameWords, term) || (strings.Contains(term, " ") && strings.Contains(name, term)):
+			best = weightNameWord
+		case hasWordPrefix(nameWords, term):
+			best = weightNamePrefix
+		case strings.Contains(name, term):
+			best = weightName
+		case strings.Contains(station, term):
+			best = weightStation
+		case strings.Contains(description, term):
+			best = weightDesc
+		case strings.Contains(ingredients, term):
+			best = weightIngredient
+		}
+		if best == 0 {
+			return 0, false
+		}
+		score += best
+	}
+
+	if strings.Join(terms, " ") == name {
+		score += bonusExactName
+	}
+	// Prefer tighter names: "Ramen" over "Spicy Miso Ramen Bowl" for "ramen".
+	if len(nameWords) > 0 {
+		score += 1 / float64(len(nameWords))
+	}
+
+	return score, true
+}
+
+func isWordSeparator(r rune) bool {
+	switch r {
+	case ' ', ',', '-', '(', ')', '/', '&', '\'':
+		return true
+	}
+	return false
+}
+
+func containsWord(words []string, term string) bool {
+	for _, word := range words {
+		if word == term {
+			return true
+		}
+	}
+	return false
+}
+
+func hasWordPrefix(words []string, term string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"backend/internal/models"
+	"errors"
+	"time"
+)
+
+// ParseRule parses the query of a subscription rule. A rule applies to every
+// day's menu, so it may not carry a date clause, and it needs at least one
+// clause so it cannot match the whole menu.
+func ParseRule(input string) (Query, error) {
+	q, err := Parse(input, time.Time{})
+	if err != nil {
+		return Query{}, err
+	}
+	if q.Date != "" {
+		return Query{}, errors.New("a rule applies to every day and cannot have a date clause")
+	}
+	if q.IsEmpty() {
+		return Query{}, errors.New("a rule needs at least one clause")
+	}
+	return q, nil
+}
+
+// Matches reports whether item satisfies every clause of q, the same test Run
+// applies before ranking.
+func Matches(item models.DailyItem, q Query) bool {
+	if !matchesFilters(item, q) {
+		return false
+	}
+	_, ok := textScore(item, q.Terms)
+	return ok
+}
+
+// MatchRules returns the items, in their original order, that match at least
+// one of rules.
+func MatchRules(items []models.DailyItem, rules []Query) []models.DailyItem {
+	matched := make([]models.DailyItem, 0)
+	for _, item := range items {
+		for _, rule := range rules {
+			if Matches(item, rule) {
+				matched = append(matched, item)
+				break
+			}
+		}
+	}
+	return matched
+}
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, time.July, 10, 9, 0, 0, 0, time.UTC)
+
+	q, err := Parse(`ramen location:allison meal:dinner tag:vegan protein>25 cal<600 date:tomorrow`, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ramen"}, q.Terms)
+	assert.Equal(t, "allison", q.Location)
+	assert.Equal(t, "dinner", q.Meal)
+	assert.Equal(t, []string{"vegan"}, q.Tags)
+	assert.Equal(t, "2026-07-11", q.Date)
+	assert.Equal(t, []NumericFilter{
+		{Field: "protein", Op: ">", Value: 25},
+		{Field: "calories", Op: "<", Value: 600},
+	}, q.Numeric)
+
+	q, err = Parse(`"mac and cheese" location:"plex east" fat<=10.5`, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mac and cheese"}, q.Terms)
+	assert.Equal(t, "plex east", q.Location)
+	assert.Equal(t, []NumericFilter{{Field: "fat", Op: "<=", Value: 10.5}}, q.Numeric)
+}
+
+func TestParseRejectsBadClauses(t *testing.T) {
+	now := time.Date(2026, time.July, 10, 9, 0, 0, 0, time.UTC)
+
+	for _, input := range []string{
+		`color:red`,
+		`meal:`,
+		`protein>lots`,
+		`date:someday`,
+		`"unterminated`,
+	} {
+		_, err := Parse(input, now)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestRunFiltersAndRanks(t *testing.T) {
+	items := []models.DailyItem{
+		{Name: "Spicy Miso Ramen Bowl", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Dinner", Protein: "30", Calories: "550", Filters: []string{"Vegan"}},
+		{Name: "Ramen", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Dinner", Protein: "28", Calories: "500", Filters: []string{"Vegan"}},
+		{Name: "Veggie Stir Fry", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Dinner", Protein: "26", Calories: "400", Ingredients: "Ramen Noodles, Broccoli", Filters: []string{"Vegan"}},
+		{Name: "Ramen", Date: "2026-07-10", Location: "Sargent", TimeOfDay: "Dinner", Protein: "28", Calories: "500", Filters: []string{"Vegan"}},
+		{Name: "Tonkotsu Ramen", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Dinner", Protein: "35", Calories: "700", Filters: []string{}},
+		{Name: "Plain Ramen", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Dinner", Protein: "", Filters: []string{"Vegan"}},
+	}
+
+	q, err := Parse("ramen location:allison tag:vegan protein>25 cal<600", time.Now())
+	require.NoError(t, err)
+
+	results := Run(items, q)
+	require.Len(t, results, 3)
+	assert.Equal(t, "Ramen", results[0].Name, "an exact name match ranks first")
+	assert.Equal(t, "Spicy Miso Ramen Bowl", results[1].Name)
+	assert.Equal(t, "Veggie Stir Fry", results[2].Name, "an ingredient-only match ranks last")
+	assert.Greater(t, results[0].Score, results[1].Score)
+	assert.Greater(t, results[1].Score, results[2].Score)
+}
+
+func TestRunTagMatchingRespectsMayContain(t *testing.T) {
+	items := []models.DailyItem{
+		{Name: "Bagel", Filters: []string{"Sesame*"}},
+		{Name: "Tahini", Filters: []string{"Sesame"}},
+	}
+
+	results := Run(items, Query{Tags: []string{"sesame"}})
+	require.Len(t, results, 1)
+	assert.Equal(t, "Tahini", results[0].Name)
+
+	results = Run(items, Query{Tags: []string{"sesame*"}})
+	require.Len(t, results, 1)
+	assert.Equal(t, "Bagel", results[0].Name)
+}
+
+func TestRunBoundsExtendedNutrients(t *testing.T) {
+	items := []models.DailyItem{
+		{Name: "Chicken Soup", Nutrition: models.Nutrition{Sodium: &models.NutrientAmount{Value: 900, Unit: "mg"}}},
+		{Name: "Tomato Soup", Nutrition: models.Nutrition{Sodium: &models.NutrientAmount{Value: 450, Unit: "mg"}}},
+		{Name: "Lentil Soup"},
+	}
+
+	q, err := Parse("soup sodium<600", time.Now())
+	require.NoError(t, err)
+
+	results := Run(items, q)
+	require.Len(t, results, 1, "items without a sodium value never match")
+	assert.Equal(t, "Tomato Soup", results[0].Name)
+}
+
+func TestRunMatchesIngredientsAtAnyDepth(t *testing.T) {
+	items := []models.DailyItem{
+		{Name: "Burrito Bowl", Ingredients: "Rice, Salsa (Tomatoes, Cilantro), Black Beans"},
+		{Name: "Fried Rice", Ingredients: "Rice, Eggs, Green Onions"},
+		{Name: "Cilantro Lime Rice", Ingredients: "Rice, Lime Juice"},
+	}
+
+	q, err := Parse(`ingredient:cilantro ing:rice`, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cilantro", "rice"}, q.Ingredients)
+
+	results := Run(items, q)
+	require.Len(t, results, 1, "the clause matches ingredients, not names")
+	assert.Equal(t, "Burrito Bowl", results[0].Name)
+
+	results = Run(items, Query{Ingredients: []string{"green onion"}})
+	require.Len(t, results, 1)
+	assert.Equal(t, "Fried Rice", results[0].Name)
+}
+
+func TestParseRuleRejectsDatesAndEmptyRules(t *testing.T) {
+	q, err := ParseRule("station:flame protein>30")
+	require.NoError(t, err)
+	assert.Equal(t, "flame", q.Station)
+
+	for _, input := range []string{``, `   `, `ramen date:tomorrow`, `color:red`} {
+		_, err := ParseRule(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestMatchRulesKeepsItemsMatchingAnyRule(t *testing.T) {
+	items := []models.DailyItem{
+		{Name: "Spicy Miso Ramen", Location: "Allison", StationName: "Noodle Bar"},
+		{Name: "Tofu Scramble", Location: "Sargent", StationName: "Comfort", Filters: []string{"Vegan"}},
+		{Name: "Tofu Scramble", Location: "Allison", StationName: "Comfort", Filters: []string{"Vegan"}},
+		{Name: "Flame Grilled Chicken", Location: "Elder", StationName: "Flame", Protein: "42"},
+		{Name: "Flame Veggie Burger", Location: "Elder", StationName: "Flame", Protein: "18"},
+	}
+
+	var rules []Query
+	for _, input := range []string{"ramen", "tag:vegan location:sargent", "station:flame protein>30"} {
+		q, err := ParseRule(input)
+		require.NoError(t, err)
+		rules = append(rules, q)
+	}
+
+	matched := MatchRules(items, rules)
+	require.Len(t, matched, 3)
+	assert.Equal(t, "Spicy Miso Ramen", matched[0].Name)
+	assert.Equal(t, "Sargent", matched[1].Location)
+	assert.Equal(t, "Flame Grilled Chicken", matched[2].Name)
+
+	assert.Empty(t, MatchRules(items, nil))
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"backend/internal/models"
+	"strings"
+)
+
+// maxCachedItemDetails bounds the item-detail cache. Details are cheap to
+// rebuild, so the cache is simply emptied when it fills rather than tracking
+// recency.
+const maxCachedItemDetails = 2000
+
+func itemDetailKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (s *MemoryStore) getItemDetail(name, since string) (models.ItemDetail, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	detail, ok := s.itemDetails[itemDetailKey(name)]
+	// A detail built for an older retention window would still list
+	// appearances that have since been pruned.
+	if !ok || detail.Since != since {
+		return models.ItemDetail{}, false
+	}
+	return cloneItemDetail(detail), true
+}
+
+func (s *MemoryStore) cacheItemDetail(detail models.ItemDetail) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.itemDetails == nil || len(s.itemDetails) >= maxCachedItemDetails {
+		s.itemDetails = make(map[string]models.ItemDetail)
+	}
+	s.itemDetails[itemDetailKey(detail.Name)] = cloneItemDetail(detail)
+}
+
+func cloneItemDetail(detail models.ItemDetail) models.ItemDetail {
+	detail.Filters = append([]string{}, detail.Filters...)
+	detail.Appearances = append([]models.ItemAppearance{}, detail.Appearances...)
+	return detail
+}
+
+// GetItemDetail returns the cached detail for the named item, provided it was
+// built for the same retention window start (since). Details are dropped
+// whenever the weekly menu is replaced.
+func GetItemDetail(name, since string) (models.ItemDetail, bool) {
+	if store == nil {
+		return models.ItemDetail{}, false
+	}
+	return store.getItemDetail(name, since)
+}
+
+// CacheItemDetail remembers a detail built from the database until the weekly
+// menu is next replaced.
+func CacheItemDetail(detail models.ItemDetail) {
+	if store != nil {
+		store.cacheItemDetail(detail)
+	}
+}
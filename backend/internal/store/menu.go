@@ -0,0 +1,230 @@
+package store
+
+import (
+	"backend/internal/models"
+	"sort"
+	"strings"
+)
+
+// menuSliceKey names one (date, location, time of day) slice of the menu.
+// Location and meal are lowercased so lookups are case-insensitive.
+type menuSliceKey struct {
+	date     string
+	location string
+	meal     string
+}
+
+// menuIndex lets scoped menu reads jump straight to the dates, halls and meals
+// they ask for instead of cloning and scanning the whole week. It is rebuilt
+// from scratch whenever the weekly items are replaced and never mutated after.
+type menuIndex struct {
+	dates  []string                            // every stored date, ascending
+	slices map[string][]menuSliceKey           // slice keys per date, in menu order
+	items  map[menuSliceKey][]models.DailyItem // items per slice, in menu order
+	// ordered is every item in menu order; ingredients maps each word of the
+	// parsed ingredient trees, sub-ingredients included, to the ascending
+	// positions in ordered of the items using it.
+	ordered     []models.DailyItem
+	ingredients map[string][]int
+}
+
+func newMenuIndex(weeklyItems map[string][]models.DailyItem) menuIndex {
+	index := menuIndex{
+		dates:  make([]string, 0, len(weeklyItems)),
+		slices: make(map[string][]menuSliceKey, len(weeklyItems)),
+		items:  make(map[menuSliceKey][]models.DailyItem),
+
+		ingredients: make(map[string][]int),
+	}
+
+	for date, dateItems := range weeklyItems {
+		if len(dateItems) == 0 {
+			continue
+		}
+		index.dates = append(index.dates, date)
+
+		sorted := append([]models.DailyItem(nil), dateItems...)
+		SortMenuItems(sorted)
+		for _, item := range sorted {
+			key := menuSliceKey{
+				date:     date,
+				location: strings.ToLower(item.Location),
+				meal:     strings.ToLower(item.TimeOfDay),
+			}
+			if _, exists := index.items[key]; !exists {
+				index.slices[date] = append(index.slices[date], key)
+			}
+			index.items[key] = append(index.items[key], item)
+		}
+	}
+	sort.Strings(index.dates)
+
+	for _, date := range index.dates {
+		for _, key := range index.slices[date] {
+			for _, item := range index.items[key] {
+				position := len(index.ordered)
+				index.ordered = append(index.ordered, item)
+				for word := range ingredientWordSet(item.IngredientList(), nil) {
+					index.ingredients[word] = append(index.ingredients[word], position)
+				}
+			}
+		}
+	}
+
+	return index
+}
+
+// ingredientWordSet adds the words of every ingredient in tree, at any depth,
+// to words.
+func ingredientWordSet(tree []models.Ingredient, words map[string]struct{}) map[string]struct{} {
+	if words == nil {
+		words = make(map[string]struct{})
+	}
+	for _, ingredient := range tree {
+		for _, word := range models.IngredientWords(ingredient.Name) {
+			words[word] = struct{}{}
+		}
+		ingredientWordSet(ingredient.Sub, words)
+	}
+	return words
+}
+
+// query returns copies of every indexed item matching the filter, in menu
+// order. Dates outside the filter are skipped by binary search and, when a
+// location and meal are both given, each date costs a single map lookup.
+func (index menuIndex) query(filter models.MenuFilter) []models.DailyItem {
+	if len(filter.Ingredients) > 0 {
+		return index.queryIngredients(filter)
+	}
+
+	start := 0
+	if filter.From != "" {
+		start = sort.SearchStrings(index.dates, filter.From)
+	}
+
+	location := strings.ToLower(strings.TrimSpace(filter.Location))
+	meal := strings.ToLower(strings.TrimSpace(filter.Meal))
+
+	result := make([]models.DailyItem, 0)
+	for _, date := range index.dates[start:] {
+		if filter.To != "" && date > filter.To {
+			break
+		}
+
+		keys := index.slices[date]
+		if location != "" && meal != "" {
+			keys = []menuSliceKey{{date: date, location: location, meal: meal}}
+		}
+
+		for _, key := range keys {
+			if location != "" && key.location != location {
+				continue
+			}
+			if meal != "" && key.meal != meal {
+				continue
+			}
+			for _, item := range index.items[key] {
+				if filter.Station != "" && !strings.EqualFold(item.StationName, filter.Station) {
+					continue
+				}
+				result = append(result, item)
+			}
+		}
+	}
+
+	return result
+}
+
+// queryIngredients answers a filter with ingredient clauses from the
+// ingredient postings: only items using a word of every clause, or its plural
+// or singular, are checked against the full filter.
+func (index menuIndex) queryIngredients(filter models.MenuFilter) []models.DailyItem {
+	var candidates []int
+	first := true
+	for _, ingredient := range filter.Ingredients {
+		for _, word := range models.IngredientWords(ingredient) {
+			postings := index.wordPostings(word)
+			if first {
+				candidates, first = postings, false
+			} else {
+				candidates = intersectPositions(candidates, postings)
+			}
+			if len(candidates) == 0 {
+				return []models.DailyItem{}
+			}
+		}
+	}
+
+	result := make([]models.DailyItem, 0, len(candidates))
+	for _, position := range candidates {
+		if item := index.ordered[position]; filter.Matches(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// wordPostings returns the ascending positions of items whose ingredients use
+// word or a form models.IngredientMatches treats as the same word: word plus
+// "s" or "es", or word without a trailing "s" or "es".
+func (index menuIndex) wordPostings(word string) []int {
+	forms := []string{word, word + "s", word + "es"}
+	if trimmed, ok := strings.CutSuffix(word, "s"); ok {
+		forms = append(forms, trimmed)
+	}
+	if trimmed, ok := strings.CutSuffix(word, "es"); ok {
+		forms = append(forms, trimmed)
+	}
+
+	seen := make(map[int]struct{})
+	var positions []int
+	for _, form := range forms {
+		for _, position := range index.ingredients[form] {
+			if _, exists := seen[position]; !exists {
+				seen[position] = struct{}{}
+				positions = append(positions, position)
+			}
+		}
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// intersectPositions returns the positions in both ascending lists.
+func intersectPositions(a, b []int) []int {
+	result := make([]int, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// SortMenuItems orders items the way the database returns them: by date,
+// location, time of day, station and name.
+func SortMenuItems(items []models.DailyItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		if a.TimeOfDay != b.TimeOfDay {
+			return a.TimeOfDay < b.TimeOfDay
+		}
+		if a.StationName != b.StationName {
+			return a.StationName < b.StationName
+		}
+		return a.Name < b.Name
+	})
+}
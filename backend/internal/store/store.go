@@ -1,8 +1,12 @@
 package store
 
 import (
+	"backend/internal/estimate"
 	"backend/internal/models"
+	"backend/internal/nutrition"
+	"backend/internal/taxonomy"
 	"sync"
+	"time"
 )
 
 var store *MemoryStore
@@ -12,6 +16,15 @@ type MemoryStore struct {
 	allData                []models.AllDataItem
 	weeklyItems            map[string][]models.DailyItem
 	locationOperatingTimes []models.LocationOperatingTimes
+	menuIndex              menuIndex
+	catalogIndex           catalogIndex
+	// itemDetails caches per-item history keyed by lowercased name. It is
+	// derived from the menu, so it is dropped whenever the menu is replaced.
+	itemDetails map[string]models.ItemDetail
+	// version increases on every Set and Clear; updatedAt records when. Both
+	// let handlers answer conditional requests without re-encoding payloads.
+	version   uint64
+	updatedAt time.Time
 }
 
 func InitStore() {
@@ -31,13 +44,18 @@ func (s *MemoryStore) Set(value any) {
 	switch v := value.(type) {
 	case []models.AllDataItem:
 		s.allData = append([]models.AllDataItem(nil), v...)
+		s.catalogIndex = newCatalogIndex(s.allData)
 	case []models.LocationOperatingTimes:
 		s.locationOperatingTimes = append([]models.LocationOperatingTimes(nil), v...)
 	case map[string][]models.DailyItem:
 		s.weeklyItems = cloneWeeklyItems(v)
+		DeriveWeekly(s.weeklyItems)
+		s.menuIndex = newMenuIndex(s.weeklyItems)
+		s.itemDetails = nil
 	default:
 		panic("Setting an unsupported type")
 	}
+	s.bumpVersion()
 }
 
 // Clear resets all in-memory data structures managed by the store
@@ -48,6 +66,68 @@ func (s *MemoryStore) Clear() {
 	s.allData = nil
 	s.locationOperatingTimes = nil
 	s.weeklyItems = make(map[string][]models.DailyItem)
+	s.menuIndex = menuIndex{}
+	s.catalogIndex = catalogIndex{}
+	s.itemDetails = nil
+	s.bumpVersion()
+}
+
+// DeriveWeekly fills in, in place, the fields the store derives rather than
+// reads from the database: estimates for missing calories and macros, learned
+// from the rest of the menu, filter tags, ingredient trees and servings. Menus
+// read from the database when the store is empty go through it too, so those
+// answers carry the same fields as the store's.
+func DeriveWeekly(weekly map[string][]models.DailyItem) {
+	estimate.FillWeekly(weekly)
+	taxonomy.TagWeekly(weekly)
+	parseIngredientTrees(weekly)
+	parseServings(weekly)
+}
+
+// Derive is DeriveWeekly for a list of items, such as the answer to a menu
+// query or an item's history. Estimates learn from items alone.
+func Derive(items []models.DailyItem) {
+	DeriveWeekly(map[string][]models.DailyItem{"": items})
+}
+
+// parseIngredientTrees parses every item's ingredient statement once, so
+// search and dietary checks walk the tree instead of reparsing per request.
+// Items sharing a statement share the parsed tree.
+func parseIngredientTrees(weekly map[string][]models.DailyItem) {
+	parsed := make(map[string][]models.Ingredient)
+	for _, items := range weekly {
+		for i := range items {
+			statement := items[i].Ingredients
+			tree, ok := parsed[statement]
+			if !ok {
+				tree = models.ParseIngredients(statement)
+				parsed[statement] = tree
+			}
+			items[i].IngredientTree = tree
+		}
+	}
+}
+
+// parseServings parses the portion of items stored before portions were
+// parsed at scrape time, so their servings are not reported as unparseable.
+func parseServings(weekly map[string][]models.DailyItem) {
+	for _, items := range weekly {
+		for i := range items {
+			items[i].Serving = nutrition.PortionOf(items[i])
+		}
+	}
+}
+
+// bumpVersion records a change to the stored data. Callers hold the write lock.
+func (s *MemoryStore) bumpVersion() {
+	s.version++
+	s.updatedAt = time.Now()
+}
+
+func (s *MemoryStore) getVersion() (uint64, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version, s.updatedAt
 }
 
 func (s *MemoryStore) getAllDataItems() []models.AllDataItem {
@@ -68,6 +148,28 @@ func (s *MemoryStore) getWeeklyItems() map[string][]models.DailyItem {
 	return cloneWeeklyItems(s.weeklyItems)
 }
 
+// queryMenu answers a scoped menu read from the index. The second result is
+// false when no menu is loaded, so the caller can fall back to the database.
+func (s *MemoryStore) queryMenu(filter models.MenuFilter) ([]models.DailyItem, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.menuIndex.dates) == 0 {
+		return nil, false
+	}
+	return s.menuIndex.query(filter), true
+}
+
+// suggestItems answers a typeahead query from the catalog index. The second
+// result is false when no catalog is loaded.
+func (s *MemoryStore) suggestItems(query string, limit int) ([]models.ItemSuggestion, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.allData == nil {
+		return nil, false
+	}
+	return s.catalogIndex.suggest(query, limit), true
+}
+
 func cloneWeeklyItems(items map[string][]models.DailyItem) map[string][]models.DailyItem {
 	if items == nil {
 		return nil
@@ -101,6 +203,35 @@ func GetWeeklyItems() map[string][]models.DailyItem {
 	return store.getWeeklyItems()
 }
 
+// QueryMenu returns the stored menu items matching filter, sorted by date,
+// location, time of day, station and name. It reports false when the store has
+// no menu loaded.
+func QueryMenu(filter models.MenuFilter) ([]models.DailyItem, bool) {
+	if store == nil {
+		return nil, false
+	}
+	return store.queryMenu(filter)
+}
+
+// SuggestItems returns up to limit catalog names matching query, best first. It
+// tolerates small typos and reports false when the store has no catalog loaded.
+func SuggestItems(query string, limit int) ([]models.ItemSuggestion, bool) {
+	if store == nil {
+		return nil, false
+	}
+	return store.suggestItems(query, limit)
+}
+
+// Version returns the store's current version and when it last changed. The
+// version increases on every Set and Clear, so an unchanged version means the
+// stored data is unchanged.
+func Version() (uint64, time.Time) {
+	if store == nil {
+		return 0, time.Time{}
+	}
+	return store.getVersion()
+}
+
 func Set(value any) {
 	if store != nil {
 		store.Set(value)
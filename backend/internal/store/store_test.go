@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMemoryStoreCopiesWeeklyItems(t *testing.T) {
@@ -24,6 +25,103 @@ func TestMemoryStoreCopiesWeeklyItems(t *testing.T) {
 	assert.Equal(t, "Pasta", secondRead["2026-07-10"][0].Name)
 }
 
+func TestMemoryStoreEstimatesMissingNutrition(t *testing.T) {
+	memoryStore := NewStore()
+	input := map[string][]models.DailyItem{
+		"2026-07-10": {{Name: "Pasta", Calories: "400", Protein: "12", Carbs: "70", Fat: "8"}},
+		"2026-07-11": {{Name: "Pasta"}},
+	}
+
+	memoryStore.Set(input)
+
+	assert.Nil(t, input["2026-07-11"][0].Estimates, "the caller's items are left alone")
+	items, ok := memoryStore.queryMenu(models.MenuFilter{From: "2026-07-11", To: "2026-07-11"})
+	require.True(t, ok)
+	require.Len(t, items, 1)
+	assert.Equal(t, 400.0, items[0].Estimates["calories"].Value)
+	assert.Equal(t, "history", items[0].Estimates["calories"].Source)
+}
+
+func TestMemoryStoreParsesIngredientTrees(t *testing.T) {
+	memoryStore := NewStore()
+	input := map[string][]models.DailyItem{
+		"2026-07-10": {{Name: "Tacos", Ingredients: "Tortilla, Salsa^ (Tomatoes, Cilantro)"}, {Name: "Water"}},
+	}
+
+	memoryStore.Set(input)
+
+	assert.Nil(t, input["2026-07-10"][0].IngredientTree, "the caller's items are left alone")
+	items, ok := memoryStore.queryMenu(models.MenuFilter{From: "2026-07-10", To: "2026-07-10"})
+	require.True(t, ok)
+	require.Len(t, items, 2)
+	assert.Equal(t, models.ParseIngredients("Tortilla, Salsa^ (Tomatoes, Cilantro)"), items[0].IngredientTree)
+	assert.Nil(t, items[1].IngredientTree)
+}
+
+func TestMemoryStoreQueryMenuByIngredient(t *testing.T) {
+	memoryStore := NewStore()
+	memoryStore.Set(map[string][]models.DailyItem{
+		"2026-07-10": {
+			{Name: "Tacos", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Lunch", Ingredients: "Tortilla, Salsa^ (Tomatoes, Cilantro)"},
+			{Name: "Cilantro Rice", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Lunch", Ingredients: "Rice, Lime Juice"},
+			{Name: "Stir Fry", Date: "2026-07-10", Location: "Sargent", TimeOfDay: "Dinner", Ingredients: "Broccoli, Green Onion, Button Mushrooms"},
+		},
+		"2026-07-11": {
+			{Name: "Tacos", Date: "2026-07-11", Location: "Allison", TimeOfDay: "Lunch", Ingredients: "Tortilla, Salsa^ (Tomatoes, Cilantro)"},
+		},
+	})
+
+	items, ok := memoryStore.queryMenu(models.MenuFilter{Ingredients: []string{"cilantro"}})
+	require.True(t, ok)
+	require.Len(t, items, 2, "sub-ingredients are indexed; names are not")
+	assert.Equal(t, "2026-07-10", items[0].Date)
+	assert.Equal(t, "2026-07-11", items[1].Date)
+
+	items, _ = memoryStore.queryMenu(models.MenuFilter{From: "2026-07-11", Ingredients: []string{"tomato"}})
+	require.Len(t, items, 1, "plurals match and the rest of the filter applies")
+	assert.Equal(t, "2026-07-11", items[0].Date)
+
+	items, _ = memoryStore.queryMenu(models.MenuFilter{Ingredients: []string{"green onions", "mushroom"}})
+	require.Len(t, items, 1)
+	assert.Equal(t, "Stir Fry", items[0].Name)
+
+	items, _ = memoryStore.queryMenu(models.MenuFilter{Ingredients: []string{"onion green"}})
+	assert.Empty(t, items, "words must appear in order")
+	items, _ = memoryStore.queryMenu(models.MenuFilter{Ingredients: []string{"cilantro", "broccoli"}})
+	assert.Empty(t, items)
+}
+
+func TestMemoryStoreParsesServingsOfOlderRows(t *testing.T) {
+	memoryStore := NewStore()
+	input := map[string][]models.DailyItem{
+		"2026-07-10": {{Name: "Chili", PortionSize: "8 oz"}, {Name: "Special", PortionSize: "2-3 pieces"}},
+	}
+
+	memoryStore.Set(input)
+
+	assert.False(t, input["2026-07-10"][0].Serving.Parsed, "the caller's items are left alone")
+	items, ok := memoryStore.queryMenu(models.MenuFilter{From: "2026-07-10", To: "2026-07-10"})
+	require.True(t, ok)
+	require.Len(t, items, 2)
+	assert.True(t, items[0].Serving.Parsed)
+	assert.Equal(t, 8.0, items[0].Serving.Quantity)
+	assert.False(t, items[1].Serving.Parsed)
+}
+
+func TestDeriveFillsItemsReadFromTheDatabase(t *testing.T) {
+	items := []models.DailyItem{
+		{Name: "Pasta", Date: "2026-07-10", Calories: "400", Protein: "12", Carbs: "70", Fat: "8", PortionSize: "8 oz"},
+		{Name: "Pasta", Date: "2026-07-11", PortionSize: "8 oz", Filters: []string{"Vegan"}},
+	}
+
+	Derive(items)
+
+	assert.Equal(t, 400.0, items[1].Estimates["calories"].Value)
+	assert.Equal(t, "history", items[1].Estimates["calories"].Source)
+	assert.True(t, items[1].Serving.Parsed)
+	assert.NotEmpty(t, items[1].Tags)
+}
+
 func TestMemoryStoreCopiesSlices(t *testing.T) {
 	memoryStore := NewStore()
 	allItems := []models.AllDataItem{{Name: "Pasta"}}
@@ -37,3 +135,136 @@ func TestMemoryStoreCopiesSlices(t *testing.T) {
 	firstRead[0].Name = "Mutated output"
 	assert.Equal(t, "Pasta", memoryStore.getAllDataItems()[0].Name)
 }
+
+func TestMemoryStoreQueryMenu(t *testing.T) {
+	memoryStore := NewStore()
+	_, ok := memoryStore.queryMenu(models.MenuFilter{})
+	assert.False(t, ok, "an empty store must report that no menu is loaded")
+
+	memoryStore.Set(map[string][]models.DailyItem{
+		"2026-07-10": {
+			{Name: "Waffles", Date: "2026-07-10", Location: "Sargent", TimeOfDay: "Breakfast", StationName: "Grill"},
+			{Name: "Pasta", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Lunch", StationName: "Comfort"},
+			{Name: "Burger", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Lunch", StationName: "Grill"},
+			{Name: "Apple", Date: "2026-07-10", Location: "Allison", TimeOfDay: "Lunch", StationName: "Comfort"},
+		},
+		"2026-07-11": {
+			{Name: "Soup", Date: "2026-07-11", Location: "Allison", TimeOfDay: "Dinner", StationName: "Comfort"},
+		},
+		"2026-07-12": {
+			{Name: "Tacos", Date: "2026-07-12", Location: "Elder", TimeOfDay: "Lunch", StationName: "Grill"},
+		},
+	})
+
+	names := func(items []models.DailyItem) []string {
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.Name)
+		}
+		return result
+	}
+
+	items, ok := memoryStore.queryMenu(models.MenuFilter{From: "2026-07-10", To: "2026-07-10"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Apple", "Pasta", "Burger", "Waffles"}, names(items))
+
+	items, _ = memoryStore.queryMenu(models.MenuFilter{From: "2026-07-10", To: "2026-07-10", Location: "allison", Meal: "LUNCH"})
+	assert.Equal(t, []string{"Apple", "Pasta", "Burger"}, names(items))
+
+	items, _ = memoryStore.queryMenu(models.MenuFilter{Station: "grill"})
+	assert.Equal(t, []string{"Burger", "Waffles", "Tacos"}, names(items))
+
+	items, _ = memoryStore.queryMenu(models.MenuFilter{From: "2026-07-11"})
+	assert.Equal(t, []string{"Soup", "Tacos"}, names(items))
+
+	items, ok = memoryStore.queryMenu(models.MenuFilter{From: "2026-08-01", To: "2026-08-02"})
+	assert.True(t, ok)
+	assert.Empty(t, items)
+}
+
+func TestMemoryStoreSuggestItems(t *testing.T) {
+	memoryStore := NewStore()
+	_, ok := memoryStore.suggestItems("chicken", 5)
+	assert.False(t, ok, "an empty store must report that no catalog is loaded")
+
+	memoryStore.Set([]models.AllDataItem{
+		{Name: "Chicken Tikka Masala"},
+		{Name: "Grilled Chicken Breast"},
+		{Name: "Chicken Noodle Soup"},
+		{Name: "Chickpea Salad"},
+		{Name: "Mac & Cheese"},
+		{Name: "Beef Tacos"},
+	})
+
+	names := func(suggestions []models.ItemSuggestion) []string {
+		result := make([]string, 0, len(suggestions))
+		for _, suggestion := range suggestions {
+			result = append(result, suggestion.Name)
+		}
+		return result
+	}
+
+	suggestions, ok := memoryStore.suggestItems("chicken", 10)
+	assert.True(t, ok)
+	// Literal matches come first; the near miss "Chickpea" trails as a fuzzy hit.
+	assert.Equal(t, []string{"Chicken Noodle Soup", "Chicken Tikka Masala", "Grilled Chicken Breast", "Chickpea Salad"}, names(suggestions))
+
+	suggestions, _ = memoryStore.suggestItems("chi", 2)
+	assert.Len(t, suggestions, 2, "results are capped at the limit")
+
+	suggestions, _ = memoryStore.suggestItems("chiken tika", 3)
+	require.NotEmpty(t, suggestions)
+	assert.Equal(t, "Chicken Tikka Masala", suggestions[0].Name, "small typos still find the item")
+
+	suggestions, _ = memoryStore.suggestItems("mac cheese", 3)
+	require.NotEmpty(t, suggestions)
+	assert.Equal(t, "Mac & Cheese", suggestions[0].Name)
+
+	suggestions, _ = memoryStore.suggestItems("zzzz", 3)
+	assert.Empty(t, suggestions)
+}
+
+func TestMemoryStoreItemDetailCache(t *testing.T) {
+	memoryStore := NewStore()
+	detail := models.ItemDetail{
+		Name:        "Chicken Tikka Masala",
+		Since:       "2026-06-10",
+		Appearances: []models.ItemAppearance{{Date: "2026-07-10", Location: "Allison"}},
+	}
+	memoryStore.cacheItemDetail(detail)
+
+	cached, ok := memoryStore.getItemDetail("chicken tikka masala", "2026-06-10")
+	require.True(t, ok)
+	assert.Equal(t, "Allison", cached.Appearances[0].Location)
+
+	cached.Appearances[0].Location = "Mutated output"
+	cached, _ = memoryStore.getItemDetail("Chicken Tikka Masala", "2026-06-10")
+	assert.Equal(t, "Allison", cached.Appearances[0].Location)
+
+	_, ok = memoryStore.getItemDetail("Chicken Tikka Masala", "2026-06-11")
+	assert.False(t, ok, "a detail built for another retention window must not be served")
+
+	memoryStore.Set(map[string][]models.DailyItem{"2026-07-11": {{Name: "Pasta"}}})
+	_, ok = memoryStore.getItemDetail("Chicken Tikka Masala", "2026-06-10")
+	assert.False(t, ok, "replacing the menu must drop cached details")
+}
+
+func TestMemoryStoreVersionBumpsOnEveryChange(t *testing.T) {
+	memoryStore := NewStore()
+	version, updatedAt := memoryStore.getVersion()
+	assert.Zero(t, version)
+	assert.True(t, updatedAt.IsZero())
+
+	memoryStore.Set([]models.AllDataItem{{Name: "Pasta"}})
+	afterSet, updatedAt := memoryStore.getVersion()
+	assert.Greater(t, afterSet, version)
+	assert.False(t, updatedAt.IsZero())
+
+	memoryStore.cacheItemDetail(models.ItemDetail{Name: "Pasta"})
+	afterCache, _ := memoryStore.getVersion()
+	assert.Equal(t, afterSet, afterCache, "caching derived details must not invalidate payload ETags")
+
+	memoryStore.Clear()
+	afterClear, _ := memoryStore.getVersion()
+	assert.Greater(t, afterClear, afterSet)
+}
@@ -0,0 +1,159 @@
+package store
+
+import (
+	"backend/internal/models"
+	"sort"
+	"strings"
+)
+
+// Suggestion tiers. A prefix of the whole name beats a prefix of a later word,
+// which beats a plain substring, which beats a fuzzy (typo-tolerant) match.
+const (
+	scoreNamePrefix = 1.0
+	scoreWordPrefix = 0.85
+	scoreSubstring  = 0.7
+	// fuzzy matches score up to scoreFuzzyMax, scaled by trigram overlap.
+	scoreFuzzyMax = 0.6
+	// minFuzzyCoverage is the share of the query's trigrams a name must contain
+	// to count as a fuzzy match at all. It admits a typo or two in a word
+	// ("chiken tika") without letting unrelated names through.
+	minFuzzyCoverage = 0.5
+)
+
+// catalogIndex is a prefix and trigram index over the food catalog names, so
+// typeahead can be answered server-side instead of shipping the whole catalog.
+// It is rebuilt whenever the catalog is replaced and never mutated after.
+type catalogIndex struct {
+	names    []string         // display names, in catalog order
+	folded   []string         // normalized names, parallel to names
+	trigrams map[string][]int // trigram -> indices of names containing it
+}
+
+func newCatalogIndex(items []models.AllDataItem) catalogIndex {
+	index := catalogIndex{trigrams: make(map[string][]int)}
+	seen := make(map[string]struct{}, len(items))
+
+	for _, item := range items {
+		folded := foldName(item.Name)
+		if folded == "" {
+			continue
+		}
+		if _, exists := seen[folded]; exists {
+			continue
+		}
+		seen[folded] = struct{}{}
+
+		position := len(index.names)
+		index.names = append(index.names, strings.TrimSpace(item.Name))
+		index.folded = append(index.folded, folded)
+		for trigram := range trigramSet(folded) {
+			index.trigrams[trigram] = append(index.trigrams[trigram], position)
+		}
+	}
+
+	return index
+}
+
+// suggest returns up to limit catalog names matching query, best first.
+func (index catalogIndex) suggest(query string, limit int) []models.ItemSuggestion {
+	folded := foldName(query)
+	if folded == "" || limit <= 0 {
+		return []models.ItemSuggestion{}
+	}
+
+	scores := make(map[int]float64)
+	for position, name := range index.folded {
+		if score := literalScore(name, folded); score > 0 {
+			scores[position] = score
+		}
+	}
+
+	// Trigrams only carry signal once the query is long enough to contain a few.
+	queryTrigrams := trigramSet(folded)
+	if len([]rune(folded)) >= 3 {
+		shared := make(map[int]int)
+		for trigram := range queryTrigrams {
+			for _, position := range index.trigrams[trigram] {
+				shared[position]++
+			}
+		}
+		for position, count := range shared {
+			if _, literal := scores[position]; literal {
+				continue
+			}
+			coverage := float64(count) / float64(len(queryTrigrams))
+			if coverage < minFuzzyCoverage {
+				continue
+			}
+			scores[position] = scoreFuzzyMax * coverage
+		}
+	}
+
+	suggestions := make([]models.ItemSuggestion, 0, len(scores))
+	for position, score := range scores {
+		// Prefer tighter names among equal tiers: "Ramen" before "Ramen Noodle Bowl".
+		lengthPenalty := float64(len(index.folded[position])) / 1000
+		suggestions = append(suggestions, models.ItemSuggestion{
+			Name:  index.names[position],
+			Score: max(score-lengthPenalty, 0.001),
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// literalScore scores exact-text matches of the query within a name, or 0.
+func literalScore(name, query string) float64 {
+	switch {
+	case strings.HasPrefix(name, query):
+		return scoreNamePrefix
+	case strings.Contains(name, " "+query):
+		return scoreWordPrefix
+	case strings.Contains(name, query):
+		return scoreSubstring
+	}
+	return 0
+}
+
+// foldName lowercases a name, drops punctuation and collapses whitespace so
+// "Mac & Cheese" and "mac  cheese" index the same way.
+func foldName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r > 127:
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// trigramSet returns the distinct three-rune windows of a folded name, padded
+// so the first letters of each word form trigrams of their own.
+func trigramSet(folded string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.Fields(folded) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
@@ -0,0 +1,62 @@
+// Package subscription matches users' subscription rules against the menu for
+// the favorites email and the meal notifications. A send pass loads the menu
+// it announces once and hands it to the db favorites lookups, which match
+// every recipient's rules against that copy instead of re-reading the menu per
+// user.
+package subscription
+
+import (
+	"backend/internal/db"
+	"backend/internal/models"
+	"backend/internal/search"
+	"backend/internal/store"
+	"log"
+)
+
+// Menu is the slice of the menu a send pass announces, such as today's menu
+// or one meal of it.
+type Menu struct {
+	items []models.DailyItem
+}
+
+// LoadMenu reads the menu items matching filter from the menu store, falling
+// back to the database, with the store's derived fields filled in, when the
+// store has no menu loaded.
+func LoadMenu(filter models.MenuFilter) (Menu, error) {
+	items, ok := store.QueryMenu(filter)
+	if !ok {
+		var err error
+		items, err = db.QueryMenuItems(filter)
+		if err != nil {
+			return Menu{}, err
+		}
+		store.Derive(items)
+	}
+	return Menu{items: items}, nil
+}
+
+// Matches returns the menu items that match at least one of the user's
+// subscription rules. It implements db.RuleMatcher, whose callers apply the
+// dietary profile. A stored rule that no longer parses is logged and skipped
+// rather than failing the user's alerts.
+func (m Menu) Matches(userID string) ([]models.DailyItem, error) {
+	rules, err := db.GetSubscriptionRules(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]search.Query, 0, len(rules))
+	for _, rule := range rules {
+		q, err := search.ParseRule(rule.Query)
+		if err != nil {
+			log.Printf("skipping subscription rule %d for user %s: %v", rule.ID, userID, err)
+			continue
+		}
+		queries = append(queries, q)
+	}
+	if len(queries) == 0 {
+		return []models.DailyItem{}, nil
+	}
+
+	return search.MatchRules(m.items, queries), nil
+}
@@ -0,0 +1,77 @@
+package subscription_test
+
+import (
+	"backend/internal/db"
+	"backend/internal/db/dbtest"
+	"backend/internal/models"
+	"backend/internal/subscription"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func names(items []models.DailyItem) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.TimeOfDay+" "+item.Location+" "+item.Name)
+	}
+	return result
+}
+
+func TestMenuMatchesSubscriptionRules(t *testing.T) {
+	dbtest.Setup(t)
+	today := time.Now().Format("2006-01-02")
+
+	ramen := dbtest.MealItem(today, "Spicy Miso Ramen", "Allison", "Dinner")
+	tofu := dbtest.MealItem(today, "Tofu Scramble", "Sargent", "Dinner")
+	tofu.DailyItem.Filters = []string{"Vegan"}
+	allisonTofu := dbtest.MealItem(today, "Tofu Scramble", "Allison", "Dinner")
+	allisonTofu.DailyItem.Filters = []string{"Vegan"}
+	chicken := dbtest.MealItem(today, "Grilled Chicken", "Elder", "Dinner")
+	chicken.DailyItem.StationName = "Flame"
+	chicken.DailyItem.Protein = "42"
+	burger := dbtest.MealItem(today, "Veggie Burger", "Elder", "Dinner")
+	burger.DailyItem.StationName = "Flame"
+	burger.DailyItem.Protein = "18"
+	lunchRamen := dbtest.MealItem(today, "Chicken Ramen", "Allison", "Lunch")
+	require.NoError(t, db.PersistScrapedMenu(
+		[]models.WeeklyItem{ramen, tofu, allisonTofu, chicken, burger, lunchRamen},
+		nil,
+		[]string{today},
+		time.Now(),
+	))
+
+	for _, query := range []string{"ramen", "tag:vegan location:sargent", "station:flame protein>30"} {
+		_, err := db.CreateSubscriptionRule("test-user", models.SubscriptionRule{Name: query, Query: query})
+		require.NoError(t, err)
+	}
+
+	dinner, err := subscription.LoadMenu(models.MenuFilter{From: today, To: today, Meal: "Dinner"})
+	require.NoError(t, err)
+	matches, err := db.GetAvailableFavoritesForMeal("test-user", today, "Dinner", dinner)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"Dinner Allison Spicy Miso Ramen",
+		"Dinner Sargent Tofu Scramble",
+		"Dinner Elder Grilled Chicken",
+	}, names(matches))
+
+	// Users without rules match nothing.
+	matches, err = dinner.Matches("someone-else")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// Today's menu covers every meal.
+	day, err := subscription.LoadMenu(models.MenuFilter{From: today, To: today})
+	require.NoError(t, err)
+	matches, err = db.GetAvailableFavoritesBatch("test-user", day)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"Dinner Allison Spicy Miso Ramen",
+		"Dinner Sargent Tofu Scramble",
+		"Dinner Elder Grilled Chicken",
+		"Lunch Allison Chicken Ramen",
+	}, names(matches))
+}
@@ -0,0 +1,128 @@
+// Package taxonomy resolves upstream filter names ("Milk", "Sesame*",
+// "Vegan", "Good Source of Protein") to a category, a canonical key and a
+// "may contain" flag, so clients no longer categorize tags on their own.
+//
+// The mappings live in the database where an admin can edit them; this
+// package holds the copy in use. Names no mapping covers are still tagged, as
+// uncategorized, and logged once so new upstream labels get noticed.
+package taxonomy
+
+import (
+	"backend/internal/models"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	mu       sync.RWMutex
+	mappings = index(models.DefaultFilterMappings())
+	// unmapped holds the raw names seen without a mapping, keyed by base name.
+	unmapped = make(map[string]string)
+)
+
+func index(list []models.FilterMapping) map[string]models.FilterMapping {
+	byName := make(map[string]models.FilterMapping, len(list))
+	for _, mapping := range list {
+		if mapping.Key == "" {
+			mapping.Key = models.FilterKey(mapping.Name)
+		}
+		byName[strings.ToLower(strings.TrimSpace(mapping.Name))] = mapping
+	}
+	return byName
+}
+
+// Set replaces the mappings in use, usually with the database table. Names
+// the new mappings cover are dropped from the unmapped list.
+func Set(list []models.FilterMapping) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	mappings = index(list)
+	for base := range unmapped {
+		if _, ok := mappings[base]; ok {
+			delete(unmapped, base)
+		}
+	}
+}
+
+// Mappings returns the mappings in use, ordered by category and name.
+func Mappings() []models.FilterMapping {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := make([]models.FilterMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		list = append(list, mapping)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Category != list[j].Category {
+			return list[i].Category < list[j].Category
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Unmapped returns the base names seen on the menu that no mapping covers,
+// sorted.
+func Unmapped() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(unmapped))
+	for _, name := range unmapped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tag resolves one raw filter name. A trailing "*" marks the "may contain"
+// variant and is matched against the base name's mapping.
+func Tag(name string) models.FilterTag {
+	name = strings.TrimSpace(name)
+	base, mayContain := strings.CutSuffix(name, "*")
+	base = strings.TrimSpace(base)
+	lookup := strings.ToLower(base)
+
+	mu.RLock()
+	mapping, ok := mappings[lookup]
+	_, reported := unmapped[lookup]
+	mu.RUnlock()
+
+	if !ok {
+		if !reported {
+			mu.Lock()
+			if _, reported = unmapped[lookup]; !reported {
+				unmapped[lookup] = base
+				log.Printf("taxonomy: unmapped filter tag %q; add a mapping so clients can categorize it", base)
+			}
+			mu.Unlock()
+		}
+		return models.FilterTag{Name: name, Key: models.FilterKey(base), Category: models.FilterUncategorized, MayContain: mayContain}
+	}
+	return models.FilterTag{Name: name, Key: mapping.Key, Category: mapping.Category, MayContain: mayContain}
+}
+
+// Tags resolves an item's raw filter names in order.
+func Tags(filters []string) []models.FilterTag {
+	var tags []models.FilterTag
+	for _, name := range filters {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		tags = append(tags, Tag(name))
+	}
+	return tags
+}
+
+// TagWeekly sets Tags on every item of a weekly menu in place.
+func TagWeekly(weekly map[string][]models.DailyItem) {
+	for _, items := range weekly {
+		for i := range items {
+			items[i].Tags = Tags(items[i].Filters)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package taxonomy
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagResolvesMappedAndMayContainNames(t *testing.T) {
+	Set(models.DefaultFilterMappings())
+	t.Cleanup(func() { Set(models.DefaultFilterMappings()) })
+
+	assert.Equal(t, models.FilterTag{Name: "Milk", Key: "milk", Category: models.FilterAllergen}, Tag("Milk"))
+	assert.Equal(t, models.FilterTag{Name: "Tree Nuts*", Key: "tree_nuts", Category: models.FilterAllergen, MayContain: true}, Tag(" Tree Nuts* "))
+	assert.Equal(t, models.FilterTag{Name: "vegan", Key: "vegan", Category: models.FilterDiet}, Tag("vegan"), "names match case-insensitively")
+	assert.Equal(t, models.FilterTag{Name: "Good Source of Protein", Key: "good_source_of_protein", Category: models.FilterMarketing}, Tag("Good Source of Protein"))
+
+	tags := Tags([]string{"Sesame*", "", "Vegan"})
+	assert.Equal(t, []string{"sesame", "vegan"}, []string{tags[0].Key, tags[1].Key})
+	assert.Nil(t, Tags(nil))
+}
+
+func TestUnmappedTagsAreReportedUntilMapped(t *testing.T) {
+	Set(models.DefaultFilterMappings())
+	t.Cleanup(func() { Set(models.DefaultFilterMappings()) })
+
+	tag := Tag("Mustard*")
+	assert.Equal(t, models.FilterTag{Name: "Mustard*", Key: "mustard", Category: models.FilterUncategorized, MayContain: true}, tag)
+	Tag("mustard")
+	assert.Contains(t, Unmapped(), "Mustard")
+
+	Set(append(models.DefaultFilterMappings(), models.FilterMapping{Name: "Mustard", Category: models.FilterAllergen}))
+	assert.NotContains(t, Unmapped(), "Mustard")
+	assert.Equal(t, models.FilterTag{Name: "Mustard", Key: "mustard", Category: models.FilterAllergen}, Tag("Mustard"), "an empty key defaults to the derived one")
+}
+
+func TestTagWeeklyTagsEveryItem(t *testing.T) {
+	weekly := map[string][]models.DailyItem{
+		"2026-10-16": {{Name: "Pizza", Filters: []string{"Milk", "Wheat"}}, {Name: "Water"}},
+	}
+	TagWeekly(weekly)
+	assert.Len(t, weekly["2026-10-16"][0].Tags, 2)
+	assert.Empty(t, weekly["2026-10-16"][1].Tags)
+}
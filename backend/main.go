@@ -9,6 +9,7 @@ import (
 	"backend/internal/push"
 	"backend/internal/scheduler"
 	"backend/internal/store"
+	"backend/internal/taxonomy"
 	"context"
 	"fmt"
 	"log"
@@ -50,6 +51,14 @@ func main() {
 
 	fmt.Println("Database initialized successfully")
 
+	// Tag menu items with the admin-edited filter taxonomy; until it loads the
+	// built-in defaults are used.
+	if mappings, err := db.GetFilterMappings(); err != nil {
+		log.Printf("Error loading filter taxonomy, using defaults: %v", err)
+	} else {
+		taxonomy.Set(mappings)
+	}
+
 	store.InitStore()
 
 	fmt.Println("MemoryStore initialized")
@@ -85,6 +94,12 @@ func main() {
 	// reads the same two settings, so its refresh plan stays clear of these sends.
 	scheduler.StartDailyNotify()
 
+	// Weekly food log report email for users who opted in. Sends Sundays at 6pm
+	// Central by default; override with WEEKLY_REPORT_HOURS_CST or disable with
+	// ENABLE_WEEKLY_REPORT_CRON=false. Like the daily email it needs BASE_URL,
+	// SECRET_KEY and a mail provider, and is skipped until one is installed.
+	scheduler.StartWeeklyReportMailing()
+
 	// Create a new router
 	r := mux.NewRouter()
 
@@ -98,11 +113,22 @@ func main() {
 	apiRouter.HandleFunc("/allData", middleware.AuthMiddleware(api.GetAllDataHandler)).Methods("GET", "OPTIONS")
 	apiRouter.HandleFunc("/generalData", api.GetGeneralDataHandler).Methods("GET", "OPTIONS")
 	apiRouter.HandleFunc("/operatingTimes", api.GetLocationOperatingTimesHandler).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/locations/status", api.GetLocationStatusHandler).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/menu", middleware.OptionalAuthMiddleware(api.GetMenuHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/menu/changes", api.GetMenuChangesHandler).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/search", middleware.OptionalAuthMiddleware(api.SearchHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/items/suggest", api.SuggestItemsHandler).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/items/{name}", api.GetItemDetailHandler).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/rankings", api.GetRankingsHandler).Methods("GET", "OPTIONS")
+
+	// Live menu and hours updates (server-sent events)
+	apiRouter.HandleFunc("/events", api.EventsHandler).Methods("GET", "OPTIONS")
 
 	// User preferences endpoints
 	apiRouter.HandleFunc("/userPreferences", middleware.AuthMiddleware(api.SetUserPreferences)).Methods("POST", "OPTIONS")
 	apiRouter.HandleFunc("/mailing", middleware.AuthMiddleware(api.SetUserMailing)).Methods("POST", "OPTIONS")
 	apiRouter.HandleFunc("/displayPreferences", middleware.AuthMiddleware(api.SetDisplayPreferences)).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/dietaryProfile", middleware.AuthMiddleware(api.SetDietaryProfileHandler)).Methods("POST", "OPTIONS")
 
 	// Account deletion endpoint (required for App Store review)
 	apiRouter.HandleFunc("/user", middleware.AuthMiddleware(api.DeleteUserHandler)).Methods("DELETE", "OPTIONS")
@@ -123,12 +149,49 @@ func main() {
 
 	// Mailing endpoints
 	apiRouter.HandleFunc("/sendMailing", middleware.AdminMiddleware(api.SendOutMailing)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/sendWeeklyReports", middleware.AdminMiddleware(api.SendOutWeeklyReports)).Methods("GET", "OPTIONS")
 	apiRouter.HandleFunc("/unsubscribe", api.HandleUnsubscribe).Methods("GET", "OPTIONS")
 
 	// Nutrition Goals endpoints - combine both methods on the same route pattern
 	nutritionGoalsRoute := apiRouter.PathPrefix("/nutritionGoals").Subrouter()
 	nutritionGoalsRoute.HandleFunc("", middleware.AuthMiddleware(api.SaveNutritionGoalsHandler)).Methods("POST", "OPTIONS")
 	nutritionGoalsRoute.HandleFunc("", middleware.AuthMiddleware(api.GetNutritionGoalsHandler)).Methods("GET", "OPTIONS")
+	nutritionGoalsRoute.HandleFunc("/calculate", middleware.AuthMiddleware(api.CalculateNutritionGoalsHandler)).Methods("POST", "OPTIONS")
+	nutritionGoalsRoute.HandleFunc("/bodyStats", middleware.AuthMiddleware(api.GetBodyStatsHandler)).Methods("GET", "OPTIONS")
+	nutritionGoalsRoute.HandleFunc("/bodyStats", middleware.AuthMiddleware(api.DeleteBodyStatsHandler)).Methods("DELETE", "OPTIONS")
+
+	// Food log endpoints
+	apiRouter.HandleFunc("/foodLog", middleware.AuthMiddleware(api.GetFoodLogHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/foodLog", middleware.AuthMiddleware(api.AddFoodLogEntryHandler)).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/foodLog/summary", middleware.AuthMiddleware(api.GetFoodLogSummaryHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/foodLog/weekly", middleware.AuthMiddleware(api.GetWeeklyReportHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/weeklyReport", middleware.AuthMiddleware(api.SetWeeklyReportMailing)).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/foodLog/{id}", middleware.AuthMiddleware(api.DeleteFoodLogEntryHandler)).Methods("DELETE", "OPTIONS")
+
+	// Meal planner endpoint
+	apiRouter.HandleFunc("/planner/suggest", middleware.AuthMiddleware(api.SuggestPlatesHandler)).Methods("POST", "OPTIONS")
+
+	// Saved plate endpoints
+	apiRouter.HandleFunc("/plates", middleware.AuthMiddleware(api.GetSavedPlatesHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/plates", middleware.AuthMiddleware(api.CreateSavedPlateHandler)).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/plates/availability", middleware.AuthMiddleware(api.GetPlateAvailabilityHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/plates/{id}", middleware.AuthMiddleware(api.UpdateSavedPlateHandler)).Methods("PUT", "OPTIONS")
+	apiRouter.HandleFunc("/plates/{id}", middleware.AuthMiddleware(api.DeleteSavedPlateHandler)).Methods("DELETE", "OPTIONS")
+
+	// Subscription rule endpoints
+	apiRouter.HandleFunc("/subscriptions", middleware.AuthMiddleware(api.GetSubscriptionRulesHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/subscriptions", middleware.AuthMiddleware(api.CreateSubscriptionRuleHandler)).Methods("POST", "OPTIONS")
+	apiRouter.HandleFunc("/subscriptions/{id}", middleware.AuthMiddleware(api.UpdateSubscriptionRuleHandler)).Methods("PUT", "OPTIONS")
+	apiRouter.HandleFunc("/subscriptions/{id}", middleware.AuthMiddleware(api.DeleteSubscriptionRuleHandler)).Methods("DELETE", "OPTIONS")
+
+	// Filter taxonomy endpoints
+	apiRouter.HandleFunc("/filters/taxonomy", api.GetFilterTaxonomyHandler).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/filters/taxonomy", middleware.AdminMiddleware(api.SaveFilterMappingHandler)).Methods("PUT", "OPTIONS")
+	apiRouter.HandleFunc("/filters/taxonomy/{name}", middleware.AdminMiddleware(api.DeleteFilterMappingHandler)).Methods("DELETE", "OPTIONS")
+
+	// Item catalog endpoints
+	apiRouter.HandleFunc("/catalog", middleware.AdminMiddleware(api.GetCatalogHandler)).Methods("GET", "OPTIONS")
+	apiRouter.HandleFunc("/catalog/merge", middleware.AdminMiddleware(api.MergeCatalogHandler)).Methods("POST", "OPTIONS")
 
 	// Cache statistics endpoint (for debugging/monitoring)
 	apiRouter.HandleFunc("/cache/stats", middleware.AdminMiddleware(api.GetCacheStatsHandler)).Methods("GET", "OPTIONS")
@@ -136,8 +199,12 @@ func main() {
 	// Admin operations
 	apiRouter.HandleFunc("/stores/clear", middleware.AdminMiddleware(api.ClearStoresHandler)).Methods("POST", "OPTIONS")
 
-	// Apply CORS middleware to all routes
-	corsRouter := middleware.CorsMiddleware(r)
+	// Typed v2 API, documented at /api/v2/openapi.json
+	api.RegisterV2(apiRouter.PathPrefix("/v2").Subrouter())
+
+	// Serve the store-backed public payloads from pre-encoded, compressed
+	// bodies, then apply CORS middleware to all routes
+	corsRouter := middleware.CorsMiddleware(middleware.ResponseCacheMiddleware(r))
 
 	// Set up server with timeouts
 	server := &http.Server{